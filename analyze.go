@@ -0,0 +1,173 @@
+package gokvstores
+
+import (
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// KeyInfo describes a key found by AnalyzeKeyspace.
+type KeyInfo struct {
+	Key string
+
+	// Type is the Redis type of the key: "string" for values, "hash" for
+	// maps and "set" for slices. Other MemoryStore values are "other".
+	Type string
+
+	// Size is the approximate number of bytes used by the key and its value.
+	Size int64
+
+	// Expiration is the time the key expires, zero if it does not.
+	Expiration time.Time
+}
+
+// KeyspaceReport is the result of AnalyzeKeyspace.
+type KeyspaceReport struct {
+	// Keys is the number of keys found.
+	Keys int64
+
+	// Types is the number of keys of each type.
+	Types map[string]int64
+
+	// Expiring is the number of keys with an expiration.
+	Expiring int64
+
+	// Size is the approximate number of bytes used by all the keys.
+	Size int64
+
+	// Largest are the largest keys, largest first.
+	Largest []KeyInfo
+}
+
+// TTLCoverage returns the fraction of keys with an expiration, zero without
+// keys.
+func (r *KeyspaceReport) TTLCoverage() float64 {
+	if r.Keys == 0 {
+		return 0
+	}
+	return float64(r.Expiring) / float64(r.Keys)
+}
+
+// add records the key, keeping the top largest keys.
+func (r *KeyspaceReport) add(info KeyInfo, top int) {
+	r.Keys++
+	r.Types[info.Type]++
+	r.Size += info.Size
+	if !info.Expiration.IsZero() {
+		r.Expiring++
+	}
+
+	i := sort.Search(len(r.Largest), func(i int) bool { return r.Largest[i].Size < info.Size })
+	if i >= top {
+		return
+	}
+
+	if len(r.Largest) < top {
+		r.Largest = append(r.Largest, KeyInfo{})
+	}
+	copy(r.Largest[i+1:], r.Largest[i:])
+	r.Largest[i] = info
+}
+
+// AnalyzeOptions are AnalyzeKeyspace options.
+type AnalyzeOptions struct {
+	// Top is the number of largest keys reported. Defaults to 10.
+	Top int
+}
+
+// keyProber is implemented by stores able to describe their keys without
+// reading their values.
+type keyProber interface {
+	probeKeys(fn func(info KeyInfo) error) error
+}
+
+// AnalyzeKeyspace scans the store, which must implement Scanner, and reports
+// its largest keys, the number of keys of each type and how many expire.
+// Redis keys are measured with MEMORY USAGE, other values are estimated from
+// their string representations. It is meant for troubleshooting: on large
+// stores, it takes long and loads the backend.
+func AnalyzeKeyspace(store KVStore, options *AnalyzeOptions) (*KeyspaceReport, error) {
+	if options == nil {
+		options = &AnalyzeOptions{}
+	}
+
+	top := options.Top
+	if top <= 0 {
+		top = 10
+	}
+
+	report := &KeyspaceReport{Types: map[string]int64{}}
+	add := func(info KeyInfo) error {
+		report.add(info, top)
+		return nil
+	}
+
+	if prober, ok := store.(keyProber); ok {
+		return report, prober.probeKeys(add)
+	}
+
+	scanner, ok := store.(Scanner)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+
+	err := scanner.Scan(func(item Item) error {
+		return add(KeyInfo{
+			Key:        item.Key,
+			Type:       valueType(item.Value),
+			Size:       int64(len(item.Key) + valueSize(item.Value)),
+			Expiration: item.Expiration,
+		})
+	})
+
+	return report, err
+}
+
+// valueType returns the Redis type of the value.
+func valueType(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}, *fieldMap:
+		return "hash"
+	case []interface{}:
+		return "set"
+	case *hyperLogLog, *geoSet, *bloomFilter, *cuckooFilter:
+		return "other"
+	}
+	return "string"
+}
+
+// probeKeys calls fn with the type, size and expiration of each key, of every
+// master node with a cluster, read with TYPE, MEMORY USAGE and PTTL.
+func (r *RedisStore) probeKeys(fn func(info KeyInfo) error) error {
+	return r.scanKeys("", func(key string) error {
+		var (
+			kind  *redis.StatusCmd
+			usage *redis.IntCmd
+			ttl   *redis.DurationCmd
+		)
+
+		_, err := r.client.Pipelined(r.ctx, func(pipe redis.Pipeliner) error {
+			kind = pipe.Type(r.ctx, key)
+			usage = pipe.MemoryUsage(r.ctx, key)
+			ttl = pipe.PTTL(r.ctx, key)
+			return nil
+		})
+
+		// Deleted since scanned.
+		if err == redis.Nil || kind.Val() == "none" {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		info := KeyInfo{Key: key, Type: kind.Val(), Size: usage.Val()}
+		if ttl.Val() > 0 {
+			info.Expiration = time.Now().Add(ttl.Val())
+		}
+
+		return fn(info)
+	})
+}
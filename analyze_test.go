@@ -0,0 +1,40 @@
+package gokvstores
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeKeyspace(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewMemoryStore(0, time.Second*10)
+	is.Nil(err)
+
+	is.Nil(store.Set("small", "a"))
+	is.Nil(store.SetWithExpiration("big", strings.Repeat("a", 100), time.Minute))
+	is.Nil(store.SetMap("map", map[string]interface{}{"field": strings.Repeat("b", 50)}))
+	is.Nil(store.SetSlice("slice", []interface{}{"c", "d"}))
+
+	report, err := AnalyzeKeyspace(store, &AnalyzeOptions{Top: 2})
+	is.Nil(err)
+	is.Equal(int64(4), report.Keys)
+	is.Equal(map[string]int64{"string": 2, "hash": 1, "set": 1}, report.Types)
+	is.Equal(int64(1), report.Expiring)
+	is.Equal(0.25, report.TTLCoverage())
+	is.Equal(int64(5+1+3+100+3+5+50+5+2), report.Size)
+
+	if is.Len(report.Largest, 2) {
+		is.Equal("big", report.Largest[0].Key)
+		is.Equal(int64(103), report.Largest[0].Size)
+		is.False(report.Largest[0].Expiration.IsZero())
+		is.Equal("map", report.Largest[1].Key)
+		is.Equal("hash", report.Largest[1].Type)
+	}
+
+	_, err = AnalyzeKeyspace(DummyStore{}, nil)
+	is.Equal(ErrNotSupported, err)
+}
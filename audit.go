@@ -0,0 +1,102 @@
+package gokvstores
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// AuditRecord describes a mutating operation.
+type AuditRecord struct {
+	Time      time.Time `json:"time"`
+	Actor     string    `json:"actor,omitempty"`
+	Operation string    `json:"operation"`
+	Key       string    `json:"key,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// AuditSink receives audit records.
+type AuditSink interface {
+	Audit(record AuditRecord) error
+}
+
+// AuditSinkFunc is an adapter to use ordinary functions as AuditSink.
+type AuditSinkFunc func(record AuditRecord) error
+
+// Audit calls f(record).
+func (f AuditSinkFunc) Audit(record AuditRecord) error {
+	return f(record)
+}
+
+// jsonAuditSink writes audit records as JSON lines.
+type jsonAuditSink struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewJSONAuditSink returns an AuditSink writing one JSON record per line to w.
+func NewJSONAuditSink(w io.Writer) AuditSink {
+	return &jsonAuditSink{encoder: json.NewEncoder(w)}
+}
+
+func (s *jsonAuditSink) Audit(record AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.encoder.Encode(record)
+}
+
+// AuditStore is a KVStore decorator sending an audit record to a sink for
+// every mutating operation, once it returned.
+//
+// If the sink fails, its error is returned although the operation was applied.
+type AuditStore struct {
+	*interceptedStore
+
+	sink  AuditSink
+	actor string
+}
+
+// NewAuditStore returns an AuditStore wrapping the given store.
+func NewAuditStore(store KVStore, sink AuditSink) *AuditStore {
+	a := &AuditStore{sink: sink}
+	a.interceptedStore = &interceptedStore{store: store, intercept: a.audit}
+
+	return a
+}
+
+// WithActor returns a copy of the store recording the given actor in audit records.
+func (a *AuditStore) WithActor(actor string) *AuditStore {
+	c := *a
+	c.actor = actor
+	c.interceptedStore = &interceptedStore{store: a.store, intercept: c.audit}
+
+	return &c
+}
+
+// audit records a mutating operation.
+func (a *AuditStore) audit(op *operation, next func() error) error {
+	if op.read() || op.name == "Close" || op.name == "Ping" {
+		return next()
+	}
+
+	err := next()
+
+	record := AuditRecord{
+		Time:      time.Now(),
+		Actor:     a.actor,
+		Operation: op.name,
+		Key:       op.key,
+	}
+
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	if auditErr := a.sink.Audit(record); auditErr != nil && err == nil {
+		return auditErr
+	}
+
+	return err
+}
@@ -0,0 +1,54 @@
+package gokvstores
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	records := []AuditRecord{}
+
+	store := NewAuditStore(memory, AuditSinkFunc(func(record AuditRecord) error {
+		records = append(records, record)
+		return nil
+	}))
+
+	testStore(t, store)
+
+	records = records[:0]
+
+	is.Nil(store.WithActor("alice").Set("key", "value"))
+	_, err = store.Get("key")
+	is.Nil(err)
+	is.Nil(store.Delete("key"))
+
+	is.Len(records, 2)
+	is.Equal("alice", records[0].Actor)
+	is.Equal("Set", records[0].Operation)
+	is.Equal("key", records[0].Key)
+	is.Equal("", records[1].Actor)
+	is.Equal("Delete", records[1].Operation)
+}
+
+func TestJSONAuditSink(t *testing.T) {
+	is := assert.New(t)
+
+	var buf bytes.Buffer
+
+	sink := NewJSONAuditSink(&buf)
+	is.Nil(sink.Audit(AuditRecord{Actor: "alice", Operation: "Set", Key: "key"}))
+
+	record := AuditRecord{}
+	is.Nil(json.Unmarshal(buf.Bytes(), &record))
+	is.Equal("alice", record.Actor)
+	is.Equal("Set", record.Operation)
+}
@@ -0,0 +1,294 @@
+package gokvstores
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupVersion is the version of the backup format.
+const backupVersion = 1
+
+// backupHeader starts a backup stream, followed by its items.
+type backupHeader struct {
+	Version int
+	Time    time.Time
+}
+
+// Backup writes the items of the given store, with their expiration, to w.
+// The store must implement Scanner. Values are gob encoded: custom types must
+// be registered with gob.Register.
+func Backup(store KVStore, w io.Writer) error {
+	scanner, ok := store.(Scanner)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(backupHeader{Version: backupVersion, Time: time.Now()}); err != nil {
+		return err
+	}
+
+	return scanner.Scan(func(item Item) error {
+		return enc.Encode(item)
+	})
+}
+
+// RestoreOptions are Restore options.
+type RestoreOptions struct {
+	// Replace flushes the store before restoring. By default the backup is
+	// merged into the store, overwriting the keys it holds.
+	Replace bool
+}
+
+// Restore loads a backup written by Backup into the given store. Keys keep
+// their remaining time to live; those expired since the backup are skipped.
+func Restore(store KVStore, r io.Reader, options *RestoreOptions) error {
+	if options == nil {
+		options = &RestoreOptions{}
+	}
+
+	dec := gob.NewDecoder(r)
+
+	var header backupHeader
+	if err := dec.Decode(&header); err != nil {
+		return err
+	}
+
+	if header.Version != backupVersion {
+		return fmt.Errorf("gokvstores: unsupported backup version %d", header.Version)
+	}
+
+	if options.Replace {
+		if err := store.Flush(); err != nil {
+			return err
+		}
+	}
+
+	for {
+		var item Item
+		if err := dec.Decode(&item); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if err := setItem(store, item); err != nil {
+			return err
+		}
+	}
+}
+
+// BackupTarget is where a BackupScheduler writes its backups, a directory or
+// an object store bucket for instance.
+type BackupTarget interface {
+	// Create returns a writer for a new backup with the given name.
+	// The backup must only be listed once the writer is closed.
+	Create(name string) (io.WriteCloser, error)
+
+	// List returns the names of the existing backups.
+	List() ([]string, error)
+
+	// Remove deletes the backup with the given name.
+	Remove(name string) error
+}
+
+// DirBackupTarget is a BackupTarget writing backups as files of a directory.
+type DirBackupTarget string
+
+// dirBackupFile writes to a temporary file, renamed once closed.
+type dirBackupFile struct {
+	*os.File
+	path string
+}
+
+func (f *dirBackupFile) Close() error {
+	if err := f.File.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.File.Name(), f.path)
+}
+
+// Create returns a writer for a new backup file.
+func (d DirBackupTarget) Create(name string) (io.WriteCloser, error) {
+	path := filepath.Join(string(d), name)
+
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return nil, err
+	}
+
+	return &dirBackupFile{File: f, path: path}, nil
+}
+
+// List returns the names of the backup files.
+func (d DirBackupTarget) List() ([]string, error) {
+	entries, err := os.ReadDir(string(d))
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() && !strings.HasSuffix(entry.Name(), ".tmp") {
+			names = append(names, entry.Name())
+		}
+	}
+
+	return names, nil
+}
+
+// Remove deletes the given backup file.
+func (d DirBackupTarget) Remove(name string) error {
+	return os.Remove(filepath.Join(string(d), name))
+}
+
+// BackupSchedulerOptions are BackupScheduler options.
+type BackupSchedulerOptions struct {
+	// Interval is the delay between two backups. Defaults to an hour.
+	Interval time.Duration
+
+	// Retention is the number of backups kept, older ones being removed.
+	// Zero keeps them all.
+	Retention int
+
+	// Prefix starts the backup names. Defaults to "kvstore-".
+	Prefix string
+
+	// OnError is called when a backup fails.
+	OnError func(err error)
+}
+
+// backupTimeFormat is the format of the time in backup names, sorting chronologically.
+const backupTimeFormat = "20060102T150405.000000000Z"
+
+// BackupScheduler periodically backs up a store to a BackupTarget.
+type BackupScheduler struct {
+	store   KVStore
+	target  BackupTarget
+	options BackupSchedulerOptions
+
+	// mu serializes backups.
+	mu sync.Mutex
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// NewBackupScheduler returns a BackupScheduler backing up the given store,
+// which must implement Scanner. The first backup is taken after an interval.
+func NewBackupScheduler(store KVStore, target BackupTarget, options *BackupSchedulerOptions) *BackupScheduler {
+	if options == nil {
+		options = &BackupSchedulerOptions{}
+	}
+
+	s := &BackupScheduler{
+		store:   store,
+		target:  target,
+		options: *options,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	if s.options.Interval <= 0 {
+		s.options.Interval = time.Hour
+	}
+
+	if s.options.Prefix == "" {
+		s.options.Prefix = "kvstore-"
+	}
+
+	go s.run()
+
+	return s
+}
+
+// run takes backups until the scheduler is closed.
+func (s *BackupScheduler) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.stop:
+			return
+		}
+
+		if _, err := s.Backup(); err != nil && s.options.OnError != nil {
+			s.options.OnError(err)
+		}
+	}
+}
+
+// Backup takes a backup now, then applies the retention. It returns the
+// name of the backup.
+func (s *BackupScheduler) Backup() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := s.options.Prefix + time.Now().UTC().Format(backupTimeFormat)
+
+	w, err := s.target.Create(name)
+	if err != nil {
+		return "", err
+	}
+
+	if err := Backup(s.store, w); err != nil {
+		w.Close()
+		s.target.Remove(name)
+		return "", err
+	}
+
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	return name, s.prune()
+}
+
+// prune removes the backups exceeding the retention.
+func (s *BackupScheduler) prune() error {
+	if s.options.Retention <= 0 {
+		return nil
+	}
+
+	names, err := s.target.List()
+	if err != nil {
+		return err
+	}
+
+	backups := names[:0]
+	for _, name := range names {
+		if strings.HasPrefix(name, s.options.Prefix) {
+			backups = append(backups, name)
+		}
+	}
+
+	sort.Strings(backups)
+
+	for len(backups) > s.options.Retention {
+		if err := s.target.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+
+	return nil
+}
+
+// Close stops the scheduler.
+func (s *BackupScheduler) Close() error {
+	s.closeOnce.Do(func() { close(s.stop) })
+	<-s.done
+	return nil
+}
@@ -0,0 +1,139 @@
+package gokvstores
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackup(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewMemoryStore(NoExpiration, time.Second*10)
+	is.Nil(err)
+
+	is.Nil(store.Set("key", "value"))
+	is.Nil(store.SetWithExpiration("short", "value", time.Minute))
+	is.Nil(store.SetMap("map", map[string]interface{}{"language": "go"}))
+
+	var buf bytes.Buffer
+	is.Nil(Backup(store, &buf))
+
+	dec := gob.NewDecoder(&buf)
+
+	var header backupHeader
+	is.Nil(dec.Decode(&header))
+	is.Equal(backupVersion, header.Version)
+
+	items := map[string]Item{}
+	for {
+		var item Item
+		if err := dec.Decode(&item); err == io.EOF {
+			break
+		} else {
+			is.Nil(err)
+		}
+		items[item.Key] = item
+	}
+
+	is.Len(items, 3)
+	is.Equal("value", items["key"].Value)
+	is.True(items["key"].Expiration.IsZero())
+	is.WithinDuration(time.Now().Add(time.Minute), items["short"].Expiration, time.Second)
+	is.Equal(map[string]interface{}{"language": "go"}, items["map"].Value)
+
+	is.Equal(ErrNotSupported, Backup(DummyStore{}, &buf))
+}
+
+func TestBackupScheduler(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+	is.Nil(store.Set("key", "value"))
+
+	target := DirBackupTarget(t.TempDir())
+
+	scheduler := NewBackupScheduler(store, target, &BackupSchedulerOptions{Retention: 2})
+
+	names := []string{}
+	for i := 0; i < 3; i++ {
+		name, err := scheduler.Backup()
+		is.Nil(err)
+		is.True(strings.HasPrefix(name, "kvstore-"))
+		names = append(names, name)
+	}
+
+	listed, err := target.List()
+	is.Nil(err)
+	is.Equal(names[1:], listed)
+
+	is.Nil(scheduler.Close())
+}
+
+func TestRestore(t *testing.T) {
+	is := assert.New(t)
+
+	source, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	is.Nil(source.Set("key", "value"))
+	is.Nil(source.SetWithExpiration("short", "value", time.Minute))
+	is.Nil(source.SetWithExpiration("expired", "value", time.Millisecond))
+	is.Nil(source.SetSlice("slice", []interface{}{"a", "b"}))
+
+	var buf bytes.Buffer
+	is.Nil(Backup(source, &buf))
+	data := buf.Bytes()
+
+	// Merge, once the short item expired.
+
+	clock := NewManualClock(time.Now().Add(5 * time.Millisecond))
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{
+		Expiration:      time.Second * 10,
+		CleanupInterval: time.Second * 10,
+		Clock:           clock,
+	})
+	is.Nil(err)
+
+	is.Nil(store.Set("key", "old"))
+	is.Nil(store.Set("other", "value"))
+
+	is.Nil(Restore(store, bytes.NewReader(data), nil))
+
+	v, err := store.Get("key")
+	is.Nil(err)
+	is.Equal("value", v)
+
+	s, err := store.GetSlice("slice")
+	is.Nil(err)
+	is.Equal([]interface{}{"a", "b"}, s)
+
+	exists, err := store.Exists("other")
+	is.Nil(err)
+	is.True(exists)
+
+	exists, err = store.Exists("expired")
+	is.Nil(err)
+	is.False(exists)
+
+	snapshot, err := store.(Snapshotter).Snapshot()
+	is.Nil(err)
+	is.WithinDuration(time.Now().Add(time.Minute), snapshot.Expiration("short"), time.Second)
+
+	// Replace
+
+	is.Nil(Restore(store, bytes.NewReader(data), &RestoreOptions{Replace: true}))
+
+	exists, err = store.Exists("other")
+	is.Nil(err)
+	is.False(exists)
+
+	is.NotNil(Restore(store, strings.NewReader("garbage"), nil))
+}
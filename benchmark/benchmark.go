@@ -0,0 +1,211 @@
+// Package benchmark runs standardized workloads against KVStore
+// implementations, to compare backends.
+package benchmark
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/louiseGrandjonc/gokvstores"
+)
+
+// Workload describes the operations run by a benchmark.
+type Workload struct {
+	// Operations is the number of measured operations. Defaults to 10000.
+	Operations int
+
+	// Concurrency is the number of concurrent clients. Defaults to 1.
+	Concurrency int
+
+	// ReadRatio is the fraction (between 0 and 1) of operations which are reads,
+	// the others being writes.
+	ReadRatio float64
+
+	// Keys is the number of distinct keys, written before the benchmark starts.
+	// Defaults to 1000.
+	Keys int
+
+	// ValueSizes are the sizes, in bytes, of the written values, picked at
+	// random for each write. Defaults to 100 bytes.
+	ValueSizes []int
+
+	// Seed seeds the random choice of keys, operations and value sizes.
+	Seed int64
+}
+
+// Latencies are latency percentiles.
+type Latencies struct {
+	P50 time.Duration
+	P90 time.Duration
+	P99 time.Duration
+	Max time.Duration
+}
+
+// String returns the percentiles on a single line.
+func (l Latencies) String() string {
+	return fmt.Sprintf("p50=%s p90=%s p99=%s max=%s", l.P50, l.P90, l.P99, l.Max)
+}
+
+// Result is the outcome of a benchmark.
+type Result struct {
+	Operations int
+	Reads      int
+	Writes     int
+	Errors     int
+
+	// Duration is the time taken by the measured operations.
+	Duration time.Duration
+
+	// Throughput is the number of operations per second.
+	Throughput float64
+
+	// ReadLatencies and WriteLatencies are the latencies of reads and writes.
+	ReadLatencies  Latencies
+	WriteLatencies Latencies
+}
+
+// String returns a human readable report.
+func (r Result) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%d operations (%d reads, %d writes, %d errors) in %s: %.0f ops/s\n",
+		r.Operations, r.Reads, r.Writes, r.Errors, r.Duration, r.Throughput)
+	fmt.Fprintf(&b, "reads:  %s\n", r.ReadLatencies)
+	fmt.Fprintf(&b, "writes: %s\n", r.WriteLatencies)
+
+	return b.String()
+}
+
+// sample is a single measured operation.
+type sample struct {
+	read    bool
+	latency time.Duration
+	failed  bool
+}
+
+// Run runs the workload against the given store. Keys are prefixed with
+// "benchmark:" and are left in the store.
+func Run(store gokvstores.KVStore, workload Workload) (Result, error) {
+	if workload.Operations <= 0 {
+		workload.Operations = 10000
+	}
+
+	if workload.Concurrency <= 0 {
+		workload.Concurrency = 1
+	}
+
+	if workload.Keys <= 0 {
+		workload.Keys = 1000
+	}
+
+	if len(workload.ValueSizes) == 0 {
+		workload.ValueSizes = []int{100}
+	}
+
+	values := make([]string, len(workload.ValueSizes))
+	for i, size := range workload.ValueSizes {
+		values[i] = strings.Repeat("x", size)
+	}
+
+	key := func(i int) string {
+		return "benchmark:" + strconv.Itoa(i)
+	}
+
+	for i := 0; i < workload.Keys; i++ {
+		if err := store.Set(key(i), values[i%len(values)]); err != nil {
+			return Result{}, err
+		}
+	}
+
+	samples := make([][]sample, workload.Concurrency)
+
+	var wg sync.WaitGroup
+	start := time.Now()
+
+	for c := 0; c < workload.Concurrency; c++ {
+		operations := workload.Operations / workload.Concurrency
+		if c < workload.Operations%workload.Concurrency {
+			operations++
+		}
+
+		wg.Add(1)
+		go func(c, operations int) {
+			defer wg.Done()
+
+			rnd := rand.New(rand.NewSource(workload.Seed + int64(c)))
+			client := make([]sample, 0, operations)
+
+			for i := 0; i < operations; i++ {
+				k := key(rnd.Intn(workload.Keys))
+				s := sample{read: rnd.Float64() < workload.ReadRatio}
+
+				begin := time.Now()
+
+				var err error
+				if s.read {
+					_, err = store.Get(k)
+				} else {
+					err = store.Set(k, values[rnd.Intn(len(values))])
+				}
+
+				s.latency = time.Since(begin)
+				s.failed = err != nil
+				client = append(client, s)
+			}
+
+			samples[c] = client
+		}(c, operations)
+	}
+
+	wg.Wait()
+
+	result := Result{Duration: time.Since(start)}
+
+	var reads, writes []time.Duration
+	for _, client := range samples {
+		for _, s := range client {
+			if s.failed {
+				result.Errors++
+			}
+
+			if s.read {
+				reads = append(reads, s.latency)
+			} else {
+				writes = append(writes, s.latency)
+			}
+		}
+	}
+
+	result.Reads, result.Writes = len(reads), len(writes)
+	result.Operations = result.Reads + result.Writes
+	result.Throughput = float64(result.Operations) / result.Duration.Seconds()
+	result.ReadLatencies = percentiles(reads)
+	result.WriteLatencies = percentiles(writes)
+
+	return result, nil
+}
+
+// percentiles returns the percentiles of the given latencies.
+func percentiles(latencies []time.Duration) Latencies {
+	if len(latencies) == 0 {
+		return Latencies{}
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	at := func(p float64) time.Duration {
+		return latencies[int(p*float64(len(latencies)-1))]
+	}
+
+	return Latencies{
+		P50: at(0.5),
+		P90: at(0.9),
+		P99: at(0.99),
+		Max: latencies[len(latencies)-1],
+	}
+}
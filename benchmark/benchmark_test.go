@@ -0,0 +1,58 @@
+package benchmark
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/louiseGrandjonc/gokvstores"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := gokvstores.NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	result, err := Run(store, Workload{
+		Operations:  1001,
+		Concurrency: 4,
+		ReadRatio:   0.8,
+		Keys:        100,
+		ValueSizes:  []int{10, 1000},
+		Seed:        42,
+	})
+	is.Nil(err)
+
+	is.Equal(1001, result.Operations)
+	is.Equal(result.Operations, result.Reads+result.Writes)
+	is.True(result.Reads > result.Writes)
+	is.Zero(result.Errors)
+	is.True(result.Throughput > 0)
+	is.True(result.ReadLatencies.P50 <= result.ReadLatencies.P99)
+	is.True(result.ReadLatencies.P99 <= result.ReadLatencies.Max)
+	is.True(strings.HasPrefix(result.String(), "1001 operations"))
+
+	v, err := store.Get("benchmark:0")
+	is.Nil(err)
+	is.NotNil(v)
+}
+
+func TestPercentiles(t *testing.T) {
+	is := assert.New(t)
+
+	latencies := []time.Duration{}
+	for i := 100; i >= 1; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	is.Equal(Latencies{
+		P50: 50 * time.Millisecond,
+		P90: 90 * time.Millisecond,
+		P99: 99 * time.Millisecond,
+		Max: 100 * time.Millisecond,
+	}, percentiles(latencies))
+
+	is.Equal(Latencies{}, percentiles(nil))
+}
@@ -0,0 +1,374 @@
+package gokvstores
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	conv "github.com/cstockton/go-conv"
+	"github.com/redis/go-redis/v9"
+)
+
+// BloomFilter is implemented by stores able to tell whether an item was
+// probably added to a key, using a fraction of the memory of a set. False
+// positives are possible, false negatives are not.
+type BloomFilter interface {
+	// BFAdd adds the item to the filter of the key, creating it if needed,
+	// and reports whether it was not already there.
+	BFAdd(key string, item interface{}) (bool, error)
+
+	// BFExists reports whether the item was probably added to the key.
+	BFExists(key string, item interface{}) (bool, error)
+}
+
+// CuckooFilter is implemented by stores able to tell whether an item was
+// probably added to a key, as a BloomFilter which also supports deletions.
+type CuckooFilter interface {
+	// CFAdd adds the item to the filter of the key, creating it if needed.
+	// An item added twice must be deleted twice.
+	CFAdd(key string, item interface{}) error
+
+	// CFExists reports whether the item was probably added to the key.
+	CFExists(key string, item interface{}) (bool, error)
+
+	// CFDel deletes one occurrence of the item and reports whether it was
+	// found.
+	CFDel(key string, item interface{}) (bool, error)
+}
+
+// Parameters of the first layer of a bloomFilter, which match the RedisBloom
+// defaults. Each layer is twice larger than the previous one, with half its
+// error rate.
+const (
+	bloomCapacity  = 100
+	bloomErrorRate = 0.01
+)
+
+// bloomLayer is a fixed capacity Bloom filter.
+type bloomLayer struct {
+	bits     []uint64
+	hashes   int
+	capacity int
+	count    int
+}
+
+// newBloomLayer returns a bloomLayer holding capacity items with the given
+// error rate.
+func newBloomLayer(capacity int, errorRate float64) *bloomLayer {
+	size := int(math.Ceil(-float64(capacity) * math.Log(errorRate) / (math.Ln2 * math.Ln2)))
+	hashes := int(math.Ceil(float64(size) / float64(capacity) * math.Ln2))
+
+	return &bloomLayer{
+		bits:     make([]uint64, (size+63)/64),
+		hashes:   hashes,
+		capacity: capacity,
+	}
+}
+
+// positions calls fn with the bit positions of the hash, using double hashing.
+func (l *bloomLayer) positions(hash uint64, fn func(word int, mask uint64) bool) bool {
+	size := uint64(len(l.bits) * 64)
+	h1, h2 := hash>>32, hash&0xffffffff|1
+
+	for i := uint64(0); i < uint64(l.hashes); i++ {
+		bit := (h1 + i*h2) % size
+		if !fn(int(bit/64), 1<<(bit%64)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// has reports whether the hash was probably added.
+func (l *bloomLayer) has(hash uint64) bool {
+	return l.positions(hash, func(word int, mask uint64) bool {
+		return l.bits[word]&mask != 0
+	})
+}
+
+// add adds the hash.
+func (l *bloomLayer) add(hash uint64) {
+	l.positions(hash, func(word int, mask uint64) bool {
+		l.bits[word] |= mask
+		return true
+	})
+	l.count++
+}
+
+// bloomFilter is the MemoryStore BloomFilter value. It is a scalable Bloom
+// filter, adding a layer when the last one is full.
+type bloomFilter struct {
+	mu     sync.RWMutex
+	layers []*bloomLayer
+}
+
+// add adds the item and reports whether it was not already there.
+func (f *bloomFilter) add(item interface{}) bool {
+	hash := hllHash(conv.String(item))
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, layer := range f.layers {
+		if layer.has(hash) {
+			return false
+		}
+	}
+
+	last := f.layers[len(f.layers)-1]
+	if last.count >= last.capacity {
+		last = newBloomLayer(last.capacity*2, bloomErrorRate*math.Pow(0.5, float64(len(f.layers))))
+		f.layers = append(f.layers, last)
+	}
+	last.add(hash)
+
+	return true
+}
+
+// exists reports whether the item was probably added.
+func (f *bloomFilter) exists(item interface{}) bool {
+	hash := hllHash(conv.String(item))
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for _, layer := range f.layers {
+		if layer.has(hash) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// size returns the number of bytes of the layers.
+func (f *bloomFilter) size() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	size := 0
+	for _, layer := range f.layers {
+		size += len(layer.bits) * 8
+	}
+	return size
+}
+
+// cuckooFilter is the MemoryStore CuckooFilter value. It counts the items
+// added, so it has no false positives.
+type cuckooFilter struct {
+	mu    sync.RWMutex
+	items map[string]int
+}
+
+// ----------------------------------------------------------------------------
+// Memory
+// ----------------------------------------------------------------------------
+
+// bloomFilter returns the bloomFilter of the given key, creating it if create
+// is set. It returns nil if the key does not exist and create is not set.
+func (c *MemoryStore) bloomFilter(key string, create bool) (*bloomFilter, error) {
+	var newValue func() interface{}
+	if create {
+		newValue = func() interface{} {
+			return &bloomFilter{layers: []*bloomLayer{newBloomLayer(bloomCapacity, bloomErrorRate)}}
+		}
+	}
+
+	v, found := c.getOrAdd(key, newValue)
+	if !found {
+		return nil, nil
+	}
+
+	f, ok := v.(*bloomFilter)
+	if !ok {
+		return nil, fmt.Errorf("gokvstores: %q is not a Bloom filter", key)
+	}
+
+	return f, nil
+}
+
+// BFAdd adds the item to the filter of the key, creating it if needed, and
+// reports whether it was not already there.
+func (c *MemoryStore) BFAdd(key string, item interface{}) (bool, error) {
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	f, err := c.bloomFilter(key, true)
+	c.stats.write(err)
+	if err != nil {
+		return false, err
+	}
+
+	added := f.add(item)
+	if added {
+		c.watches.notify(ChangeSet, key)
+	}
+
+	return added, nil
+}
+
+// BFExists reports whether the item was probably added to the key.
+func (c *MemoryStore) BFExists(key string, item interface{}) (bool, error) {
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	f, err := c.bloomFilter(key, false)
+	c.stats.read(f != nil, err)
+	if f == nil || err != nil {
+		return false, err
+	}
+
+	return f.exists(item), nil
+}
+
+// cuckooFilter returns the cuckooFilter of the given key, creating it if
+// create is set. It returns nil if the key does not exist and create is not
+// set.
+func (c *MemoryStore) cuckooFilter(key string, create bool) (*cuckooFilter, error) {
+	var newValue func() interface{}
+	if create {
+		newValue = func() interface{} { return &cuckooFilter{items: map[string]int{}} }
+	}
+
+	v, found := c.getOrAdd(key, newValue)
+	if !found {
+		return nil, nil
+	}
+
+	f, ok := v.(*cuckooFilter)
+	if !ok {
+		return nil, fmt.Errorf("gokvstores: %q is not a cuckoo filter", key)
+	}
+
+	return f, nil
+}
+
+// CFAdd adds the item to the filter of the key, creating it if needed.
+func (c *MemoryStore) CFAdd(key string, item interface{}) error {
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	f, err := c.cuckooFilter(key, true)
+	c.stats.write(err)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.items[conv.String(item)]++
+	f.mu.Unlock()
+
+	c.watches.notify(ChangeSet, key)
+	return nil
+}
+
+// CFExists reports whether the item was added to the key.
+func (c *MemoryStore) CFExists(key string, item interface{}) (bool, error) {
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	f, err := c.cuckooFilter(key, false)
+	c.stats.read(f != nil, err)
+	if f == nil || err != nil {
+		return false, err
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.items[conv.String(item)] > 0, nil
+}
+
+// CFDel deletes one occurrence of the item and reports whether it was found.
+func (c *MemoryStore) CFDel(key string, item interface{}) (bool, error) {
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	f, err := c.cuckooFilter(key, false)
+	c.stats.write(err)
+	if f == nil || err != nil {
+		return false, err
+	}
+
+	s := conv.String(item)
+
+	f.mu.Lock()
+	found := f.items[s] > 0
+	if f.items[s]--; f.items[s] <= 0 {
+		delete(f.items, s)
+	}
+	f.mu.Unlock()
+
+	if found {
+		c.watches.notify(ChangeSet, key)
+	}
+
+	return found, nil
+}
+
+// ----------------------------------------------------------------------------
+// Redis
+// ----------------------------------------------------------------------------
+
+// bloomCommand runs a RedisBloom command returning an integer, and reports
+// whether it is one. It returns ErrNotSupported if the server does not have
+// the module.
+func (r *RedisStore) bloomCommand(args ...interface{}) (bool, error) {
+	if !moduleAvailable(&r.modules.bloom) {
+		return false, ErrNotSupported
+	}
+
+	cmd := redis.NewIntCmd(r.ctx, args...)
+	ok, err := r.moduleCommand(&r.modules.bloom, cmd)
+	if !ok {
+		return false, ErrNotSupported
+	}
+
+	return cmd.Val() == 1, err
+}
+
+// BFAdd adds the item to the filter of the key with BF.ADD, and reports
+// whether it was not already there. It requires the RedisBloom module.
+func (r *RedisStore) BFAdd(key string, item interface{}) (bool, error) {
+	added, err := r.bloomCommand("bf.add", key, conv.String(item))
+	r.stats.write(err)
+	return added, err
+}
+
+// BFExists reports whether the item was probably added to the key with
+// BF.EXISTS. It requires the RedisBloom module.
+func (r *RedisStore) BFExists(key string, item interface{}) (bool, error) {
+	exists, err := r.bloomCommand("bf.exists", key, conv.String(item))
+	r.stats.read(exists, err)
+	return exists, err
+}
+
+// CFAdd adds the item to the filter of the key with CF.ADD. It requires the
+// RedisBloom module.
+func (r *RedisStore) CFAdd(key string, item interface{}) error {
+	_, err := r.bloomCommand("cf.add", key, conv.String(item))
+	r.stats.write(err)
+	return err
+}
+
+// CFExists reports whether the item was probably added to the key with
+// CF.EXISTS. It requires the RedisBloom module.
+func (r *RedisStore) CFExists(key string, item interface{}) (bool, error) {
+	exists, err := r.bloomCommand("cf.exists", key, conv.String(item))
+	r.stats.read(exists, err)
+	return exists, err
+}
+
+// CFDel deletes one occurrence of the item with CF.DEL and reports whether
+// it was found. It requires the RedisBloom module.
+func (r *RedisStore) CFDel(key string, item interface{}) (bool, error) {
+	found, err := r.bloomCommand("cf.del", key, conv.String(item))
+	if err != nil && strings.Contains(strings.ToLower(err.Error()), "not found") {
+		found, err = false, nil
+	}
+
+	r.stats.write(err)
+	return found, err
+}
@@ -0,0 +1,90 @@
+package gokvstores
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testBloomFilter(t *testing.T, store KVStore) {
+	is := assert.New(t)
+
+	bf := store.(BloomFilter)
+
+	is.Nil(store.Delete("seen"))
+
+	exists, err := bf.BFExists("seen", "a")
+	is.Nil(err)
+	is.False(exists)
+
+	added, err := bf.BFAdd("seen", "a")
+	is.Nil(err)
+	is.True(added)
+
+	added, err = bf.BFAdd("seen", "a")
+	is.Nil(err)
+	is.False(added)
+
+	for i := 0; i < 1000; i++ {
+		_, err = bf.BFAdd("seen", i)
+		is.Nil(err)
+	}
+
+	exists, err = bf.BFExists("seen", "a")
+	is.Nil(err)
+	is.True(exists)
+
+	exists, err = bf.BFExists("seen", 999)
+	is.Nil(err)
+	is.True(exists)
+
+	falsePositives := 0
+	for i := 0; i < 1000; i++ {
+		exists, err = bf.BFExists("seen", fmt.Sprintf("missing-%d", i))
+		is.Nil(err)
+		if exists {
+			falsePositives++
+		}
+	}
+	is.True(falsePositives < 30, falsePositives)
+
+	cf := store.(CuckooFilter)
+
+	is.Nil(store.Delete("seen"))
+
+	found, err := cf.CFDel("seen", "a")
+	is.Nil(err)
+	is.False(found)
+
+	is.Nil(cf.CFAdd("seen", "a"))
+	is.Nil(cf.CFAdd("seen", "a"))
+
+	exists, err = cf.CFExists("seen", "a")
+	is.Nil(err)
+	is.True(exists)
+
+	found, err = cf.CFDel("seen", "a")
+	is.Nil(err)
+	is.True(found)
+
+	exists, err = cf.CFExists("seen", "a")
+	is.Nil(err)
+	is.True(exists)
+
+	found, err = cf.CFDel("seen", "a")
+	is.Nil(err)
+	is.True(found)
+
+	exists, err = cf.CFExists("seen", "a")
+	is.Nil(err)
+	is.False(exists)
+}
+
+func TestMemoryStoreBloomFilter(t *testing.T) {
+	store, err := NewMemoryStore(time.Second*10, time.Second*10)
+	assert.Nil(t, err)
+
+	testBloomFilter(t, store)
+}
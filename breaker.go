@@ -0,0 +1,185 @@
+package gokvstores
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a BreakerStore circuit.
+type BreakerState int
+
+// Circuit states.
+const (
+	// BreakerClosed lets every operation through.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects every operation.
+	BreakerOpen
+	// BreakerHalfOpen lets a single probe operation through.
+	BreakerHalfOpen
+)
+
+// String returns the state name.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// BreakerOptions are BreakerStore options.
+type BreakerOptions struct {
+	// Threshold is the number of consecutive failures tripping the circuit. Defaults to 5.
+	Threshold int
+
+	// Timeout is how long the circuit stays open before a probe is attempted. Defaults to 30s.
+	Timeout time.Duration
+
+	// Fallback, if set, serves operations while the circuit is open instead of
+	// returning ErrCircuitOpen.
+	Fallback KVStore
+
+	// IsFailure reports whether an error counts as a backend failure.
+	// Defaults to any non-nil error.
+	IsFailure func(err error) bool
+
+	// OnStateChange is called whenever the circuit changes state.
+	OnStateChange func(from, to BreakerState)
+}
+
+// BreakerStore is a KVStore decorator implementing the circuit breaker pattern:
+// after consecutive failures, operations fail fast until a probe succeeds.
+// Close is always forwarded to the wrapped store.
+type BreakerStore struct {
+	*interceptedStore
+
+	options BreakerOptions
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewBreakerStore returns a BreakerStore wrapping the given store.
+func NewBreakerStore(store KVStore, options *BreakerOptions) *BreakerStore {
+	if options == nil {
+		options = &BreakerOptions{}
+	}
+
+	b := &BreakerStore{options: *options}
+
+	if b.options.Threshold <= 0 {
+		b.options.Threshold = 5
+	}
+
+	if b.options.Timeout <= 0 {
+		b.options.Timeout = 30 * time.Second
+	}
+
+	if b.options.IsFailure == nil {
+		b.options.IsFailure = func(err error) bool { return err != nil }
+	}
+
+	b.interceptedStore = &interceptedStore{store: store, intercept: b.guard}
+
+	return b
+}
+
+// State returns the current circuit state.
+func (b *BreakerStore) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.options.Timeout {
+		return BreakerHalfOpen
+	}
+
+	return b.state
+}
+
+// guard lets an operation through if the circuit allows it.
+func (b *BreakerStore) guard(op *operation, next func() error) error {
+	if op.name == "Close" {
+		return next()
+	}
+
+	if !b.allow() {
+		if b.options.Fallback == nil {
+			return ErrCircuitOpen
+		}
+
+		op.store = b.options.Fallback
+
+		return next()
+	}
+
+	err := next()
+	b.record(b.options.IsFailure(err))
+
+	return err
+}
+
+// allow reports whether an operation may reach the wrapped store.
+func (b *BreakerStore) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerClosed:
+		return true
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.options.Timeout {
+			return false
+		}
+		b.setState(BreakerHalfOpen)
+	}
+
+	if b.probing {
+		return false
+	}
+
+	b.probing = true
+
+	return true
+}
+
+// record updates the circuit with the outcome of an operation.
+func (b *BreakerStore) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+
+	if !failed {
+		b.failures = 0
+		b.setState(BreakerClosed)
+		return
+	}
+
+	b.failures++
+
+	if b.state == BreakerHalfOpen || b.failures >= b.options.Threshold {
+		b.openedAt = time.Now()
+		b.setState(BreakerOpen)
+	}
+}
+
+// setState changes the circuit state, b.mu must be held.
+func (b *BreakerStore) setState(state BreakerState) {
+	if b.state == state {
+		return
+	}
+
+	from := b.state
+	b.state = state
+
+	if b.options.OnStateChange != nil {
+		b.options.OnStateChange(from, state)
+	}
+}
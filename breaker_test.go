@@ -0,0 +1,82 @@
+package gokvstores
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreakerStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	testStore(t, NewBreakerStore(memory, nil))
+
+	is.Nil(memory.Set("key", "value"))
+
+	flaky := &flakyStore{KVStore: memory, err: errors.New("down"), failures: 3}
+	store := NewBreakerStore(flaky, &BreakerOptions{
+		Threshold: 2,
+		Timeout:   50 * time.Millisecond,
+	})
+
+	_, err = store.Get("key")
+	is.NotNil(err)
+	is.Equal(BreakerClosed, store.State())
+
+	_, err = store.Get("key")
+	is.NotNil(err)
+	is.Equal(BreakerOpen, store.State())
+
+	_, err = store.Get("key")
+	is.Equal(ErrCircuitOpen, err)
+	is.Equal(2, flaky.calls)
+
+	// Failed probe
+
+	time.Sleep(60 * time.Millisecond)
+	is.Equal(BreakerHalfOpen, store.State())
+
+	_, err = store.Get("key")
+	is.NotNil(err)
+	is.NotEqual(ErrCircuitOpen, err)
+	is.Equal(BreakerOpen, store.State())
+
+	// Successful probe
+
+	time.Sleep(60 * time.Millisecond)
+
+	v, err := store.Get("key")
+	is.Nil(err)
+	is.Equal("value", v)
+	is.Equal(BreakerClosed, store.State())
+}
+
+func TestBreakerStoreFallback(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	fallback, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+	is.Nil(fallback.Set("key", "fallback"))
+
+	flaky := &flakyStore{KVStore: memory, err: errors.New("down"), failures: 1}
+	store := NewBreakerStore(flaky, &BreakerOptions{
+		Threshold: 1,
+		Timeout:   time.Minute,
+		Fallback:  fallback,
+	})
+
+	_, err = store.Get("key")
+	is.NotNil(err)
+
+	v, err := store.Get("key")
+	is.Nil(err)
+	is.Equal("fallback", v)
+}
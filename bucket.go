@@ -0,0 +1,112 @@
+package gokvstores
+
+import "strings"
+
+// MemoryBucket is a namespace of a MemoryStore, returned by Bucket. Its keys
+// are stored in the MemoryStore prefixed by the bucket name and a colon, so
+// buckets share its cleanup and its MaxEntries and MaxWeight budget, while
+// having their own Flush and Stats.
+type MemoryBucket struct {
+	// stats comes first to keep its counters 64-bit aligned.
+	stats statsCounter
+
+	*interceptedStore
+
+	memory *MemoryStore
+	name   string
+	prefix string
+}
+
+// Bucket returns the bucket of the given name, creating it on first use.
+// Flushing a bucket only deletes its keys, closing it does nothing.
+func (c *MemoryStore) Bucket(name string) *MemoryBucket {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if b, ok := c.buckets[name]; ok {
+		return b
+	}
+
+	b := &MemoryBucket{
+		memory: c,
+		name:   name,
+		prefix: name + ":",
+	}
+	b.interceptedStore = &interceptedStore{store: c, intercept: b.intercept}
+
+	if c.buckets == nil {
+		c.buckets = map[string]*MemoryBucket{}
+	}
+	c.buckets[name] = b
+
+	return b
+}
+
+// Name returns the name of the bucket.
+func (b *MemoryBucket) Name() string {
+	return b.name
+}
+
+// intercept runs an operation in the namespace of the bucket.
+func (b *MemoryBucket) intercept(op *operation, next func() error) error {
+	switch op.name {
+	case "Close":
+		return nil
+	case "Ping":
+		return next()
+	case "Flush":
+		b.flush()
+		b.stats.write(nil)
+		return nil
+	}
+
+	op.key = b.prefix + op.key
+
+	err := next()
+	if op.read() {
+		b.stats.read(op.hit, err)
+	} else {
+		b.stats.write(err)
+	}
+
+	return err
+}
+
+// keys returns the unexpired keys of the bucket, spilled ones included, as
+// stored in the MemoryStore.
+func (b *MemoryBucket) keys() []string {
+	var keys []string
+	for key, item := range b.memory.items() {
+		if _, found := plainValue(item.object, true); found && strings.HasPrefix(key, b.prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	if b.memory.spill != nil {
+		b.memory.spill.unexpired(func(key string) {
+			if strings.HasPrefix(key, b.prefix) {
+				keys = append(keys, key)
+			}
+		})
+	}
+
+	return keys
+}
+
+// flush deletes the keys of the bucket.
+func (b *MemoryBucket) flush() {
+	c := b.memory
+
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	for _, key := range b.keys() {
+		c.delete(key)
+	}
+}
+
+// Stats returns the counters of the operations made through the bucket, and
+// its number of unexpired keys.
+func (b *MemoryBucket) Stats() (Stats, error) {
+	return b.stats.stats(int64(len(b.keys()))), nil
+}
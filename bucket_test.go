@@ -0,0 +1,66 @@
+package gokvstores
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreBucket(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{MaxEntries: 3})
+	is.Nil(err)
+
+	memory := store.(*MemoryStore)
+
+	users := memory.Bucket("users")
+	sessions := memory.Bucket("sessions")
+	is.True(users == memory.Bucket("users"))
+	is.Equal("users", users.Name())
+
+	is.Nil(users.Set("1", "alice"))
+	is.Nil(sessions.Set("1", "token"))
+	is.Nil(store.Set("other", "value"))
+
+	value, err := users.Get("1")
+	is.Nil(err)
+	is.Equal("alice", value)
+
+	value, err = store.Get("sessions:1")
+	is.Nil(err)
+	is.Equal("token", value)
+
+	exists, err := sessions.Exists("2")
+	is.Nil(err)
+	is.False(exists)
+
+	stats, err := sessions.Stats()
+	is.Nil(err)
+	is.Equal(Stats{Operations: 2, Misses: 1, Keys: 1}, stats)
+
+	// Buckets share the MaxEntries budget of the store.
+	_, err = store.Get("users:1")
+	is.Nil(err)
+	_, err = store.Get("other")
+	is.Nil(err)
+
+	is.Nil(users.Set("2", "bob"))
+	exists, err = sessions.Exists("1")
+	is.Nil(err)
+	is.False(exists)
+
+	is.Nil(users.Flush())
+	for key, expected := range map[string]bool{"users:1": false, "users:2": false, "other": true} {
+		exists, err := store.Exists(key)
+		is.Nil(err)
+		is.Equal(expected, exists, key)
+	}
+
+	stats, err = users.Stats()
+	is.Nil(err)
+	is.Equal(Stats{Operations: 4, Hits: 1, Keys: 0}, stats)
+
+	is.Nil(users.Close())
+	is.Nil(users.Set("3", "carol"))
+}
@@ -0,0 +1,125 @@
+package gokvstores
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ChaosRule configures the faults injected in an operation.
+type ChaosRule struct {
+	// ErrorRate is the probability, between 0 and 1, for the operation to fail.
+	ErrorRate float64
+
+	// Error is the error returned by failing operations. Defaults to ErrInjectedFault.
+	Error error
+
+	// Latency is added to every operation.
+	Latency time.Duration
+
+	// PartialFailure makes failing operations reach the wrapped store before
+	// returning the error, as when a write is applied but not acknowledged.
+	PartialFailure bool
+}
+
+// ChaosOptions are ChaosStore options.
+type ChaosOptions struct {
+	// Rule applies to all operations.
+	Rule ChaosRule
+
+	// Operations overrides the rule per operation, keyed by method name (e.g. "Get").
+	Operations map[string]ChaosRule
+
+	// Seed makes injected failures deterministic when not zero.
+	Seed int64
+}
+
+// ChaosStore is a KVStore decorator injecting failures and latency, to test
+// the resilience of applications to store incidents. Close is never altered.
+type ChaosStore struct {
+	*interceptedStore
+
+	mu         sync.Mutex
+	rand       *rand.Rand
+	rule       ChaosRule
+	operations map[string]ChaosRule
+}
+
+// NewChaosStore returns a ChaosStore wrapping the given store.
+func NewChaosStore(store KVStore, options *ChaosOptions) *ChaosStore {
+	if options == nil {
+		options = &ChaosOptions{}
+	}
+
+	seed := options.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	c := &ChaosStore{
+		rand:       rand.New(rand.NewSource(seed)),
+		rule:       options.Rule,
+		operations: make(map[string]ChaosRule, len(options.Operations)),
+	}
+
+	for name, rule := range options.Operations {
+		c.operations[name] = rule
+	}
+
+	c.interceptedStore = &interceptedStore{store: store, intercept: c.inject}
+
+	return c
+}
+
+// SetRule changes the rule of the given operation, or of all operations if empty.
+func (c *ChaosStore) SetRule(operation string, rule ChaosRule) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if operation == "" {
+		c.rule = rule
+		return
+	}
+
+	c.operations[operation] = rule
+}
+
+// plan returns the rule of the given operation and whether it must fail.
+func (c *ChaosStore) plan(operation string) (ChaosRule, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rule, ok := c.operations[operation]
+	if !ok {
+		rule = c.rule
+	}
+
+	return rule, rule.ErrorRate > 0 && c.rand.Float64() < rule.ErrorRate
+}
+
+// inject applies the faults of the operation rule.
+func (c *ChaosStore) inject(op *operation, next func() error) error {
+	if op.name == "Close" {
+		return next()
+	}
+
+	rule, fail := c.plan(op.name)
+
+	if rule.Latency > 0 {
+		time.Sleep(rule.Latency)
+	}
+
+	if !fail {
+		return next()
+	}
+
+	if rule.PartialFailure {
+		next()
+	}
+
+	if rule.Error != nil {
+		return rule.Error
+	}
+
+	return ErrInjectedFault
+}
@@ -0,0 +1,59 @@
+package gokvstores
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChaosStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	store := NewChaosStore(memory, nil)
+
+	testStore(t, store)
+
+	store.SetRule("Get", ChaosRule{ErrorRate: 1})
+
+	is.Nil(store.Set("key", "value"))
+
+	_, err = store.Get("key")
+	is.Equal(ErrInjectedFault, err)
+
+	// Partial failures reach the store
+
+	failure := errors.New("timeout")
+	store.SetRule("Set", ChaosRule{ErrorRate: 1, Error: failure, PartialFailure: true})
+
+	is.Equal(failure, store.Set("key", "other"))
+
+	v, err := memory.Get("key")
+	is.Nil(err)
+	is.Equal("other", v)
+
+	// Latency
+
+	store.SetRule("", ChaosRule{Latency: 20 * time.Millisecond})
+
+	start := time.Now()
+	_, err = store.Exists("key")
+	is.Nil(err)
+	is.True(time.Since(start) >= 20*time.Millisecond)
+
+	// Error rates
+
+	store = NewChaosStore(memory, &ChaosOptions{Rule: ChaosRule{ErrorRate: 0.5}, Seed: 42})
+
+	failures := 0
+	for i := 0; i < 1000; i++ {
+		if _, err := store.Get("key"); err != nil {
+			failures++
+		}
+	}
+	is.True(failures > 400 && failures < 600)
+}
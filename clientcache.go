@@ -0,0 +1,222 @@
+package gokvstores
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// invalidationChannel is the channel Redis sends tracking invalidations on.
+const invalidationChannel = "__redis__:invalidate"
+
+// ClientSideCacheOptions are the options of the Redis client-side cache.
+type ClientSideCacheOptions struct {
+	// MaxEntries is the maximum number of keys kept in memory, 10000 by
+	// default. Once reached, read keys are no longer cached until others
+	// are invalidated.
+	MaxEntries int
+}
+
+// cachedRead identifies the operation which read a cached value, so that a
+// key is only served to the operation which cached it.
+type cachedRead int
+
+const (
+	cachedGet cachedRead = iota
+	cachedGetMap
+	cachedGetSlice
+)
+
+// localEntry is a value of a localCache. An entry without value is a read in
+// progress, which an invalidation discards.
+type localEntry struct {
+	read   cachedRead
+	value  interface{}
+	filled bool
+}
+
+// localCache keeps the values read from Redis, until the server invalidates
+// them. Invalidations are received on a dedicated connection, which the
+// tracking of the store connections redirects to: if it is lost, the cache is
+// cleared and disabled.
+type localCache struct {
+	mu         sync.Mutex
+	entries    map[string]*localEntry
+	maxEntries int
+	disabled   bool
+
+	client *redis.Client
+	pubsub *redis.PubSub
+}
+
+// newLocalCache returns a localCache receiving invalidations through a
+// connection opened with the given options, and the client ID of that
+// connection, which the store connections must redirect their tracking to.
+func newLocalCache(opts redis.Options, options *ClientSideCacheOptions) (*localCache, int64, error) {
+	c := &localCache{
+		entries:    map[string]*localEntry{},
+		maxEntries: options.MaxEntries,
+	}
+
+	if c.maxEntries <= 0 {
+		c.maxEntries = 10000
+	}
+
+	var id int64
+	opts.OnConnect = func(ctx context.Context, conn *redis.Conn) error {
+		connID, err := conn.ClientID(ctx).Result()
+		atomic.StoreInt64(&id, connID)
+		return err
+	}
+
+	ctx := context.Background()
+
+	c.client = redis.NewClient(&opts)
+	c.pubsub = c.client.Subscribe(ctx, invalidationChannel)
+	if _, err := c.pubsub.Receive(ctx); err != nil {
+		c.close()
+		return nil, 0, err
+	}
+
+	go c.receive()
+
+	return c, atomic.LoadInt64(&id), nil
+}
+
+// receive applies invalidations until the connection is lost or closed.
+func (c *localCache) receive() {
+	for {
+		msg, err := c.pubsub.ReceiveMessage(context.Background())
+		if err != nil {
+			c.mu.Lock()
+			c.disabled = true
+			c.entries = map[string]*localEntry{}
+			c.mu.Unlock()
+			return
+		}
+
+		// A flush of the database invalidates every key, without listing them.
+		if msg.PayloadSlice == nil {
+			c.clear()
+			continue
+		}
+
+		c.invalidate(msg.PayloadSlice...)
+	}
+}
+
+// get returns the value of key read by the given operation, reading it with
+// fn if it is not cached.
+func (c *localCache) get(read cachedRead, key string, fn func() (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && entry.filled && entry.read == read {
+		c.mu.Unlock()
+		return copyValue(entry.value), nil
+	}
+
+	cache := !ok && !c.disabled && len(c.entries) < c.maxEntries
+	if cache {
+		entry = &localEntry{read: read}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	value, err := fn()
+
+	if cache {
+		c.mu.Lock()
+		if c.entries[key] == entry {
+			if err != nil {
+				delete(c.entries, key)
+			} else {
+				entry.value, entry.filled = value, true
+			}
+		}
+		c.mu.Unlock()
+	}
+
+	return copyValue(value), err
+}
+
+// invalidate discards the given keys.
+func (c *localCache) invalidate(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+}
+
+// clear discards every key.
+func (c *localCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = map[string]*localEntry{}
+}
+
+// close closes the invalidation connection.
+func (c *localCache) close() error {
+	c.pubsub.Close()
+	return c.client.Close()
+}
+
+// copyValue returns a shallow copy of maps and slices, so that callers can't
+// alter cached values.
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		m := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			m[k] = item
+		}
+		return m
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		return append([]interface{}(nil), v...)
+	}
+	return value
+}
+
+// trackingConnect returns an OnConnect function enabling the tracking of the
+// keys read by each connection, redirecting invalidations to the given client.
+func trackingConnect(redirect int64, next func(ctx context.Context, conn *redis.Conn) error) func(ctx context.Context, conn *redis.Conn) error {
+	return func(ctx context.Context, conn *redis.Conn) error {
+		cmd := redis.NewStatusCmd(ctx, "client", "tracking", "on", "redirect", redirect)
+		if err := conn.Process(ctx, cmd); err != nil {
+			return err
+		}
+
+		if next != nil {
+			return next(ctx, conn)
+		}
+		return nil
+	}
+}
+
+// cached returns the value of key read by the given operation, from the
+// client-side cache when enabled.
+func (r *RedisStore) cached(read cachedRead, key string, fn func() (interface{}, error)) (interface{}, error) {
+	if r.local == nil {
+		return fn()
+	}
+
+	return r.local.get(read, key, fn)
+}
+
+// uncache discards the given keys from the client-side cache, so that the
+// store reads its own writes before the server invalidates them.
+func (r *RedisStore) uncache(keys ...string) {
+	if r.local != nil {
+		r.local.invalidate(keys...)
+	}
+}
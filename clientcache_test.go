@@ -0,0 +1,62 @@
+package gokvstores
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalCache(t *testing.T) {
+	is := assert.New(t)
+
+	c := &localCache{entries: map[string]*localEntry{}, maxEntries: 2}
+
+	reads := 0
+	read := func(value interface{}) func() (interface{}, error) {
+		return func() (interface{}, error) {
+			reads++
+			return value, nil
+		}
+	}
+
+	value, err := c.get(cachedGet, "a", read("1"))
+	is.Nil(err)
+	is.Equal("1", value)
+
+	value, _ = c.get(cachedGet, "a", read("2"))
+	is.Equal("1", value)
+	is.Equal(1, reads)
+
+	// Values are only served to the operation which read them.
+	value, _ = c.get(cachedGetMap, "a", read(map[string]interface{}{"k": "v"}))
+	is.Equal(map[string]interface{}{"k": "v"}, value)
+	is.Equal(2, reads)
+
+	c.invalidate("a")
+
+	value, _ = c.get(cachedGet, "a", read("2"))
+	is.Equal("2", value)
+	is.Equal(3, reads)
+
+	// Values invalidated while read are not cached.
+	c.get(cachedGet, "b", func() (interface{}, error) {
+		c.invalidate("b")
+		return "stale", nil
+	})
+	value, _ = c.get(cachedGet, "b", read("fresh"))
+	is.Equal("fresh", value)
+
+	// Cached slices can't be altered by callers.
+	c.clear()
+	slice, _ := c.get(cachedGetSlice, "s", read([]interface{}{"x"}))
+	slice.([]interface{})[0] = "y"
+	value, _ = c.get(cachedGetSlice, "s", read(nil))
+	is.Equal([]interface{}{"x"}, value)
+
+	// Once full, keys are read but not cached.
+	c.get(cachedGet, "c", read("3"))
+	reads = 0
+	c.get(cachedGet, "d", read("4"))
+	c.get(cachedGet, "d", read("4"))
+	is.Equal(2, reads)
+}
@@ -0,0 +1,92 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreClock(t *testing.T) {
+	is := assert.New(t)
+
+	clock := NewManualClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{
+		Expiration: time.Minute,
+		Clock:      clock,
+	})
+	is.Nil(err)
+
+	memory := store.(*MemoryStore)
+
+	var expired []string
+	memory.OnEvicted(func(key string, value interface{}, reason EvictionReason) {
+		if reason == EvictionExpired {
+			expired = append(expired, key)
+		}
+	})
+
+	is.Nil(store.Set("key", "value"))
+	is.Nil(store.SetWithExpiration("long", "value", time.Hour))
+	is.Nil(memory.SetMapValueWithExpiration("map", "field", "value", time.Second))
+	is.Nil(memory.SetMapValue("map", "other", "value"))
+
+	is.Nil(memory.Scan(func(item Item) error {
+		if item.Key == "long" {
+			is.True(clock.Now().Add(time.Hour).Equal(item.Expiration))
+		}
+		return nil
+	}))
+
+	clock.Advance(2 * time.Second)
+
+	values, err := store.GetMap("map")
+	is.Nil(err)
+	is.Equal(map[string]interface{}{"other": "value"}, values)
+
+	clock.Advance(time.Minute)
+
+	for key, expected := range map[string]bool{"key": false, "map": false, "long": true} {
+		exists, err := store.Exists(key)
+		is.Nil(err)
+		is.Equal(expected, exists, key)
+	}
+
+	memory.deleteExpired()
+	is.ElementsMatch([]string{"key", "map"}, expired)
+
+	token, err := memory.Lock("lock", time.Second)
+	is.Nil(err)
+	clock.Advance(2 * time.Second)
+	is.Equal(ErrLockNotHeld, memory.Unlock("lock", token))
+}
+
+func TestStaleWhileRevalidateClock(t *testing.T) {
+	is := assert.New(t)
+
+	clock := NewManualClock(time.Now())
+
+	store, err := NewMemoryStore(NoExpiration, 0)
+	is.Nil(err)
+
+	loads := make(chan string, 1)
+	swr := NewStaleWhileRevalidateStore(store, func(key string) (interface{}, error) {
+		loads <- key
+		return "loaded", nil
+	}, &StaleWhileRevalidateOptions{TTL: time.Minute, Clock: clock})
+
+	is.Nil(swr.Set("key", "value"))
+
+	value, err := swr.Get("key")
+	is.Nil(err)
+	is.Equal("value", value)
+	is.Len(loads, 0)
+
+	clock.Advance(2 * time.Minute)
+
+	value, err = swr.Get("key")
+	is.Nil(err)
+	is.Equal("value", value)
+	is.Equal("key", <-loads)
+}
@@ -0,0 +1,296 @@
+// Command gokv inspects and edits the content of a key/value store.
+//
+// Usage:
+//
+//	gokv [flags] get KEY
+//	gokv [flags] set KEY VALUE [TTL]
+//	gokv [flags] del KEY...
+//	gokv [flags] keys [PREFIX]
+//	gokv [flags] dump [FILE]
+//	gokv [flags] restore [-replace] [FILE]
+//	gokv [flags] copy [-url URL | -config FILE | -addr ADDR [-cluster ADDRS] [-db DB] [-password PASSWORD]]
+//
+// The store is described by a URL, as accepted by NewStoreFromURL, or by a
+// JSON configuration file, as accepted by NewStoreFromConfig. Otherwise it is
+// a Redis server, or cluster, configured with the flags.
+// Dumps are written to the standard output and restored from the standard
+// input unless a file is given.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/louiseGrandjonc/gokvstores"
+)
+
+// errUsage is returned for invalid command lines.
+var errUsage = errors.New("invalid usage, see gokv -h")
+
+// storeFlags are the flags configuring a store.
+type storeFlags struct {
+	url      string
+	config   string
+	addr     string
+	cluster  string
+	db       int
+	password string
+}
+
+// register registers the flags on the given flag set.
+func (f *storeFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.url, "url", "", "store URL (e.g. memory:// or redis://host:6379/0), instead of the Redis flags")
+	fs.StringVar(&f.config, "config", "", "JSON store configuration file, instead of the Redis flags")
+	fs.StringVar(&f.addr, "addr", "localhost:6379", "Redis server address")
+	fs.StringVar(&f.cluster, "cluster", "", "comma separated Redis cluster addresses, instead of -addr")
+	fs.IntVar(&f.db, "db", 0, "Redis database")
+	fs.StringVar(&f.password, "password", "", "Redis password")
+}
+
+// store returns the configured store.
+func (f *storeFlags) store() (gokvstores.KVStore, error) {
+	switch {
+	case f.url != "" && f.config != "":
+		return nil, errors.New("-url and -config are exclusive")
+	case f.url != "":
+		return gokvstores.NewStoreFromURL(f.url)
+	case f.config != "":
+		data, err := os.ReadFile(f.config)
+		if err != nil {
+			return nil, err
+		}
+
+		var config gokvstores.Config
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("%s: %v", f.config, err)
+		}
+
+		return gokvstores.NewStoreFromConfig(&config)
+	}
+
+	if f.cluster != "" {
+		return gokvstores.NewRedisClusterStore(&gokvstores.RedisClusterOptions{
+			Addrs:    strings.Split(f.cluster, ","),
+			Password: f.password,
+		}, 0)
+	}
+
+	return gokvstores.NewRedisClientStore(&gokvstores.RedisClientOptions{
+		Addr:     f.addr,
+		DB:       f.db,
+		Password: f.password,
+	}, 0)
+}
+
+func main() {
+	var flags storeFlags
+	flags.register(flag.CommandLine)
+	flag.Parse()
+
+	os.Exit(execute(&flags, flag.Args(), os.Stdin, os.Stdout, os.Stderr))
+}
+
+// execute runs the command described by args against the store configured
+// by flags, closes the store and returns the exit status.
+func execute(flags *storeFlags, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	store, err := flags.store()
+	if err != nil {
+		fmt.Fprintln(stderr, "gokv:", err)
+		return 1
+	}
+
+	err = run(store, args, stdin, stdout)
+	if closeErr := store.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		fmt.Fprintln(stderr, "gokv:", err)
+		return 1
+	}
+
+	return 0
+}
+
+// run runs the command described by args against the given store.
+func run(store gokvstores.KVStore, args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	command, args := args[0], args[1:]
+
+	switch command {
+	case "get":
+		if len(args) != 1 {
+			return errUsage
+		}
+		return get(store, args[0], stdout)
+
+	case "set":
+		if len(args) != 2 && len(args) != 3 {
+			return errUsage
+		}
+
+		var ttl time.Duration
+		if len(args) == 3 {
+			var err error
+			if ttl, err = time.ParseDuration(args[2]); err != nil {
+				return err
+			}
+		}
+
+		return store.SetWithExpiration(args[0], args[1], ttl)
+
+	case "del":
+		if len(args) == 0 {
+			return errUsage
+		}
+
+		for _, key := range args {
+			if err := store.Delete(key); err != nil {
+				return err
+			}
+		}
+
+		return nil
+
+	case "keys":
+		if len(args) > 1 {
+			return errUsage
+		}
+
+		prefix := ""
+		if len(args) == 1 {
+			prefix = args[0]
+		}
+
+		return keys(store, prefix, stdout)
+
+	case "dump":
+		if len(args) > 1 {
+			return errUsage
+		}
+
+		if len(args) == 0 {
+			return gokvstores.Backup(store, stdout)
+		}
+
+		f, err := os.Create(args[0])
+		if err != nil {
+			return err
+		}
+
+		if err := gokvstores.Backup(store, f); err != nil {
+			f.Close()
+			return err
+		}
+
+		return f.Close()
+
+	case "restore":
+		fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+		replace := fs.Bool("replace", false, "flush the store before restoring")
+		if err := fs.Parse(args); err != nil || fs.NArg() > 1 {
+			return errUsage
+		}
+
+		r := stdin
+		if fs.NArg() == 1 {
+			f, err := os.Open(fs.Arg(0))
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+
+		return gokvstores.Restore(store, r, &gokvstores.RestoreOptions{Replace: *replace})
+
+	case "copy":
+		var dst storeFlags
+
+		fs := flag.NewFlagSet("copy", flag.ContinueOnError)
+		dst.register(fs)
+		if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+			return errUsage
+		}
+
+		to, err := dst.store()
+		if err != nil {
+			return err
+		}
+		defer to.Close()
+
+		copied, err := gokvstores.CopyStore(store, to, nil)
+		fmt.Fprintf(stdout, "%d keys copied\n", copied)
+
+		return err
+	}
+
+	return errUsage
+}
+
+// get prints the value of the given key, maps and slices as JSON. Backends
+// failing to read a map or slice with Get are tried with GetMap and GetSlice.
+func get(store gokvstores.KVStore, key string, stdout io.Writer) error {
+	value, err := store.Get(key)
+	if err != nil {
+		if value, err = store.GetMap(key); err != nil {
+			value, err = store.GetSlice(key)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	switch value.(type) {
+	case nil:
+		return fmt.Errorf("key %q not found", key)
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, string(data))
+	default:
+		fmt.Fprintln(stdout, value)
+	}
+
+	return nil
+}
+
+// keys prints the sorted keys starting with prefix.
+func keys(store gokvstores.KVStore, prefix string, stdout io.Writer) error {
+	scanner, ok := store.(gokvstores.Scanner)
+	if !ok {
+		return gokvstores.ErrNotSupported
+	}
+
+	found := []string{}
+	err := scanner.Scan(func(item gokvstores.Item) error {
+		if strings.HasPrefix(item.Key, prefix) {
+			found = append(found, item.Key)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(found)
+
+	for _, key := range found {
+		fmt.Fprintln(stdout, key)
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/louiseGrandjonc/gokvstores"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := gokvstores.NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	var out bytes.Buffer
+
+	is.Nil(run(store, []string{"set", "user:1", "value"}, nil, &out))
+	is.Nil(run(store, []string{"set", "user:2", "value", "1m"}, nil, &out))
+	is.Nil(store.SetMap("map", map[string]interface{}{"language": "go"}))
+
+	is.Nil(run(store, []string{"get", "user:1"}, nil, &out))
+	is.Equal("value\n", out.String())
+
+	out.Reset()
+	is.Nil(run(store, []string{"get", "map"}, nil, &out))
+	is.Equal("{\"language\":\"go\"}\n", out.String())
+
+	out.Reset()
+	is.Nil(run(store, []string{"keys", "user:"}, nil, &out))
+	is.Equal("user:1\nuser:2\n", out.String())
+
+	var dump bytes.Buffer
+	is.Nil(run(store, []string{"dump"}, nil, &dump))
+
+	is.Nil(run(store, []string{"del", "user:1", "user:2"}, nil, &out))
+	is.NotNil(run(store, []string{"get", "user:1"}, nil, &out))
+
+	is.Nil(run(store, []string{"restore", "-replace"}, &dump, &out))
+
+	out.Reset()
+	is.Nil(run(store, []string{"keys"}, nil, &out))
+	is.Equal("map\nuser:1\nuser:2\n", out.String())
+
+	is.Equal(errUsage, run(store, []string{"unknown"}, nil, &out))
+	is.Equal(errUsage, run(store, nil, nil, &out))
+}
+
+func TestStoreFlags(t *testing.T) {
+	is := assert.New(t)
+
+	parse := func(args ...string) (gokvstores.KVStore, error) {
+		var flags storeFlags
+
+		fs := flag.NewFlagSet("gokv", flag.ContinueOnError)
+		flags.register(fs)
+		is.Nil(fs.Parse(args))
+
+		return flags.store()
+	}
+
+	store, err := parse("-url", "memory://?max_entries=10")
+	is.Nil(err)
+	is.IsType(&gokvstores.MemoryStore{}, store)
+
+	var out bytes.Buffer
+
+	is.Nil(run(store, []string{"set", "key", "value"}, nil, &out))
+	is.Nil(run(store, []string{"copy", "-url", "memory://"}, nil, &out))
+	is.Equal("1 keys copied\n", out.String())
+
+	config := filepath.Join(t.TempDir(), "store.json")
+	is.Nil(os.WriteFile(config, []byte(`{"backend": "memory", "options": {"expiration": "5m"}, "hashed_keys": {"max_length": 64}}`), 0600))
+
+	store, err = parse("-config", config)
+	is.Nil(err)
+	is.IsType(&gokvstores.HashedKeyStore{}, store)
+	is.Nil(store.Close())
+
+	_, err = parse("-url", "memory://", "-config", config)
+	is.Error(err)
+
+	_, err = parse("-url", "unknown://")
+	is.Error(err)
+}
+
+func TestExecute(t *testing.T) {
+	is := assert.New(t)
+
+	var flags storeFlags
+
+	fs := flag.NewFlagSet("gokv", flag.ContinueOnError)
+	flags.register(fs)
+	is.Nil(fs.Parse([]string{"-url", "memory://"}))
+
+	var out, errOut bytes.Buffer
+
+	is.Equal(0, execute(&flags, []string{"set", "key", "value"}, nil, &out, &errOut))
+	is.Equal("", errOut.String())
+
+	is.Equal(1, execute(&flags, []string{"unknown"}, nil, &out, &errOut))
+	is.Equal("gokv: "+errUsage.Error()+"\n", errOut.String())
+
+	errOut.Reset()
+	flags.url = "unknown://"
+	is.Equal(1, execute(&flags, []string{"get", "key"}, nil, &out, &errOut))
+	is.Contains(errOut.String(), "gokv: ")
+}
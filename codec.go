@@ -0,0 +1,81 @@
+package gokvstores
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+
+	conv "github.com/cstockton/go-conv"
+)
+
+// Codec serializes the values written to a store.
+type Codec interface {
+	// Encode returns the serialized value.
+	Encode(value interface{}) ([]byte, error)
+
+	// Decode returns the value from its serialized form.
+	Decode(data []byte) (interface{}, error)
+}
+
+// StringCodec stringifies values and reads them back as strings.
+// It is the default codec.
+type StringCodec struct{}
+
+// Encode returns the value as a string.
+func (StringCodec) Encode(value interface{}) ([]byte, error) {
+	return []byte(conv.String(value)), nil
+}
+
+// Decode returns data as a string.
+func (StringCodec) Decode(data []byte) (interface{}, error) {
+	return string(data), nil
+}
+
+// JSONCodec serializes values as JSON. Structs are read back as maps.
+type JSONCodec struct{}
+
+// Encode returns the JSON encoding of the value.
+func (JSONCodec) Encode(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Decode returns the value decoded from JSON.
+func (JSONCodec) Decode(data []byte) (interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// GobCodec serializes values with encoding/gob, preserving their Go type.
+// Custom types must be registered with gob.Register.
+type GobCodec struct{}
+
+func init() {
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
+
+// gobValue carries a value as an interface, so gob records its concrete type.
+type gobValue struct {
+	Value interface{}
+}
+
+// Encode returns the gob encoding of the value.
+func (GobCodec) Encode(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(gobValue{Value: value}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode returns the value decoded from gob.
+func (GobCodec) Decode(data []byte) (interface{}, error) {
+	var v gobValue
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v); err != nil {
+		return nil, err
+	}
+	return v.Value, nil
+}
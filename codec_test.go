@@ -0,0 +1,44 @@
+package gokvstores
+
+import (
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type codecTestItem struct {
+	Name  string
+	Count int
+}
+
+func TestCodecs(t *testing.T) {
+	is := assert.New(t)
+
+	gob.Register(codecTestItem{})
+
+	item := codecTestItem{Name: "go", Count: 2}
+
+	tests := []struct {
+		codec    Codec
+		value    interface{}
+		expected interface{}
+	}{
+		{StringCodec{}, 12, "12"},
+		{StringCodec{}, "value", "value"},
+		{JSONCodec{}, item, map[string]interface{}{"Name": "go", "Count": float64(2)}},
+		{JSONCodec{}, []interface{}{"a", true}, []interface{}{"a", true}},
+		{GobCodec{}, item, item},
+		{GobCodec{}, 12, 12},
+		{GobCodec{}, map[string]interface{}{"integer": 1}, map[string]interface{}{"integer": 1}},
+	}
+
+	for _, tt := range tests {
+		data, err := tt.codec.Encode(tt.value)
+		is.Nil(err)
+
+		v, err := tt.codec.Decode(data)
+		is.Nil(err)
+		is.Equal(tt.expected, v)
+	}
+}
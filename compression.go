@@ -0,0 +1,336 @@
+package gokvstores
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	conv "github.com/cstockton/go-conv"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression is a compression algorithm.
+type Compression byte
+
+// Compression algorithms.
+const (
+	NoCompression Compression = iota
+	GzipCompression
+	SnappyCompression
+	ZstdCompression
+)
+
+// compressionMagic prefixes the values written by a CompressedStore.
+// It is followed by the algorithm byte.
+const compressionMagic = "\x00kvz"
+
+// CompressionOptions are CompressedStore options.
+type CompressionOptions struct {
+	// Algorithm compresses new values. Defaults to GzipCompression.
+	Algorithm Compression
+
+	// MinSize is the size in bytes from which values are compressed. Defaults to 1024.
+	MinSize int
+}
+
+// CompressedStore is a KVStore decorator compressing large values before they
+// reach the wrapped store.
+//
+// Compressed values are prefixed with a small header recording the algorithm,
+// so they are read back whatever the current configuration, as strings.
+// Values written without compression are returned unchanged.
+type CompressedStore struct {
+	store     KVStore
+	algorithm Compression
+	minSize   int
+	encoder   *zstd.Encoder
+	decoder   *zstd.Decoder
+}
+
+// NewCompressedStore returns a CompressedStore wrapping the given store.
+func NewCompressedStore(store KVStore, options *CompressionOptions) (*CompressedStore, error) {
+	if options == nil {
+		options = &CompressionOptions{}
+	}
+
+	c := &CompressedStore{
+		store:     store,
+		algorithm: options.Algorithm,
+		minSize:   options.MinSize,
+	}
+
+	if c.algorithm == NoCompression {
+		c.algorithm = GzipCompression
+	}
+
+	if c.minSize <= 0 {
+		c.minSize = 1024
+	}
+
+	var err error
+
+	if c.encoder, err = zstd.NewWriter(nil); err != nil {
+		return nil, err
+	}
+
+	if c.decoder, err = zstd.NewReader(nil); err != nil {
+		c.encoder.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// compress returns the value to write for the given value.
+func (c *CompressedStore) compress(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	s := conv.String(value)
+
+	algorithm := c.algorithm
+	if len(s) < c.minSize {
+		// Small values are stored as is, unless they would be mistaken for a header.
+		if len(s) < len(compressionMagic) || s[:len(compressionMagic)] != compressionMagic {
+			return value, nil
+		}
+		algorithm = NoCompression
+	}
+
+	var compressed []byte
+
+	switch algorithm {
+	case NoCompression:
+		compressed = []byte(s)
+	case GzipCompression:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write([]byte(s)); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		compressed = buf.Bytes()
+	case SnappyCompression:
+		compressed = snappy.Encode(nil, []byte(s))
+	case ZstdCompression:
+		compressed = c.encoder.EncodeAll([]byte(s), nil)
+	default:
+		return nil, fmt.Errorf("gokvstores: unknown compression algorithm %d", algorithm)
+	}
+
+	return compressionMagic + string(algorithm) + string(compressed), nil
+}
+
+// decompress returns the original value of the given stored value.
+func (c *CompressedStore) decompress(value interface{}) (interface{}, error) {
+	var s string
+
+	switch v := value.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return value, nil
+	}
+
+	if len(s) <= len(compressionMagic) || s[:len(compressionMagic)] != compressionMagic {
+		return value, nil
+	}
+
+	algorithm := Compression(s[len(compressionMagic)])
+	compressed := []byte(s[len(compressionMagic)+1:])
+
+	switch algorithm {
+	case NoCompression:
+		return string(compressed), nil
+	case GzipCompression:
+		r, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+
+		decompressed, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return string(decompressed), nil
+	case SnappyCompression:
+		decompressed, err := snappy.Decode(nil, compressed)
+		if err != nil {
+			return nil, err
+		}
+		return string(decompressed), nil
+	case ZstdCompression:
+		decompressed, err := c.decoder.DecodeAll(compressed, nil)
+		if err != nil {
+			return nil, err
+		}
+		return string(decompressed), nil
+	}
+
+	return nil, fmt.Errorf("gokvstores: unknown compression algorithm %d", algorithm)
+}
+
+// compressSlice compresses every non-nil value of the given slice.
+func (c *CompressedStore) compressSlice(values []interface{}) ([]interface{}, error) {
+	compressed := make([]interface{}, 0, len(values))
+
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+
+		value, err := c.compress(v)
+		if err != nil {
+			return nil, err
+		}
+
+		compressed = append(compressed, value)
+	}
+
+	return compressed, nil
+}
+
+// Get returns value for the given key.
+func (c *CompressedStore) Get(key string) (interface{}, error) {
+	value, err := c.store.Get(key)
+	if err != nil || value == nil {
+		return value, err
+	}
+
+	return c.decompress(value)
+}
+
+// Set sets value for the given key.
+func (c *CompressedStore) Set(key string, value interface{}) error {
+	compressed, err := c.compress(value)
+	if err != nil {
+		return err
+	}
+
+	return c.store.Set(key, compressed)
+}
+
+// SetWithExpiration sets value for the given key with a specific expiration.
+func (c *CompressedStore) SetWithExpiration(key string, value interface{}, expiration time.Duration) error {
+	compressed, err := c.compress(value)
+	if err != nil {
+		return err
+	}
+
+	return c.store.SetWithExpiration(key, compressed, expiration)
+}
+
+// GetMap returns map for the given key.
+func (c *CompressedStore) GetMap(key string) (map[string]interface{}, error) {
+	values, err := c.store.GetMap(key)
+	if err != nil || values == nil {
+		return values, err
+	}
+
+	decompressed := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if decompressed[k], err = c.decompress(v); err != nil {
+			return nil, err
+		}
+	}
+
+	return decompressed, nil
+}
+
+// SetMap sets map for the given key.
+func (c *CompressedStore) SetMap(key string, values map[string]interface{}) error {
+	compressed := make(map[string]interface{}, len(values))
+
+	for k, v := range values {
+		value, err := c.compress(v)
+		if err != nil {
+			return err
+		}
+		compressed[k] = value
+	}
+
+	return c.store.SetMap(key, compressed)
+}
+
+// GetSlice returns slice for the given key.
+func (c *CompressedStore) GetSlice(key string) ([]interface{}, error) {
+	values, err := c.store.GetSlice(key)
+	if err != nil || values == nil {
+		return values, err
+	}
+
+	decompressed := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+
+		value, err := c.decompress(v)
+		if err != nil {
+			return nil, err
+		}
+
+		decompressed = append(decompressed, value)
+	}
+
+	return decompressed, nil
+}
+
+// SetSlice sets slice for the given key.
+func (c *CompressedStore) SetSlice(key string, values []interface{}) error {
+	compressed, err := c.compressSlice(values)
+	if err != nil {
+		return err
+	}
+
+	return c.store.SetSlice(key, compressed)
+}
+
+// AppendSlice appends values to an existing slice.
+// If key does not exist, creates slice.
+func (c *CompressedStore) AppendSlice(key string, values ...interface{}) error {
+	compressed, err := c.compressSlice(values)
+	if err != nil {
+		return err
+	}
+
+	return c.store.AppendSlice(key, compressed...)
+}
+
+// Exists checks if the given key exists.
+func (c *CompressedStore) Exists(key string) (bool, error) {
+	return c.store.Exists(key)
+}
+
+// Delete deletes the given key.
+func (c *CompressedStore) Delete(key string) error {
+	return c.store.Delete(key)
+}
+
+// Flush flushes the store.
+func (c *CompressedStore) Flush() error {
+	return c.store.Flush()
+}
+
+// Close closes the connection to the store, and stops the goroutines of the
+// zstd encoder and decoder.
+func (c *CompressedStore) Close() error {
+	c.decoder.Close()
+	c.encoder.Close()
+
+	return c.store.Close()
+}
+
+// Ping checks the store is reachable.
+func (c *CompressedStore) Ping() error {
+	return c.store.Ping()
+}
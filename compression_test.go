@@ -0,0 +1,52 @@
+package gokvstores
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	conv "github.com/cstockton/go-conv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressedStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	large := strings.Repeat(`{"language": "go"}`, 100)
+
+	for _, algorithm := range []Compression{GzipCompression, SnappyCompression, ZstdCompression} {
+		store, err := NewCompressedStore(memory, &CompressionOptions{Algorithm: algorithm, MinSize: 64})
+		is.Nil(err)
+
+		testStore(t, store)
+
+		is.Nil(store.Set("key", large))
+
+		raw, err := memory.Get("key")
+		is.Nil(err)
+		is.True(strings.HasPrefix(conv.String(raw), compressionMagic))
+
+		v, err := store.Get("key")
+		is.Nil(err)
+		is.Equal(large, v)
+
+		// Small values are left untouched
+
+		is.Nil(store.Set("key", 42))
+
+		v, err = store.Get("key")
+		is.Nil(err)
+		is.Equal(42, v)
+
+		// Values looking like a header survive a round-trip
+
+		is.Nil(store.Set("key", compressionMagic+"x"))
+
+		v, err = store.Get("key")
+		is.Nil(err)
+		is.Equal(compressionMagic+"x", v)
+	}
+}
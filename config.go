@@ -0,0 +1,473 @@
+package gokvstores
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Duration is a time.Duration written in configuration files as accepted by
+// time.ParseDuration, such as "5m".
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+
+	return nil
+}
+
+// Config describes a store and its decorators, as read from a JSON or YAML
+// file by NewStoreFromConfig, for instance:
+//
+//	backend: redis
+//	addr: cache:6379
+//	options:
+//	  expiration: 1h
+//	  pool_size: 20
+//	compression:
+//	  algorithm: zstd
+//	metrics:
+//	  namespace: myapp
+type Config struct {
+	// URL is the backend, as accepted by NewStoreFromURL.
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+
+	// Backend, Addr, Username, Password and DB, when set, override the parts
+	// of URL. The backend defaults to memory.
+	Backend  string `json:"backend,omitempty" yaml:"backend,omitempty"`
+	Addr     string `json:"addr,omitempty" yaml:"addr,omitempty"`
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+	DB       int    `json:"db,omitempty" yaml:"db,omitempty"`
+
+	// Options are the options of the backend, as in URL queries, overriding
+	// the URL ones (e.g. {"expiration": "5m", "pool_size": 20}).
+	Options map[string]interface{} `json:"options,omitempty" yaml:"options,omitempty"`
+
+	// The decorators below wrap the backend in the order they are declared,
+	// from the innermost to the outermost. Nil ones are not used. A Config
+	// describes a single backend: the package has no tiered store, so tiered
+	// setups can't be declared and are built in code.
+	RateLimit     *RateLimitConfig     `json:"rate_limit,omitempty" yaml:"rate_limit,omitempty"`
+	Retry         *RetryConfig         `json:"retry,omitempty" yaml:"retry,omitempty"`
+	Breaker       *BreakerConfig       `json:"breaker,omitempty" yaml:"breaker,omitempty"`
+	WriteBehind   *WriteBehindConfig   `json:"write_behind,omitempty" yaml:"write_behind,omitempty"`
+	Compression   *CompressionConfig   `json:"compression,omitempty" yaml:"compression,omitempty"`
+	Tombstones    *TombstoneConfig     `json:"tombstones,omitempty" yaml:"tombstones,omitempty"`
+	Jitter        *JitterConfig        `json:"jitter,omitempty" yaml:"jitter,omitempty"`
+	SizeGuard     *SizeGuardConfig     `json:"size_guard,omitempty" yaml:"size_guard,omitempty"`
+	HashedKeys    *HashedKeyConfig     `json:"hashed_keys,omitempty" yaml:"hashed_keys,omitempty"`
+	KeyValidation *KeyValidationConfig `json:"key_validation,omitempty" yaml:"key_validation,omitempty"`
+	Singleflight  bool                 `json:"singleflight,omitempty" yaml:"singleflight,omitempty"`
+	Tracing       *TracingConfig       `json:"tracing,omitempty" yaml:"tracing,omitempty"`
+	Logging       *LoggingConfig       `json:"logging,omitempty" yaml:"logging,omitempty"`
+	Metrics       *MetricsConfig       `json:"metrics,omitempty" yaml:"metrics,omitempty"`
+}
+
+// RateLimitConfig configures a RateLimitStore limiting all operations.
+type RateLimitConfig struct {
+	Rate     float64 `json:"rate" yaml:"rate"`
+	Burst    int     `json:"burst,omitempty" yaml:"burst,omitempty"`
+	FailFast bool    `json:"fail_fast,omitempty" yaml:"fail_fast,omitempty"`
+}
+
+// RetryConfig configures a RetryStore, with the policy of all operations.
+type RetryConfig struct {
+	MaxAttempts    int      `json:"max_attempts" yaml:"max_attempts"`
+	InitialBackoff Duration `json:"initial_backoff,omitempty" yaml:"initial_backoff,omitempty"`
+	MaxBackoff     Duration `json:"max_backoff,omitempty" yaml:"max_backoff,omitempty"`
+	Multiplier     float64  `json:"multiplier,omitempty" yaml:"multiplier,omitempty"`
+	Jitter         float64  `json:"jitter,omitempty" yaml:"jitter,omitempty"`
+}
+
+// BreakerConfig configures a BreakerStore.
+type BreakerConfig struct {
+	Threshold int      `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+	Timeout   Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// Fallback is the store serving operations while the circuit is open.
+	Fallback *Config `json:"fallback,omitempty" yaml:"fallback,omitempty"`
+}
+
+// WriteBehindConfig configures a WriteBehindStore.
+type WriteBehindConfig struct {
+	QueueSize     int      `json:"queue_size,omitempty" yaml:"queue_size,omitempty"`
+	BatchSize     int      `json:"batch_size,omitempty" yaml:"batch_size,omitempty"`
+	FlushInterval Duration `json:"flush_interval,omitempty" yaml:"flush_interval,omitempty"`
+}
+
+// CompressionConfig configures a CompressedStore.
+type CompressionConfig struct {
+	// Algorithm is gzip, snappy or zstd. Defaults to gzip.
+	Algorithm string `json:"algorithm,omitempty" yaml:"algorithm,omitempty"`
+	MinSize   int    `json:"min_size,omitempty" yaml:"min_size,omitempty"`
+}
+
+// TombstoneConfig configures a TombstoneStore.
+type TombstoneConfig struct {
+	TTL Duration `json:"ttl" yaml:"ttl"`
+}
+
+// JitterConfig configures a JitterStore.
+type JitterConfig struct {
+	Expiration Duration `json:"expiration,omitempty" yaml:"expiration,omitempty"`
+	Percent    float64  `json:"percent" yaml:"percent"`
+}
+
+// SizeGuardConfig configures a SizeGuardStore.
+type SizeGuardConfig struct {
+	MaxSize int `json:"max_size" yaml:"max_size"`
+
+	// Action is reject, truncate or allow. Defaults to reject.
+	Action string `json:"action,omitempty" yaml:"action,omitempty"`
+}
+
+// HashedKeyConfig configures a HashedKeyStore.
+type HashedKeyConfig struct {
+	MaxLength    int `json:"max_length" yaml:"max_length"`
+	PrefixLength int `json:"prefix_length,omitempty" yaml:"prefix_length,omitempty"`
+}
+
+// KeyValidationConfig configures a KeyValidationStore.
+type KeyValidationConfig struct {
+	MaxLength int    `json:"max_length,omitempty" yaml:"max_length,omitempty"`
+	Charset   string `json:"charset,omitempty" yaml:"charset,omitempty"`
+
+	// Forbidden are regular expressions keys must not match.
+	Forbidden []string `json:"forbidden,omitempty" yaml:"forbidden,omitempty"`
+}
+
+// TracingConfig configures a TracingStore using the global tracer provider.
+type TracingConfig struct {
+	Backend  string `json:"backend,omitempty" yaml:"backend,omitempty"`
+	HashKeys bool   `json:"hash_keys,omitempty" yaml:"hash_keys,omitempty"`
+}
+
+// LoggingConfig configures a LoggingStore using the standard logger.
+type LoggingConfig struct {
+	// Level is debug, info or error. Defaults to debug.
+	Level string `json:"level,omitempty" yaml:"level,omitempty"`
+}
+
+// MetricsConfig configures a MetricsStore.
+type MetricsConfig struct {
+	// Registerer, set in code, registers the metrics until the store is
+	// closed. Defaults to prometheus.DefaultRegisterer.
+	Registerer prometheus.Registerer `json:"-" yaml:"-"`
+
+	Namespace   string            `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Subsystem   string            `json:"subsystem,omitempty" yaml:"subsystem,omitempty"`
+	ConstLabels map[string]string `json:"const_labels,omitempty" yaml:"const_labels,omitempty"`
+	Buckets     []float64         `json:"buckets,omitempty" yaml:"buckets,omitempty"`
+}
+
+// compressions are the compression algorithms selected by name in a Config.
+var compressions = map[string]Compression{
+	"gzip":   GzipCompression,
+	"snappy": SnappyCompression,
+	"zstd":   ZstdCompression,
+}
+
+// oversizeActions are the oversize actions selected by name in a Config.
+var oversizeActions = map[string]OversizeAction{
+	"reject":   RejectOversize,
+	"truncate": TruncateOversize,
+	"allow":    AllowOversize,
+}
+
+// logLevels are the log levels selected by name in a Config.
+var logLevels = map[string]LogLevel{
+	LogLevelDebug.String(): LogLevelDebug,
+	LogLevelInfo.String():  LogLevelInfo,
+	LogLevelError.String(): LogLevelError,
+}
+
+// decorator wraps a store.
+type decorator func(store KVStore) (KVStore, error)
+
+// NewStoreFromConfig returns the store described by the given configuration,
+// wrapped by its decorators. The configuration is checked before connecting
+// to the backend.
+func NewStoreFromConfig(config *Config) (KVStore, error) {
+	decorators, err := config.decorators()
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := config.url()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := factory(u.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := f(u)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, decorate := range decorators {
+		decorated, err := decorate(store)
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
+		store = decorated
+	}
+
+	return store, nil
+}
+
+// url returns the URL of the backend.
+func (c *Config) url() (*url.URL, error) {
+	u := &url.URL{Scheme: "memory"}
+	if c.URL != "" {
+		var err error
+		if u, err = url.Parse(c.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.Backend != "" {
+		u.Scheme = c.Backend
+	}
+
+	if c.Addr != "" {
+		u.Host = c.Addr
+	}
+
+	if c.Username != "" || c.Password != "" {
+		username := u.User.Username()
+		password, _ := u.User.Password()
+
+		if c.Username != "" {
+			username = c.Username
+		}
+		if c.Password != "" {
+			password = c.Password
+		}
+
+		u.User = url.UserPassword(username, password)
+	}
+
+	if c.DB != 0 {
+		u.Path = "/" + strconv.Itoa(c.DB)
+	}
+
+	if len(c.Options) > 0 {
+		query := u.Query()
+		for name, value := range c.Options {
+			query.Set(name, optionString(value))
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	return u, nil
+}
+
+// optionString returns a backend option value as written in URL queries.
+// JSON numbers are float64 values, which must not be written with exponents.
+func optionString(value interface{}) string {
+	if f, ok := value.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// decorators returns the decorators of the configuration, from the innermost
+// to the outermost.
+func (c *Config) decorators() ([]decorator, error) {
+	var decorators []decorator
+
+	if c.RateLimit != nil {
+		options := &RateLimitOptions{
+			Limit:    &RateLimit{Rate: c.RateLimit.Rate, Burst: c.RateLimit.Burst},
+			FailFast: c.RateLimit.FailFast,
+		}
+		decorators = append(decorators, func(store KVStore) (KVStore, error) {
+			return NewRateLimitStore(store, options), nil
+		})
+	}
+
+	if c.Retry != nil {
+		options := &RetryOptions{Policy: &RetryPolicy{
+			MaxAttempts:    c.Retry.MaxAttempts,
+			InitialBackoff: time.Duration(c.Retry.InitialBackoff),
+			MaxBackoff:     time.Duration(c.Retry.MaxBackoff),
+			Multiplier:     c.Retry.Multiplier,
+			Jitter:         c.Retry.Jitter,
+		}}
+		decorators = append(decorators, func(store KVStore) (KVStore, error) {
+			return NewRetryStore(store, options), nil
+		})
+	}
+
+	if c.Breaker != nil {
+		config := *c.Breaker
+		if config.Fallback != nil {
+			if _, err := config.Fallback.decorators(); err != nil {
+				return nil, err
+			}
+		}
+
+		decorators = append(decorators, func(store KVStore) (KVStore, error) {
+			options := &BreakerOptions{
+				Threshold: config.Threshold,
+				Timeout:   time.Duration(config.Timeout),
+			}
+
+			if config.Fallback != nil {
+				fallback, err := NewStoreFromConfig(config.Fallback)
+				if err != nil {
+					return nil, err
+				}
+				options.Fallback = fallback
+			}
+
+			return NewBreakerStore(store, options), nil
+		})
+	}
+
+	if c.WriteBehind != nil {
+		options := &WriteBehindOptions{
+			QueueSize:     c.WriteBehind.QueueSize,
+			BatchSize:     c.WriteBehind.BatchSize,
+			FlushInterval: time.Duration(c.WriteBehind.FlushInterval),
+		}
+		decorators = append(decorators, func(store KVStore) (KVStore, error) {
+			return NewWriteBehindStore(store, options), nil
+		})
+	}
+
+	if c.Compression != nil {
+		options := &CompressionOptions{MinSize: c.Compression.MinSize}
+		if c.Compression.Algorithm != "" {
+			algorithm, ok := compressions[c.Compression.Algorithm]
+			if !ok {
+				return nil, fmt.Errorf("gokvstores: unknown compression algorithm %q", c.Compression.Algorithm)
+			}
+			options.Algorithm = algorithm
+		}
+		decorators = append(decorators, func(store KVStore) (KVStore, error) {
+			return NewCompressedStore(store, options)
+		})
+	}
+
+	if c.Tombstones != nil {
+		ttl := time.Duration(c.Tombstones.TTL)
+		decorators = append(decorators, func(store KVStore) (KVStore, error) {
+			return NewTombstoneStore(store, ttl), nil
+		})
+	}
+
+	if c.Jitter != nil {
+		expiration, percent := time.Duration(c.Jitter.Expiration), c.Jitter.Percent
+		decorators = append(decorators, func(store KVStore) (KVStore, error) {
+			return NewJitterStore(store, expiration, percent), nil
+		})
+	}
+
+	if c.SizeGuard != nil {
+		options := &SizeGuardOptions{MaxSize: c.SizeGuard.MaxSize}
+		if c.SizeGuard.Action != "" {
+			action, ok := oversizeActions[c.SizeGuard.Action]
+			if !ok {
+				return nil, fmt.Errorf("gokvstores: unknown oversize action %q", c.SizeGuard.Action)
+			}
+			options.Action = action
+		}
+		decorators = append(decorators, func(store KVStore) (KVStore, error) {
+			return NewSizeGuardStore(store, options), nil
+		})
+	}
+
+	if c.HashedKeys != nil {
+		maxLength, prefixLength := c.HashedKeys.MaxLength, c.HashedKeys.PrefixLength
+		decorators = append(decorators, func(store KVStore) (KVStore, error) {
+			return NewHashedKeyStore(store, maxLength, prefixLength), nil
+		})
+	}
+
+	if c.KeyValidation != nil {
+		rules := &KeyRules{MaxLength: c.KeyValidation.MaxLength, Charset: c.KeyValidation.Charset}
+		for _, pattern := range c.KeyValidation.Forbidden {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, err
+			}
+			rules.Forbidden = append(rules.Forbidden, re)
+		}
+		decorators = append(decorators, func(store KVStore) (KVStore, error) {
+			return NewKeyValidationStore(store, rules), nil
+		})
+	}
+
+	if c.Singleflight {
+		decorators = append(decorators, func(store KVStore) (KVStore, error) {
+			return NewSingleflightStore(store), nil
+		})
+	}
+
+	if c.Tracing != nil {
+		options := &TracingOptions{Backend: c.Tracing.Backend, HashKeys: c.Tracing.HashKeys}
+		decorators = append(decorators, func(store KVStore) (KVStore, error) {
+			return NewTracingStore(store, options), nil
+		})
+	}
+
+	if c.Logging != nil {
+		options := &LoggingOptions{}
+		if c.Logging.Level != "" {
+			level, ok := logLevels[c.Logging.Level]
+			if !ok {
+				return nil, fmt.Errorf("gokvstores: unknown log level %q", c.Logging.Level)
+			}
+			options.Level = level
+		}
+		decorators = append(decorators, func(store KVStore) (KVStore, error) {
+			return NewLoggingStore(store, options), nil
+		})
+	}
+
+	if c.Metrics != nil {
+		options := &MetricsOptions{
+			Namespace:   c.Metrics.Namespace,
+			Subsystem:   c.Metrics.Subsystem,
+			ConstLabels: prometheus.Labels(c.Metrics.ConstLabels),
+			Buckets:     c.Metrics.Buckets,
+		}
+		registerer := c.Metrics.Registerer
+		if registerer == nil {
+			registerer = prometheus.DefaultRegisterer
+		}
+		decorators = append(decorators, func(store KVStore) (KVStore, error) {
+			metrics := NewMetricsStore(store, options)
+			if err := registerer.Register(metrics); err != nil {
+				return nil, err
+			}
+			metrics.registerer = registerer
+			return metrics, nil
+		})
+	}
+
+	return decorators, nil
+}
@@ -0,0 +1,116 @@
+package gokvstores
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStoreFromConfig(t *testing.T) {
+	is := assert.New(t)
+
+	var config Config
+	is.Nil(json.Unmarshal([]byte(`{
+		"backend": "memory",
+		"options": {"expiration": "5m", "max_entries": 1000000},
+		"retry": {"max_attempts": 3, "initial_backoff": "10ms"},
+		"compression": {"algorithm": "zstd", "min_size": 64},
+		"size_guard": {"max_size": 1024},
+		"metrics": {"namespace": "config_test"}
+	}`), &config))
+
+	registry := prometheus.NewPedanticRegistry()
+	config.Metrics.Registerer = registry
+
+	is.Equal(Duration(10*time.Millisecond), config.Retry.InitialBackoff)
+
+	store, err := NewStoreFromConfig(&config)
+	is.Nil(err)
+
+	testStore(t, store)
+
+	metrics, ok := store.(*MetricsStore)
+	is.True(ok)
+
+	guard, ok := metrics.store.(*SizeGuardStore)
+	is.True(ok)
+
+	compressed, ok := guard.KVStore.(*CompressedStore)
+	is.True(ok)
+	is.Equal(ZstdCompression, compressed.algorithm)
+
+	retry, ok := compressed.store.(*RetryStore)
+	is.True(ok)
+
+	memory, ok := retry.store.(*MemoryStore)
+	is.True(ok)
+	is.Equal(5*time.Minute, memory.expiration)
+	is.NotNil(memory.lru)
+
+	value := strings.Repeat("x", 512)
+	is.Nil(store.Set("key", value))
+
+	v, err := store.Get("key")
+	is.Nil(err)
+	is.Equal(value, v)
+
+	is.Error(store.Set("key", strings.Repeat("x", 2048)))
+
+	// The metrics are registered until the store is closed.
+	_, err = NewStoreFromConfig(&config)
+	is.IsType(prometheus.AlreadyRegisteredError{}, err)
+
+	is.Nil(store.Close())
+
+	store, err = NewStoreFromConfig(&config)
+	is.Nil(err)
+	is.Nil(store.Close())
+
+	// Configurations read from files register with the default registerer.
+	var file Config
+	is.Nil(json.Unmarshal([]byte(`{"metrics": {"namespace": "config_file_test"}}`), &file))
+
+	store, err = NewStoreFromConfig(&file)
+	is.Nil(err)
+
+	_, err = NewStoreFromConfig(&file)
+	is.IsType(prometheus.AlreadyRegisteredError{}, err)
+
+	is.Nil(store.Close())
+
+	store, err = NewStoreFromConfig(&file)
+	is.Nil(err)
+	is.Nil(store.Close())
+
+	for expected, config := range map[string]*Config{
+		`gokvstores: unknown compression algorithm "lz4"`:        {Compression: &CompressionConfig{Algorithm: "lz4"}},
+		`gokvstores: unknown oversize action "drop"`:             {SizeGuard: &SizeGuardConfig{Action: "drop"}},
+		`gokvstores: unknown log level "warning"`:                {Logging: &LoggingConfig{Level: "warning"}},
+		`gokvstores: unknown backend "etcd" (forgotten import?)`: {Backend: "etcd"},
+		`gokvstores: unknown store URL options size`:             {Options: map[string]interface{}{"size": 1}},
+		`gokvstores: unknown log level "trace"`: {
+			Breaker: &BreakerConfig{Fallback: &Config{Logging: &LoggingConfig{Level: "trace"}}},
+		},
+	} {
+		_, err := NewStoreFromConfig(config)
+		is.EqualError(err, expected)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	is := assert.New(t)
+
+	data, err := json.Marshal(JitterConfig{Expiration: Duration(90 * time.Second), Percent: 10})
+	is.Nil(err)
+	is.Equal(`{"expiration":"1m30s","percent":10}`, string(data))
+
+	var config JitterConfig
+	is.Nil(json.Unmarshal(data, &config))
+	is.Equal(Duration(90*time.Second), config.Expiration)
+
+	is.Error(json.Unmarshal([]byte(`{"expiration":"soon"}`), &config))
+}
@@ -0,0 +1,33 @@
+package gokvstores
+
+// CopyOptions are CopyStore options.
+type CopyOptions struct {
+	// Concurrency is the number of keys written in parallel. Defaults to 8.
+	Concurrency int
+
+	// OnProgress is called after each key is copied, with the number of keys
+	// copied so far. Calls are serialized.
+	OnProgress func(copied int)
+}
+
+// CopyStore copies all the items of src, which must implement Scanner, to dst.
+// Keys keep their type and remaining time to live. It stops at the first
+// failure and returns the number of keys copied.
+func CopyStore(src, dst KVStore, options *CopyOptions) (int, error) {
+	scanner, ok := src.(Scanner)
+	if !ok {
+		return 0, ErrNotSupported
+	}
+
+	if options == nil {
+		options = &CopyOptions{}
+	}
+
+	return parallel(options.Concurrency, options.OnProgress, func(send func(task task) error) error {
+		return scanner.Scan(func(item Item) error {
+			return send(func() (bool, error) {
+				return true, setItem(dst, item)
+			})
+		})
+	})
+}
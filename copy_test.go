@@ -0,0 +1,138 @@
+package gokvstores
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingSetStore is a KVStore whose Set always fails with err.
+type failingSetStore struct {
+	KVStore
+	err error
+}
+
+func (s *failingSetStore) SetWithExpiration(key string, value interface{}, expiration time.Duration) error {
+	return s.err
+}
+
+func TestCopyStore(t *testing.T) {
+	is := assert.New(t)
+
+	src, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	is.Nil(src.Set("key", "value"))
+	is.Nil(src.SetWithExpiration("short", "value", time.Minute))
+	is.Nil(src.SetMap("map", map[string]interface{}{"language": "go"}))
+	is.Nil(src.SetSlice("slice", []interface{}{"a"}))
+
+	dst, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	progress := []int{}
+	copied, err := CopyStore(src, dst, &CopyOptions{
+		Concurrency: 2,
+		OnProgress:  func(copied int) { progress = append(progress, copied) },
+	})
+	is.Nil(err)
+	is.Equal(4, copied)
+	is.Equal([]int{1, 2, 3, 4}, progress)
+
+	m, err := dst.GetMap("map")
+	is.Nil(err)
+	is.Equal(map[string]interface{}{"language": "go"}, m)
+
+	snapshot, err := dst.(Snapshotter).Snapshot()
+	is.Nil(err)
+	is.Equal([]string{"key", "map", "short", "slice"}, snapshot.Keys())
+	is.WithinDuration(time.Now().Add(time.Minute), snapshot.Expiration("short"), time.Second)
+
+	// Failures
+
+	failure := errors.New("failure")
+	_, err = CopyStore(src, &failingSetStore{KVStore: dst, err: failure}, nil)
+	is.Equal(failure, err)
+
+	_, err = CopyStore(DummyStore{}, dst, nil)
+	is.Equal(ErrNotSupported, err)
+}
+
+func TestSetItemClock(t *testing.T) {
+	is := assert.New(t)
+
+	// The destination lives an hour in the past.
+	clock := NewManualClock(time.Now().Add(-time.Hour))
+
+	dst, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{Clock: clock})
+	is.Nil(err)
+
+	expiration := clock.Now().Add(time.Minute)
+	is.Nil(setItem(dst, Item{Key: "key", Value: "value", Expiration: expiration}))
+	is.Nil(setItem(dst, Item{Key: "expired", Value: "value", Expiration: clock.Now()}))
+
+	items := dst.(*MemoryStore).items()
+	is.Len(items, 1)
+	is.Equal(expiration.UnixNano(), items["key"].expiration)
+}
+
+func TestSetItemReplace(t *testing.T) {
+	is := assert.New(t)
+
+	clock := NewManualClock(time.Now())
+
+	dst, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{Clock: clock})
+	is.Nil(err)
+
+	is.Nil(dst.SetMap("map", map[string]interface{}{"old": "value"}))
+	is.Nil(dst.SetSlice("slice", []interface{}{"old"}))
+
+	expiration := clock.Now().Add(time.Minute)
+	is.Nil(setItem(dst, Item{Key: "map", Value: map[string]interface{}{"language": "go"}, Expiration: expiration}))
+	is.Nil(setItem(dst, Item{Key: "slice", Value: []interface{}{"new"}, Expiration: expiration}))
+
+	m, err := dst.GetMap("map")
+	is.Nil(err)
+	is.Equal(map[string]interface{}{"language": "go"}, m)
+
+	s, err := dst.GetSlice("slice")
+	is.Nil(err)
+	is.Equal([]interface{}{"new"}, s)
+
+	items := dst.(*MemoryStore).items()
+	is.Equal(expiration.UnixNano(), items["map"].expiration)
+	is.Equal(expiration.UnixNano(), items["slice"].expiration)
+}
+
+func TestRedisStoreSetItem(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Minute)
+	is.Nil(err)
+
+	rs := store.(*RedisStore)
+
+	is.Nil(store.SetMap("map", map[string]interface{}{"old": "value"}))
+	is.Nil(setItem(store, Item{Key: "map", Value: map[string]interface{}{"language": "go"}, Expiration: time.Now().Add(time.Hour)}))
+
+	m, err := store.GetMap("map")
+	is.Nil(err)
+	is.Equal(map[string]interface{}{"language": "go"}, m)
+
+	ttl, err := rs.client.TTL(rs.ctx, "map").Result()
+	is.Nil(err)
+	is.True(ttl > time.Minute && ttl <= time.Hour)
+
+	is.Nil(setItem(store, Item{Key: "map", Value: "value"}))
+
+	ttl, err = rs.client.TTL(rs.ctx, "map").Result()
+	is.Nil(err)
+	is.Equal(time.Duration(-1), ttl)
+
+	is.Nil(store.Delete("map"))
+	is.Nil(store.Close())
+}
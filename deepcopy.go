@@ -0,0 +1,92 @@
+package gokvstores
+
+import "reflect"
+
+// deepCopyValue returns a deep copy of the value, following its maps,
+// slices, arrays, pointers, interfaces and exported struct fields. Unexported
+// fields, channels and functions are shared. The value must not be cyclic.
+func deepCopyValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(value)).Interface()
+}
+
+// deepCopy returns a deep copy of v.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem()))
+		return c
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return c
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := c.Field(i); field.CanSet() {
+				field.Set(deepCopy(v.Field(i)))
+			}
+		}
+		return c
+	}
+
+	return v
+}
+
+// readCopy returns the value to hand to a reader: a copy of it with
+// CopyOnRead, the value itself otherwise.
+func (c *MemoryStore) readCopy(value interface{}) (interface{}, error) {
+	if !c.copyOnRead || value == nil {
+		return value, nil
+	}
+
+	if c.copyCodec == nil {
+		return deepCopyValue(value), nil
+	}
+
+	data, err := c.copyCodec.Encode(value)
+	if err != nil {
+		return nil, err
+	}
+	return c.copyCodec.Decode(data)
+}
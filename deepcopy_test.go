@@ -0,0 +1,80 @@
+package gokvstores
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type deepCopyTestItem struct {
+	Name   string
+	Tags   []string
+	Parent *deepCopyTestItem
+}
+
+func TestMemoryStoreCopyOnRead(t *testing.T) {
+	is := assert.New(t)
+
+	for _, codec := range []Codec{nil, GobCodec{}} {
+		store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{
+			CopyOnRead: true,
+			CopyCodec:  codec,
+		})
+		is.Nil(err)
+
+		is.Nil(store.SetMap("map", map[string]interface{}{"field": "value"}))
+		is.Nil(store.SetSlice("slice", []interface{}{"a", "b"}))
+
+		values, err := store.GetMap("map")
+		is.Nil(err)
+		values["field"] = "changed"
+
+		items, err := store.GetSlice("slice")
+		is.Nil(err)
+		items[0] = "changed"
+
+		values, err = store.GetMap("map")
+		is.Nil(err)
+		is.Equal(map[string]interface{}{"field": "value"}, values)
+
+		items, err = store.GetSlice("slice")
+		is.Nil(err)
+		is.Equal([]interface{}{"a", "b"}, items)
+
+		is.Nil(store.(Scanner).Scan(func(item Item) error {
+			if item.Key == "map" {
+				item.Value.(map[string]interface{})["field"] = "changed"
+			}
+			return nil
+		}))
+
+		values, err = store.GetMap("map")
+		is.Nil(err)
+		is.Equal("value", values["field"])
+	}
+}
+
+func TestDeepCopyValue(t *testing.T) {
+	is := assert.New(t)
+
+	item := &deepCopyTestItem{
+		Name:   "child",
+		Tags:   []string{"a"},
+		Parent: &deepCopyTestItem{Name: "parent"},
+	}
+
+	copied := deepCopyValue(item).(*deepCopyTestItem)
+	is.Equal(item, copied)
+
+	copied.Tags[0] = "b"
+	copied.Parent.Name = "changed"
+	is.Equal("a", item.Tags[0])
+	is.Equal("parent", item.Parent.Name)
+
+	nested := map[string]interface{}{"list": []interface{}{map[string]interface{}{"k": 1}}}
+	copiedNested := deepCopyValue(nested).(map[string]interface{})
+	copiedNested["list"].([]interface{})[0].(map[string]interface{})["k"] = 2
+	is.Equal(1, nested["list"].([]interface{})[0].(map[string]interface{})["k"])
+
+	is.Nil(deepCopyValue(nil))
+}
@@ -0,0 +1,200 @@
+package gokvstores
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DelayedSetter is implemented by stores able to schedule writes.
+type DelayedSetter interface {
+	// SetDelayed sets the value of the given key once visibleAt is reached,
+	// until then the key keeps its current value. Scheduling a key again
+	// replaces its pending value.
+	SetDelayed(key string, value interface{}, visibleAt time.Time) error
+}
+
+// promoteInterval is how often scheduled values are checked for visibility.
+const promoteInterval = 100 * time.Millisecond
+
+// periodic calls a function periodically, from its start until closed.
+type periodic struct {
+	once      sync.Once
+	closeOnce sync.Once
+	stop      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// start calls fn every interval, unless already started or closed.
+func (p *periodic) start(interval time.Duration, fn func()) {
+	p.once.Do(func() {
+		p.stop = make(chan struct{})
+
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-p.stop:
+					return
+				case <-ticker.C:
+					fn()
+				}
+			}
+		}()
+	})
+}
+
+// close stops the calls.
+func (p *periodic) close() {
+	// Not to start once closed.
+	p.once.Do(func() {})
+
+	p.closeOnce.Do(func() {
+		if p.stop != nil {
+			close(p.stop)
+			p.wg.Wait()
+		}
+	})
+}
+
+// ----------------------------------------------------------------------------
+// Memory
+// ----------------------------------------------------------------------------
+
+// delayedItem is a value scheduled in a MemoryStore.
+type delayedItem struct {
+	value     interface{}
+	visibleAt time.Time
+}
+
+// delayedItems are the values scheduled in a MemoryStore.
+type delayedItems struct {
+	mu    sync.Mutex
+	items map[string]delayedItem
+}
+
+// SetDelayed sets the value of the given key once visibleAt is reached,
+// within 100ms. Scheduled values are lost when the store is closed.
+func (c *MemoryStore) SetDelayed(key string, value interface{}, visibleAt time.Time) error {
+	if !visibleAt.After(c.clock.Now()) {
+		return c.Set(key, value)
+	}
+
+	c.delayed.mu.Lock()
+	if c.delayed.items == nil {
+		c.delayed.items = map[string]delayedItem{}
+	}
+	c.delayed.items[key] = delayedItem{value: value, visibleAt: visibleAt}
+	c.delayed.mu.Unlock()
+
+	c.promoter.start(promoteInterval, c.promote)
+	return nil
+}
+
+// promote sets the scheduled values which became visible.
+func (c *MemoryStore) promote() {
+	now := c.clock.Now()
+	due := map[string]interface{}{}
+
+	c.delayed.mu.Lock()
+	for key, item := range c.delayed.items {
+		if !item.visibleAt.After(now) {
+			due[key] = item.value
+			delete(c.delayed.items, key)
+		}
+	}
+	c.delayed.mu.Unlock()
+
+	for key, value := range due {
+		c.Set(key, value)
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Redis
+// ----------------------------------------------------------------------------
+
+// Keys holding the values scheduled in Redis: an index, sorted by
+// visibility time, and the pending values.
+const (
+	delayedIndexKey    = "delayed:index"
+	delayedValuePrefix = "delayed:value:"
+)
+
+// promoteBatchSize is the number of values promoted by a run of promoteScript.
+const promoteBatchSize = 100
+
+// promoteScript sets the pending values visible at ARGV[1], with the
+// expiration ARGV[3] in milliseconds, and returns how many were due.
+var promoteScript = NewScript(`
+	local due = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[4])
+	for _, key in ipairs(due) do
+		local value = redis.call("GET", ARGV[2] .. key)
+		if value then
+			if tonumber(ARGV[3]) > 0 then
+				redis.call("SET", key, value, "PX", ARGV[3])
+			else
+				redis.call("SET", key, value)
+			end
+			redis.call("DEL", ARGV[2] .. key)
+		end
+		redis.call("ZREM", KEYS[1], key)
+	end
+	return #due
+`)
+
+// SetDelayed schedules the value of the given key, stored in the
+// "delayed:value:" keys and indexed in the "delayed:index" sorted set. Every
+// store setting delayed values checks the index every 100ms, and promotes
+// the visible ones. Clusters are not supported.
+func (r *RedisStore) SetDelayed(key string, value interface{}, visibleAt time.Time) (err error) {
+	if _, ok := r.client.(*redis.ClusterClient); ok {
+		return ErrNotSupported
+	}
+
+	if !visibleAt.After(time.Now()) {
+		return r.Set(key, value)
+	}
+
+	defer func() { r.stats.write(err) }()
+
+	encoded, err := r.encode(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TxPipelined(r.ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(r.ctx, delayedValuePrefix+key, encoded, 0)
+		pipe.ZAdd(r.ctx, delayedIndexKey, redis.Z{Score: float64(visibleAt.UnixMilli()), Member: key})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The promoter is shared by the WithContext copies, and outlives the
+	// context of this call.
+	r.promoter.start(promoteInterval, r.WithContext(context.Background()).promote)
+	return nil
+}
+
+// promote sets the scheduled values which became visible. Failures are
+// retried by the next run.
+func (r *RedisStore) promote() {
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+
+	for {
+		due, err := r.RunScript(promoteScript, []string{delayedIndexKey}, now, delayedValuePrefix, r.expiration.Milliseconds(), promoteBatchSize)
+		if n, _ := due.(int64); err != nil || n < promoteBatchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testDelayedSetter(t *testing.T, store KVStore) {
+	is := assert.New(t)
+
+	ds := store.(DelayedSetter)
+
+	is.Nil(store.Set("article", "draft"))
+	is.Nil(store.Delete("now"))
+
+	is.Nil(ds.SetDelayed("article", "published", time.Now().Add(200*time.Millisecond)))
+	is.Nil(ds.SetDelayed("now", "visible", time.Now().Add(-time.Second)))
+
+	value, err := store.Get("now")
+	is.Nil(err)
+	is.Equal("visible", value)
+
+	value, err = store.Get("article")
+	is.Nil(err)
+	is.Equal("draft", value)
+
+	time.Sleep(500 * time.Millisecond)
+
+	value, err = store.Get("article")
+	is.Nil(err)
+	is.Equal("published", value)
+}
+
+func TestMemoryStoreDelayed(t *testing.T) {
+	store, err := NewMemoryStore(0, time.Second*10)
+	assert.Nil(t, err)
+
+	testDelayedSetter(t, store)
+
+	assert.Nil(t, store.Close())
+	assert.Nil(t, store.Close())
+}
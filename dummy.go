@@ -1,5 +1,7 @@
 package gokvstores
 
+import "time"
+
 // DummyStore is a noop store (caching disabled).
 type DummyStore struct{}
 
@@ -13,6 +15,11 @@ func (s DummyStore) Set(key string, value interface{}) error {
 	return nil
 }
 
+// SetWithExpiration sets value for the given key with a specific expiration.
+func (s DummyStore) SetWithExpiration(key string, value interface{}, expiration time.Duration) error {
+	return nil
+}
+
 // GetMap returns map for the given key.
 func (s DummyStore) GetMap(key string) (map[string]interface{}, error) {
 	return nil, nil
@@ -58,3 +65,8 @@ func (s DummyStore) Flush() error {
 func (s DummyStore) Close() error {
 	return nil
 }
+
+// Ping checks the store is reachable.
+func (s DummyStore) Ping() error {
+	return nil
+}
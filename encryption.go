@@ -0,0 +1,287 @@
+package gokvstores
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"time"
+
+	conv "github.com/cstockton/go-conv"
+)
+
+// KeyProvider provides the AES keys used by an EncryptedStore.
+type KeyProvider interface {
+	// CurrentKey returns the ID and value of the key encrypting new values.
+	CurrentKey() (id string, key []byte, err error)
+
+	// Key returns the key with the given ID, to decrypt existing values.
+	Key(id string) ([]byte, error)
+}
+
+// StaticKeyProvider is a KeyProvider backed by a fixed set of keys.
+// Keys are rotated by adding a new key and making it current: values
+// encrypted with previous keys remain readable as long as they are kept.
+type StaticKeyProvider struct {
+	// Current is the ID of the key encrypting new values.
+	Current string
+
+	// Keys are the AES-128, AES-192 or AES-256 keys indexed by ID.
+	Keys map[string][]byte
+}
+
+// CurrentKey returns the current key.
+func (p *StaticKeyProvider) CurrentKey() (string, []byte, error) {
+	key, err := p.Key(p.Current)
+	return p.Current, key, err
+}
+
+// Key returns the key with the given ID.
+func (p *StaticKeyProvider) Key(id string) ([]byte, error) {
+	key, ok := p.Keys[id]
+	if !ok {
+		return nil, fmt.Errorf("gokvstores: unknown encryption key %q", id)
+	}
+	return key, nil
+}
+
+// EncryptedStore is a KVStore decorator encrypting values with AES-GCM before
+// they reach the wrapped store.
+//
+// Values are stringified before encryption, so they are read back as strings.
+// Each ciphertext is an envelope made of the key ID length (one byte), the key
+// ID, the nonce and the sealed value authenticated with the store key.
+// As encryption is randomized, slices can hold duplicate values.
+type EncryptedStore struct {
+	store    KVStore
+	provider KeyProvider
+}
+
+// NewEncryptedStore returns an EncryptedStore wrapping the given store.
+func NewEncryptedStore(store KVStore, provider KeyProvider) *EncryptedStore {
+	return &EncryptedStore{store: store, provider: provider}
+}
+
+// aead returns the AES-GCM cipher for the given key.
+func (e *EncryptedStore) aead(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encrypt returns the envelope of the given value.
+func (e *EncryptedStore) encrypt(key string, value interface{}) (string, error) {
+	id, secret, err := e.provider.CurrentKey()
+	if err != nil {
+		return "", err
+	}
+
+	if len(id) > 255 {
+		return "", fmt.Errorf("gokvstores: encryption key ID %q is too long", id)
+	}
+
+	aead, err := e.aead(secret)
+	if err != nil {
+		return "", err
+	}
+
+	envelope := make([]byte, 1+len(id)+aead.NonceSize())
+	envelope[0] = byte(len(id))
+	copy(envelope[1:], id)
+
+	nonce := envelope[1+len(id):]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	return string(aead.Seal(envelope, nonce, []byte(conv.String(value)), []byte(key))), nil
+}
+
+// decrypt returns the value sealed in the given envelope.
+func (e *EncryptedStore) decrypt(key string, value interface{}) (string, error) {
+	envelope := []byte(conv.String(value))
+	if len(envelope) == 0 || len(envelope) < 1+int(envelope[0]) {
+		return "", ErrInvalidCiphertext
+	}
+
+	id := string(envelope[1 : 1+envelope[0]])
+	envelope = envelope[1+len(id):]
+
+	secret, err := e.provider.Key(id)
+	if err != nil {
+		return "", err
+	}
+
+	aead, err := e.aead(secret)
+	if err != nil {
+		return "", err
+	}
+
+	if len(envelope) < aead.NonceSize() {
+		return "", ErrInvalidCiphertext
+	}
+
+	nonce, sealed := envelope[:aead.NonceSize()], envelope[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, sealed, []byte(key))
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+
+	return string(plaintext), nil
+}
+
+// encryptSlice encrypts every non-nil value of the given slice.
+func (e *EncryptedStore) encryptSlice(key string, values []interface{}) ([]interface{}, error) {
+	encrypted := make([]interface{}, 0, len(values))
+
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+
+		ciphertext, err := e.encrypt(key, v)
+		if err != nil {
+			return nil, err
+		}
+
+		encrypted = append(encrypted, ciphertext)
+	}
+
+	return encrypted, nil
+}
+
+// Get returns the decrypted value for the given key.
+func (e *EncryptedStore) Get(key string) (interface{}, error) {
+	value, err := e.store.Get(key)
+	if err != nil || value == nil {
+		return value, err
+	}
+
+	return e.decrypt(key, value)
+}
+
+// Set encrypts and sets value for the given key.
+func (e *EncryptedStore) Set(key string, value interface{}) error {
+	ciphertext, err := e.encrypt(key, value)
+	if err != nil {
+		return err
+	}
+
+	return e.store.Set(key, ciphertext)
+}
+
+// SetWithExpiration encrypts and sets value for the given key with a specific expiration.
+func (e *EncryptedStore) SetWithExpiration(key string, value interface{}, expiration time.Duration) error {
+	ciphertext, err := e.encrypt(key, value)
+	if err != nil {
+		return err
+	}
+
+	return e.store.SetWithExpiration(key, ciphertext, expiration)
+}
+
+// GetMap returns the map for the given key, with decrypted values.
+func (e *EncryptedStore) GetMap(key string) (map[string]interface{}, error) {
+	values, err := e.store.GetMap(key)
+	if err != nil || values == nil {
+		return values, err
+	}
+
+	decrypted := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if decrypted[k], err = e.decrypt(key, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return decrypted, nil
+}
+
+// SetMap encrypts the map values and sets the map for the given key.
+func (e *EncryptedStore) SetMap(key string, values map[string]interface{}) error {
+	encrypted := make(map[string]interface{}, len(values))
+
+	for k, v := range values {
+		ciphertext, err := e.encrypt(key, v)
+		if err != nil {
+			return err
+		}
+		encrypted[k] = ciphertext
+	}
+
+	return e.store.SetMap(key, encrypted)
+}
+
+// GetSlice returns the slice for the given key, with decrypted values.
+func (e *EncryptedStore) GetSlice(key string) ([]interface{}, error) {
+	values, err := e.store.GetSlice(key)
+	if err != nil || values == nil {
+		return values, err
+	}
+
+	decrypted := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+
+		plaintext, err := e.decrypt(key, v)
+		if err != nil {
+			return nil, err
+		}
+
+		decrypted = append(decrypted, plaintext)
+	}
+
+	return decrypted, nil
+}
+
+// SetSlice encrypts the slice values and sets the slice for the given key.
+func (e *EncryptedStore) SetSlice(key string, values []interface{}) error {
+	encrypted, err := e.encryptSlice(key, values)
+	if err != nil {
+		return err
+	}
+
+	return e.store.SetSlice(key, encrypted)
+}
+
+// AppendSlice encrypts values and appends them to an existing slice.
+// If key does not exist, creates slice.
+func (e *EncryptedStore) AppendSlice(key string, values ...interface{}) error {
+	encrypted, err := e.encryptSlice(key, values)
+	if err != nil {
+		return err
+	}
+
+	return e.store.AppendSlice(key, encrypted...)
+}
+
+// Exists checks if the given key exists.
+func (e *EncryptedStore) Exists(key string) (bool, error) {
+	return e.store.Exists(key)
+}
+
+// Delete deletes the given key.
+func (e *EncryptedStore) Delete(key string) error {
+	return e.store.Delete(key)
+}
+
+// Flush flushes the store.
+func (e *EncryptedStore) Flush() error {
+	return e.store.Flush()
+}
+
+// Close closes the connection to the store.
+func (e *EncryptedStore) Close() error {
+	return e.store.Close()
+}
+
+// Ping checks the store is reachable.
+func (e *EncryptedStore) Ping() error {
+	return e.store.Ping()
+}
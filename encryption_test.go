@@ -0,0 +1,60 @@
+package gokvstores
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptedStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	provider := &StaticKeyProvider{
+		Current: "v1",
+		Keys:    map[string][]byte{"v1": bytes.Repeat([]byte("k"), 32)},
+	}
+
+	store := NewEncryptedStore(memory, provider)
+
+	testStore(t, store)
+
+	is.Nil(store.Set("key", "secret"))
+
+	raw, err := memory.Get("key")
+	is.Nil(err)
+	is.NotContains(raw, "secret")
+
+	// Rotation
+
+	provider.Keys["v2"] = bytes.Repeat([]byte("l"), 32)
+	provider.Current = "v2"
+
+	v, err := store.Get("key")
+	is.Nil(err)
+	is.Equal("secret", v)
+
+	is.Nil(store.Set("other", "secret"))
+
+	delete(provider.Keys, "v1")
+
+	_, err = store.Get("key")
+	is.NotNil(err)
+
+	v, err = store.Get("other")
+	is.Nil(err)
+	is.Equal("secret", v)
+
+	// Values are bound to their key
+
+	raw, err = memory.Get("other")
+	is.Nil(err)
+	is.Nil(memory.Set("moved", raw))
+
+	_, err = store.Get("moved")
+	is.NotNil(err)
+}
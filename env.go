@@ -0,0 +1,79 @@
+package gokvstores
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// defaultEnvPrefix is the prefix of the variables read by NewStoreFromEnv
+// when none is given.
+const defaultEnvPrefix = "KVSTORE"
+
+// NewStoreFromEnv returns the store described by the environment variables
+// named after prefix, KVSTORE if empty, so that services share the same cache
+// configuration. With the default prefix, the variables are:
+//
+//	KVSTORE_URL       a store URL, as accepted by NewStoreFromURL
+//	KVSTORE_BACKEND   the backend, overriding the scheme of the URL
+//	KVSTORE_ADDR      the comma-separated host[:port] addresses
+//	KVSTORE_USERNAME  the username
+//	KVSTORE_PASSWORD  the password
+//	KVSTORE_DB        the Redis database
+//
+// Each other variable is an option of the backend, named by the rest of the
+// variable name in lower case: KVSTORE_EXPIRATION=5m sets the expiration and
+// KVSTORE_POOL_SIZE=20 the pool_size option, overriding the URL ones. The
+// backend defaults to memory, and empty variables are ignored.
+func NewStoreFromEnv(prefix string) (KVStore, error) {
+	config, err := envConfig(prefix, os.Environ())
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStoreFromConfig(config)
+}
+
+// envConfig returns the configuration described by the given environment, as
+// returned by os.Environ.
+func envConfig(prefix string, environ []string) (*Config, error) {
+	if prefix == "" {
+		prefix = defaultEnvPrefix
+	}
+	prefix = strings.TrimSuffix(prefix, "_") + "_"
+
+	config := &Config{Options: map[string]interface{}{}}
+
+	for _, variable := range environ {
+		parts := strings.SplitN(variable, "=", 2)
+		if len(parts) != 2 || parts[1] == "" || !strings.HasPrefix(parts[0], prefix) {
+			continue
+		}
+
+		name, value := strings.TrimPrefix(parts[0], prefix), parts[1]
+
+		switch name {
+		case "URL":
+			config.URL = value
+		case "BACKEND":
+			config.Backend = value
+		case "ADDR":
+			config.Addr = value
+		case "USERNAME":
+			config.Username = value
+		case "PASSWORD":
+			config.Password = value
+		case "DB":
+			db, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("gokvstores: invalid %s %q", parts[0], value)
+			}
+			config.DB = db
+		default:
+			config.Options[strings.ToLower(name)] = value
+		}
+	}
+
+	return config, nil
+}
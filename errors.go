@@ -0,0 +1,115 @@
+package gokvstores
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrCircuitOpen is returned by a BreakerStore whose circuit is open.
+	ErrCircuitOpen = errors.New("gokvstores: circuit breaker is open")
+
+	// ErrRateLimited is returned by a fail-fast RateLimitStore exceeding its limits.
+	ErrRateLimited = errors.New("gokvstores: rate limit exceeded")
+
+	// ErrInvalidCiphertext is returned by an EncryptedStore reading a value it cannot decrypt.
+	ErrInvalidCiphertext = errors.New("gokvstores: invalid ciphertext")
+
+	// ErrWriteQueueFull is returned by a WriteBehindStore whose write queue is full.
+	ErrWriteQueueFull = errors.New("gokvstores: write queue is full")
+
+	// ErrStoreClosed is returned when writing to a closed store.
+	ErrStoreClosed = errors.New("gokvstores: store is closed")
+
+	// ErrVersionNotFound is returned by a VersionedStore asked for an unknown version.
+	ErrVersionNotFound = errors.New("gokvstores: version not found")
+
+	// ErrInjectedFault is the default error returned by a ChaosStore.
+	ErrInjectedFault = errors.New("gokvstores: injected fault")
+
+	// ErrFlushNotAllowed is returned by a FlushGuardStore refusing to flush.
+	ErrFlushNotAllowed = errors.New("gokvstores: flush is not allowed")
+
+	// ErrNoTenant is returned by a TenantStore view whose context carries no tenant.
+	ErrNoTenant = errors.New("gokvstores: no tenant in context")
+
+	// ErrReadOnly is returned when writing to a read-only store.
+	ErrReadOnly = errors.New("gokvstores: store is read-only")
+
+	// ErrNotSupported is returned when a store does not support an operation.
+	ErrNotSupported = errors.New("gokvstores: operation not supported by this store")
+
+	// ErrTxnConflict is returned when a transaction is aborted because a watched key changed.
+	ErrTxnConflict = errors.New("gokvstores: transaction aborted, a watched key changed")
+
+	// ErrLocked is returned when acquiring a lock held by another owner.
+	ErrLocked = errors.New("gokvstores: lock is held by another owner")
+
+	// ErrLockNotHeld is returned when releasing a lock which expired or is held by another owner.
+	ErrLockNotHeld = errors.New("gokvstores: lock is not held")
+
+	// ErrInvalidLockTTL is returned when acquiring a lock with a zero or negative TTL.
+	ErrInvalidLockTTL = errors.New("gokvstores: lock TTL must be positive")
+
+	// ErrPathNotFound is returned when a JSON path does not match any value of a document.
+	ErrPathNotFound = errors.New("gokvstores: JSON path not found")
+
+	// ErrIndexNotFound is returned when searching an index which was not created.
+	ErrIndexNotFound = errors.New("gokvstores: search index not found")
+
+	// ErrWrongType is returned when reading or changing a key as a map or a
+	// slice while it holds another type of value.
+	ErrWrongType = errors.New("gokvstores: key holds a value of the wrong type")
+)
+
+// ValueTooLargeError is returned when writing a value larger than allowed.
+type ValueTooLargeError struct {
+	Key     string
+	Size    int
+	MaxSize int
+}
+
+func (e *ValueTooLargeError) Error() string {
+	return fmt.Sprintf("gokvstores: value of %q is %d bytes, more than the %d bytes allowed", e.Key, e.Size, e.MaxSize)
+}
+
+// KeyExistsError is returned when writing a key that cannot be overwritten.
+type KeyExistsError struct {
+	Key string
+}
+
+func (e *KeyExistsError) Error() string {
+	return fmt.Sprintf("gokvstores: key %q already exists", e.Key)
+}
+
+// QuotaExceededError is returned when a write would exceed a tenant quota.
+type QuotaExceededError struct {
+	Tenant string
+	Quota  string
+	Limit  int
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("gokvstores: tenant %q exceeds its %s quota of %d", e.Tenant, e.Quota, e.Limit)
+}
+
+// InvalidKeyError is returned when using a key breaking the validation rules.
+type InvalidKeyError struct {
+	Key    string
+	Reason string
+}
+
+func (e *InvalidKeyError) Error() string {
+	return fmt.Sprintf("gokvstores: invalid key %q: %s", e.Key, e.Reason)
+}
+
+// NotReplicatedError is returned when a write was acknowledged by fewer
+// replicas than required in time. The write is not rolled back.
+type NotReplicatedError struct {
+	Replicas     int
+	Acknowledged int
+}
+
+func (e *NotReplicatedError) Error() string {
+	return fmt.Sprintf("gokvstores: write acknowledged by %d of the %d replicas required", e.Acknowledged, e.Replicas)
+}
@@ -0,0 +1,39 @@
+package gokvstores
+
+import "expvar"
+
+// expvarStats is the value published by PublishExpvar.
+type expvarStats struct {
+	Stats
+	Pool     *PoolStats       `json:"pool,omitempty"`
+	Keyspace *KeyspaceSummary `json:"keyspace,omitempty"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// PublishExpvar publishes the stats of the given store as an expvar variable
+// named name, so they are served on /debug/vars. The stats are read each time
+// the variable is, and include the connection pool statistics of Redis stores
+// and the keyspace summary of memory stores.
+// Like expvar.Publish, it panics if the name is already in use.
+func PublishExpvar(name string, store StatsProvider) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		var value expvarStats
+
+		stats, err := store.Stats()
+		if err != nil {
+			value.Error = err.Error()
+		}
+		value.Stats = stats
+
+		if redis, ok := store.(*RedisStore); ok {
+			pool := redis.PoolStats()
+			value.Pool = &pool
+		}
+
+		if memory, ok := store.(*MemoryStore); ok {
+			value.Keyspace = memory.Summary(nil)
+		}
+
+		return value
+	}))
+}
@@ -0,0 +1,49 @@
+package gokvstores
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	PublishExpvar("kvstore_test", store.(StatsProvider))
+
+	is.Nil(store.Set("key", "value"))
+	_, err = store.Get("key")
+	is.Nil(err)
+
+	v := expvar.Get("kvstore_test")
+	is.NotNil(v)
+
+	values := map[string]interface{}{}
+	is.Nil(json.Unmarshal([]byte(v.String()), &values))
+	is.Equal(map[string]interface{}{
+		"operations":  float64(2),
+		"hits":        float64(1),
+		"misses":      float64(0),
+		"errors":      float64(0),
+		"keys":        float64(1),
+		"evictions":   float64(0),
+		"expirations": float64(0),
+		"rejected":    float64(0),
+		"keyspace": map[string]interface{}{
+			"items":   float64(1),
+			"bytes":   float64(len("key") + len("value")),
+			"spilled": float64(0),
+			"prefixes": map[string]interface{}{
+				"key": map[string]interface{}{"items": float64(1), "bytes": float64(len("key") + len("value"))},
+			},
+		},
+	}, values)
+
+	is.Panics(func() { PublishExpvar("kvstore_test", store.(StatsProvider)) })
+}
@@ -0,0 +1,29 @@
+package gokvstores
+
+// FlushGuardStore is a KVStore decorator refusing to flush the wrapped store
+// unless the confirmation token it was created with is provided.
+type FlushGuardStore struct {
+	KVStore
+
+	token string
+}
+
+// NewFlushGuardStore returns a FlushGuardStore wrapping the given store.
+// An empty token disables flushing altogether.
+func NewFlushGuardStore(store KVStore, token string) *FlushGuardStore {
+	return &FlushGuardStore{KVStore: store, token: token}
+}
+
+// Flush always fails with ErrFlushNotAllowed, use FlushWithToken instead.
+func (s *FlushGuardStore) Flush() error {
+	return ErrFlushNotAllowed
+}
+
+// FlushWithToken flushes the store if the given token is the confirmation token.
+func (s *FlushGuardStore) FlushWithToken(token string) error {
+	if s.token == "" || token != s.token {
+		return ErrFlushNotAllowed
+	}
+
+	return s.KVStore.Flush()
+}
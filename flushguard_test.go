@@ -0,0 +1,34 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlushGuardStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	store := NewFlushGuardStore(memory, "wipe-staging-cache")
+
+	is.Nil(store.Set("key", "value"))
+
+	is.Equal(ErrFlushNotAllowed, store.Flush())
+	is.Equal(ErrFlushNotAllowed, store.FlushWithToken("wrong"))
+
+	exists, err := store.Exists("key")
+	is.Nil(err)
+	is.True(exists)
+
+	is.Nil(store.FlushWithToken("wipe-staging-cache"))
+
+	exists, err = store.Exists("key")
+	is.Nil(err)
+	is.False(exists)
+
+	is.Equal(ErrFlushNotAllowed, NewFlushGuardStore(memory, "").FlushWithToken(""))
+}
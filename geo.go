@@ -0,0 +1,220 @@
+package gokvstores
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// earthRadius is the Earth radius in meters used by Redis distance computations.
+const earthRadius = 6372797.560856
+
+// GeoLocation is a named position.
+type GeoLocation struct {
+	Name      string
+	Longitude float64
+	Latitude  float64
+
+	// Distance is the distance in meters to the center of a GeoRadius query.
+	Distance float64
+}
+
+// Geo is implemented by stores able to index positions.
+type Geo interface {
+	// GeoAdd adds the locations to the key, replacing the positions of
+	// existing names.
+	GeoAdd(key string, locations ...GeoLocation) error
+
+	// GeoRadius returns the locations of the key within radius meters of
+	// the given position, nearest first.
+	GeoRadius(key string, longitude, latitude, radius float64) ([]GeoLocation, error)
+
+	// GeoDist returns the distance in meters between two names of the key,
+	// and whether both exist.
+	GeoDist(key, name1, name2 string) (float64, bool, error)
+}
+
+// haversine returns the distance in meters between two positions, assuming
+// the Earth is a sphere.
+func haversine(longitude1, latitude1, longitude2, latitude2 float64) float64 {
+	lat1 := latitude1 * math.Pi / 180
+	lat2 := latitude2 * math.Pi / 180
+	u := math.Sin((lat2 - lat1) / 2)
+	v := math.Sin((longitude2 - longitude1) * math.Pi / 180 / 2)
+
+	return 2 * earthRadius * math.Asin(math.Sqrt(u*u+math.Cos(lat1)*math.Cos(lat2)*v*v))
+}
+
+// ----------------------------------------------------------------------------
+// Memory
+// ----------------------------------------------------------------------------
+
+// geoSet is the MemoryStore Geo value. Lookups scan all its locations.
+type geoSet struct {
+	mu        sync.RWMutex
+	locations map[string]GeoLocation
+}
+
+// geoSet returns the geoSet of the given key, creating it if create is set.
+// It returns nil if the key does not exist and create is not set.
+func (c *MemoryStore) geoSet(key string, create bool) (*geoSet, error) {
+	var newValue func() interface{}
+	if create {
+		newValue = func() interface{} { return &geoSet{locations: map[string]GeoLocation{}} }
+	}
+
+	v, found := c.getOrAdd(key, newValue)
+	if !found {
+		return nil, nil
+	}
+
+	s, ok := v.(*geoSet)
+	if !ok {
+		return nil, fmt.Errorf("gokvstores: %q is not a geo index", key)
+	}
+
+	return s, nil
+}
+
+// GeoAdd adds the locations to the key, replacing the positions of existing
+// names.
+func (c *MemoryStore) GeoAdd(key string, locations ...GeoLocation) error {
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	s, err := c.geoSet(key, true)
+	c.stats.write(err)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	for _, location := range locations {
+		location.Distance = 0
+		s.locations[location.Name] = location
+	}
+	s.mu.Unlock()
+
+	c.watches.notify(ChangeSet, key)
+	return nil
+}
+
+// GeoRadius returns the locations of the key within radius meters of the
+// given position, nearest first.
+func (c *MemoryStore) GeoRadius(key string, longitude, latitude, radius float64) ([]GeoLocation, error) {
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	s, err := c.geoSet(key, false)
+	c.stats.read(s != nil, err)
+	if s == nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	var locations []GeoLocation
+	for _, location := range s.locations {
+		location.Distance = haversine(longitude, latitude, location.Longitude, location.Latitude)
+		if location.Distance <= radius {
+			locations = append(locations, location)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(locations, func(i, j int) bool {
+		return locations[i].Distance < locations[j].Distance
+	})
+
+	return locations, nil
+}
+
+// GeoDist returns the distance in meters between two names of the key, and
+// whether both exist.
+func (c *MemoryStore) GeoDist(key, name1, name2 string) (float64, bool, error) {
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	s, err := c.geoSet(key, false)
+	if s == nil {
+		c.stats.read(false, err)
+		return 0, false, err
+	}
+
+	s.mu.RLock()
+	location1, found1 := s.locations[name1]
+	location2, found2 := s.locations[name2]
+	s.mu.RUnlock()
+
+	found := found1 && found2
+	c.stats.read(found, nil)
+	if !found {
+		return 0, false, nil
+	}
+
+	return haversine(location1.Longitude, location1.Latitude, location2.Longitude, location2.Latitude), true, nil
+}
+
+// ----------------------------------------------------------------------------
+// Redis
+// ----------------------------------------------------------------------------
+
+// GeoAdd adds the locations to the key, replacing the positions of existing
+// names.
+func (r *RedisStore) GeoAdd(key string, locations ...GeoLocation) error {
+	geoLocations := make([]*redis.GeoLocation, len(locations))
+	for i, location := range locations {
+		geoLocations[i] = &redis.GeoLocation{
+			Name:      location.Name,
+			Longitude: location.Longitude,
+			Latitude:  location.Latitude,
+		}
+	}
+
+	err := r.client.GeoAdd(r.ctx, key, geoLocations...).Err()
+	r.stats.write(err)
+	return err
+}
+
+// GeoRadius returns the locations of the key within radius meters of the
+// given position, nearest first.
+func (r *RedisStore) GeoRadius(key string, longitude, latitude, radius float64) ([]GeoLocation, error) {
+	geoLocations, err := r.client.GeoRadius(r.ctx, key, longitude, latitude, &redis.GeoRadiusQuery{
+		Radius:    radius,
+		Unit:      "m",
+		WithCoord: true,
+		WithDist:  true,
+		Sort:      "ASC",
+	}).Result()
+	r.stats.read(len(geoLocations) > 0, err)
+	if err != nil {
+		return nil, err
+	}
+
+	var locations []GeoLocation
+	for _, location := range geoLocations {
+		locations = append(locations, GeoLocation{
+			Name:      location.Name,
+			Longitude: location.Longitude,
+			Latitude:  location.Latitude,
+			Distance:  location.Dist,
+		})
+	}
+
+	return locations, nil
+}
+
+// GeoDist returns the distance in meters between two names of the key, and
+// whether both exist.
+func (r *RedisStore) GeoDist(key, name1, name2 string) (float64, bool, error) {
+	distance, err := r.client.GeoDist(r.ctx, key, name1, name2, "m").Result()
+	if err == redis.Nil {
+		r.stats.read(false, nil)
+		return 0, false, nil
+	}
+
+	r.stats.read(true, err)
+	return distance, err == nil, err
+}
@@ -0,0 +1,53 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testGeo(t *testing.T, store KVStore) {
+	is := assert.New(t)
+
+	geo := store.(Geo)
+
+	is.Nil(store.Delete("places"))
+
+	is.Nil(geo.GeoAdd("places",
+		GeoLocation{Name: "Palermo", Longitude: 13.361389, Latitude: 38.115556},
+		GeoLocation{Name: "Catania", Longitude: 15.087269, Latitude: 37.502669},
+	))
+
+	distance, found, err := geo.GeoDist("places", "Palermo", "Catania")
+	is.Nil(err)
+	is.True(found)
+	is.InDelta(166274, distance, 10)
+
+	_, found, err = geo.GeoDist("places", "Palermo", "Rome")
+	is.Nil(err)
+	is.False(found)
+
+	locations, err := geo.GeoRadius("places", 15, 37, 200000)
+	is.Nil(err)
+	is.Len(locations, 2)
+	is.Equal("Catania", locations[0].Name)
+	is.InDelta(56441, locations[0].Distance, 10)
+	is.InDelta(15.087269, locations[0].Longitude, 0.0001)
+	is.Equal("Palermo", locations[1].Name)
+
+	locations, err = geo.GeoRadius("places", 15, 37, 100000)
+	is.Nil(err)
+	is.Len(locations, 1)
+
+	locations, err = geo.GeoRadius("missing", 15, 37, 100000)
+	is.Nil(err)
+	is.Empty(locations)
+}
+
+func TestMemoryStoreGeo(t *testing.T) {
+	store, err := NewMemoryStore(time.Second*10, time.Second*10)
+	assert.Nil(t, err)
+
+	testGeo(t, store)
+}
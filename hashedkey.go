@@ -0,0 +1,54 @@
+package gokvstores
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// HashedKeyStore is a KVStore decorator replacing keys longer than a maximum
+// length by their SHA-256, optionally keeping a readable prefix of the key.
+type HashedKeyStore struct {
+	*interceptedStore
+
+	maxLength    int
+	prefixLength int
+}
+
+// NewHashedKeyStore returns a HashedKeyStore wrapping the given store.
+// Keys longer than maxLength are hashed, keeping their first prefixLength bytes.
+func NewHashedKeyStore(store KVStore, maxLength int, prefixLength int) *HashedKeyStore {
+	h := &HashedKeyStore{
+		maxLength:    maxLength,
+		prefixLength: prefixLength,
+	}
+
+	h.interceptedStore = &interceptedStore{store: store, intercept: h.rewrite}
+
+	return h
+}
+
+// Key returns the key the given key is stored under.
+func (h *HashedKeyStore) Key(key string) string {
+	if len(key) <= h.maxLength {
+		return key
+	}
+
+	prefix := ""
+	if h.prefixLength > 0 {
+		prefix = key
+		if len(prefix) > h.prefixLength {
+			prefix = prefix[:h.prefixLength]
+		}
+		prefix += "#"
+	}
+
+	sum := sha256.Sum256([]byte(key))
+
+	return prefix + hex.EncodeToString(sum[:])
+}
+
+// rewrite hashes the key of the operation if needed.
+func (h *HashedKeyStore) rewrite(op *operation, next func() error) error {
+	op.key = h.Key(op.key)
+	return next()
+}
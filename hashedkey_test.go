@@ -0,0 +1,41 @@
+package gokvstores
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashedKeyStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	store := NewHashedKeyStore(memory, 32, 8)
+
+	testStore(t, store)
+
+	long := "users:" + strings.Repeat("x", 100)
+	hashed := store.Key(long)
+
+	is.Equal("key", store.Key("key"))
+	is.Equal(8+1+64, len(hashed))
+	is.True(strings.HasPrefix(hashed, "users:xx#"))
+
+	is.Nil(store.Set(long, "value"))
+
+	v, err := store.Get(long)
+	is.Nil(err)
+	is.Equal("value", v)
+
+	v, err = memory.Get(hashed)
+	is.Nil(err)
+	is.Equal("value", v)
+
+	exists, err := memory.Exists(long)
+	is.Nil(err)
+	is.False(exists)
+}
@@ -0,0 +1,76 @@
+package gokvstores
+
+import "sync"
+
+// HookStore is a KVStore decorator calling registered hooks on cache events.
+// Hooks run synchronously, after the operation succeeded.
+type HookStore struct {
+	*interceptedStore
+
+	mu       sync.RWMutex
+	onSet    []func(key string, value interface{})
+	onDelete []func(key string)
+	onMiss   []func(key string)
+}
+
+// NewHookStore returns a HookStore wrapping the given store.
+func NewHookStore(store KVStore) *HookStore {
+	h := &HookStore{}
+	h.interceptedStore = &interceptedStore{store: store, intercept: h.dispatch}
+
+	return h
+}
+
+// OnSet registers a hook called when a key is written. For AppendSlice,
+// value holds the appended values.
+func (h *HookStore) OnSet(hook func(key string, value interface{})) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.onSet = append(h.onSet, hook)
+}
+
+// OnDelete registers a hook called when a key is deleted.
+func (h *HookStore) OnDelete(hook func(key string)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.onDelete = append(h.onDelete, hook)
+}
+
+// OnMiss registers a hook called when a lookup does not find its key.
+func (h *HookStore) OnMiss(hook func(key string)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.onMiss = append(h.onMiss, hook)
+}
+
+// dispatch calls the hooks matching a successful operation.
+func (h *HookStore) dispatch(op *operation, next func() error) error {
+	if err := next(); err != nil {
+		return err
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	switch op.name {
+	case "Set", "SetWithExpiration", "SetMap", "SetSlice", "AppendSlice":
+		for _, hook := range h.onSet {
+			hook(op.key, op.value)
+		}
+	case "Delete":
+		for _, hook := range h.onDelete {
+			hook(op.key)
+		}
+	default:
+		if op.read() && !op.hit {
+			for _, hook := range h.onMiss {
+				hook(op.key)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHookStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	testStore(t, NewHookStore(memory))
+
+	store := NewHookStore(memory)
+
+	set := map[string]interface{}{}
+	deleted := []string{}
+	missed := []string{}
+
+	store.OnSet(func(key string, value interface{}) { set[key] = value })
+	store.OnDelete(func(key string) { deleted = append(deleted, key) })
+	store.OnMiss(func(key string) { missed = append(missed, key) })
+
+	is.Nil(store.Set("key", "value"))
+	is.Nil(store.SetMap("map", map[string]interface{}{"language": "go"}))
+
+	_, err = store.Get("key")
+	is.Nil(err)
+	_, err = store.Get("unknown")
+	is.Nil(err)
+	_, err = store.Exists("other")
+	is.Nil(err)
+
+	is.Nil(store.Delete("key"))
+
+	is.Equal(map[string]interface{}{
+		"key": "value",
+		"map": map[string]interface{}{"language": "go"},
+	}, set)
+	is.Equal([]string{"key"}, deleted)
+	is.Equal([]string{"unknown", "other"}, missed)
+}
@@ -0,0 +1,145 @@
+package gokvstores
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// hotPromoteAfter is the number of counted reads promoting a key.
+const hotPromoteAfter = 4
+
+// hotEntry is a value served by the lock-free read path.
+type hotEntry struct {
+	value interface{}
+
+	// expiration is the segment expiration, in nanoseconds, zero if the
+	// value does not expire.
+	expiration int64
+}
+
+// hotKeys serves the most read keys of a MemoryStore from an immutable map
+// replaced on each change, so that reads take no lock. Writers update the
+// cache before calling invalidate.
+type hotKeys struct {
+	// writes comes first to keep it 64-bit aligned.
+	writes uint64
+
+	entries atomic.Value // map[string]hotEntry
+	size    int
+	clock   Clock
+
+	// mu serializes the changes of entries and counts.
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// newHotKeys returns a hotKeys holding up to size keys.
+func newHotKeys(size int, clock Clock) *hotKeys {
+	h := &hotKeys{size: size, clock: clock, counts: map[string]int{}}
+	h.entries.Store(map[string]hotEntry{})
+	return h
+}
+
+// get returns the value of a hot key.
+func (h *hotKeys) get(key string) (interface{}, time.Time, bool) {
+	e, found := h.entries.Load().(map[string]hotEntry)[key]
+	if !found {
+		return nil, time.Time{}, false
+	}
+
+	if e.expiration > 0 {
+		if h.clock.Now().UnixNano() > e.expiration {
+			return nil, time.Time{}, false
+		}
+		return e.value, time.Unix(0, e.expiration), true
+	}
+
+	return e.value, time.Time{}, true
+}
+
+// read counts a read of a key found in the shard, promoting it once read
+// often enough. Reads are not counted while another goroutine holds the lock,
+// so they never wait.
+func (h *hotKeys) read(shard *segment, key string) {
+	if !h.mu.TryLock() {
+		return
+	}
+	defer h.mu.Unlock()
+
+	h.counts[key]++
+	if h.counts[key] < hotPromoteAfter {
+		if len(h.counts) > 4*h.size {
+			h.counts = map[string]int{}
+		}
+		return
+	}
+	delete(h.counts, key)
+
+	writes := atomic.LoadUint64(&h.writes)
+
+	item, found := shard.peek(key)
+	if !found {
+		return
+	}
+
+	e := hotEntry{value: item.object, expiration: item.expiration}
+
+	h.replace(func(entries map[string]hotEntry) {
+		if len(entries) >= h.size {
+			// Make room by dropping an arbitrary key.
+			for k := range entries {
+				delete(entries, k)
+				break
+			}
+		}
+		entries[key] = e
+	})
+
+	// A write since the lookup may have missed the new entry: drop it.
+	if atomic.LoadUint64(&h.writes) != writes {
+		h.replace(func(entries map[string]hotEntry) {
+			delete(entries, key)
+		})
+	}
+}
+
+// invalidate drops the given key, after it was changed in the cache.
+func (h *hotKeys) invalidate(key string) {
+	atomic.AddUint64(&h.writes, 1)
+
+	if _, found := h.entries.Load().(map[string]hotEntry)[key]; !found {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.replace(func(entries map[string]hotEntry) {
+		delete(entries, key)
+	})
+}
+
+// clear drops all the keys.
+func (h *hotKeys) clear() {
+	atomic.AddUint64(&h.writes, 1)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries.Store(map[string]hotEntry{})
+	h.counts = map[string]int{}
+}
+
+// replace stores a copy of the entries changed by fn, with the lock held.
+func (h *hotKeys) replace(fn func(entries map[string]hotEntry)) {
+	current := h.entries.Load().(map[string]hotEntry)
+
+	entries := make(map[string]hotEntry, len(current)+1)
+	for k, e := range current {
+		entries[k] = e
+	}
+	fn(entries)
+
+	h.entries.Store(entries)
+}
@@ -0,0 +1,64 @@
+package gokvstores
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testHyperLogLog(t *testing.T, store KVStore) {
+	is := assert.New(t)
+
+	hll := store.(HyperLogLog)
+
+	is.Nil(store.Delete("visitors:a"))
+	is.Nil(store.Delete("visitors:b"))
+	is.Nil(store.Delete("visitors"))
+
+	count, err := hll.PFCount("visitors:a")
+	is.Nil(err)
+	is.Equal(int64(0), count)
+
+	for i := 0; i < 10000; i++ {
+		_, err = hll.PFAdd("visitors:a", "user"+strconv.Itoa(i))
+		is.Nil(err)
+	}
+	for i := 5000; i < 15000; i++ {
+		_, err = hll.PFAdd("visitors:b", "user"+strconv.Itoa(i))
+		is.Nil(err)
+	}
+
+	changed, err := hll.PFAdd("visitors:a", "user0")
+	is.Nil(err)
+	is.False(changed)
+
+	count, err = hll.PFCount("visitors:a")
+	is.Nil(err)
+	is.InDelta(10000, count, 300)
+
+	count, err = hll.PFCount("visitors:a", "visitors:b")
+	is.Nil(err)
+	is.InDelta(15000, count, 450)
+
+	is.Nil(hll.PFMerge("visitors", "visitors:a", "visitors:b"))
+
+	count, err = hll.PFCount("visitors")
+	is.Nil(err)
+	is.InDelta(15000, count, 450)
+}
+
+func TestMemoryStoreHyperLogLog(t *testing.T) {
+	store, err := NewMemoryStore(time.Second*10, time.Second*10)
+	assert.Nil(t, err)
+
+	testHyperLogLog(t, store)
+
+	_, err = store.(HyperLogLog).PFCount("visitors", "missing")
+	assert.Nil(t, err)
+
+	assert.Nil(t, store.Set("key", "value"))
+	_, err = store.(HyperLogLog).PFAdd("key", "user")
+	assert.NotNil(t, err)
+}
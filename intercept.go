@@ -0,0 +1,172 @@
+package gokvstores
+
+import "time"
+
+// operation describes a single KVStore call going through an interceptedStore.
+type operation struct {
+	// name is the KVStore method name (Get, SetMap, Flush...).
+	name string
+
+	// key is the key the call applies to, empty for store-wide calls.
+	// Interceptors may rewrite it before calling next.
+	key string
+
+	// value is the value being written or, once the call returned, the value read.
+	value interface{}
+
+	// hit reports whether a read call found the key.
+	hit bool
+
+	// store is the store the call is sent to. Interceptors may redirect it
+	// before calling next.
+	store KVStore
+}
+
+// read reports whether the operation is a lookup.
+func (op *operation) read() bool {
+	switch op.name {
+	case "Get", "GetMap", "GetSlice", "Exists":
+		return true
+	}
+	return false
+}
+
+// interceptor is invoked around every operation of an interceptedStore.
+// It must call next to hand the operation over to the wrapped store.
+type interceptor func(op *operation, next func() error) error
+
+// interceptedStore is a KVStore routing every call through an interceptor.
+// It is the building block of decorators observing or altering all operations.
+type interceptedStore struct {
+	store     KVStore
+	intercept interceptor
+}
+
+// Get returns value for the given key.
+func (s *interceptedStore) Get(key string) (interface{}, error) {
+	var value interface{}
+
+	op := &operation{name: "Get", key: key, store: s.store}
+	err := s.intercept(op, func() (err error) {
+		value, err = op.store.Get(op.key)
+		op.value, op.hit = value, value != nil
+		return err
+	})
+
+	return value, err
+}
+
+// Set sets value for the given key.
+func (s *interceptedStore) Set(key string, value interface{}) error {
+	op := &operation{name: "Set", key: key, value: value, store: s.store}
+	return s.intercept(op, func() error {
+		return op.store.Set(op.key, value)
+	})
+}
+
+// SetWithExpiration sets value for the given key with a specific expiration.
+func (s *interceptedStore) SetWithExpiration(key string, value interface{}, expiration time.Duration) error {
+	op := &operation{name: "SetWithExpiration", key: key, value: value, store: s.store}
+	return s.intercept(op, func() error {
+		return op.store.SetWithExpiration(op.key, value, expiration)
+	})
+}
+
+// GetMap returns map for the given key.
+func (s *interceptedStore) GetMap(key string) (map[string]interface{}, error) {
+	var value map[string]interface{}
+
+	op := &operation{name: "GetMap", key: key, store: s.store}
+	err := s.intercept(op, func() (err error) {
+		value, err = op.store.GetMap(op.key)
+		op.value, op.hit = value, value != nil
+		return err
+	})
+
+	return value, err
+}
+
+// SetMap sets map for the given key.
+func (s *interceptedStore) SetMap(key string, value map[string]interface{}) error {
+	op := &operation{name: "SetMap", key: key, value: value, store: s.store}
+	return s.intercept(op, func() error {
+		return op.store.SetMap(op.key, value)
+	})
+}
+
+// GetSlice returns slice for the given key.
+func (s *interceptedStore) GetSlice(key string) ([]interface{}, error) {
+	var value []interface{}
+
+	op := &operation{name: "GetSlice", key: key, store: s.store}
+	err := s.intercept(op, func() (err error) {
+		value, err = op.store.GetSlice(op.key)
+		op.value, op.hit = value, value != nil
+		return err
+	})
+
+	return value, err
+}
+
+// SetSlice sets slice for the given key.
+func (s *interceptedStore) SetSlice(key string, value []interface{}) error {
+	op := &operation{name: "SetSlice", key: key, value: value, store: s.store}
+	return s.intercept(op, func() error {
+		return op.store.SetSlice(op.key, value)
+	})
+}
+
+// AppendSlice appends values to an existing slice.
+// If key does not exist, creates slice.
+func (s *interceptedStore) AppendSlice(key string, values ...interface{}) error {
+	op := &operation{name: "AppendSlice", key: key, value: values, store: s.store}
+	return s.intercept(op, func() error {
+		return op.store.AppendSlice(op.key, values...)
+	})
+}
+
+// Exists checks if the given key exists.
+func (s *interceptedStore) Exists(key string) (bool, error) {
+	var exists bool
+
+	op := &operation{name: "Exists", key: key, store: s.store}
+	err := s.intercept(op, func() (err error) {
+		exists, err = op.store.Exists(op.key)
+		op.hit = exists
+		return err
+	})
+
+	return exists, err
+}
+
+// Delete deletes the given key.
+func (s *interceptedStore) Delete(key string) error {
+	op := &operation{name: "Delete", key: key, store: s.store}
+	return s.intercept(op, func() error {
+		return op.store.Delete(op.key)
+	})
+}
+
+// Flush flushes the store.
+func (s *interceptedStore) Flush() error {
+	op := &operation{name: "Flush", store: s.store}
+	return s.intercept(op, func() error {
+		return op.store.Flush()
+	})
+}
+
+// Close closes the connection to the store.
+func (s *interceptedStore) Close() error {
+	op := &operation{name: "Close", store: s.store}
+	return s.intercept(op, func() error {
+		return op.store.Close()
+	})
+}
+
+// Ping checks the store is reachable.
+func (s *interceptedStore) Ping() error {
+	op := &operation{name: "Ping", store: s.store}
+	return s.intercept(op, func() error {
+		return op.store.Ping()
+	})
+}
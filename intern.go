@@ -0,0 +1,98 @@
+package gokvstores
+
+// maxInterned caps the number of strings of an interner, so that it does not
+// grow with the values of a store whose strings are all different.
+const maxInterned = 1 << 16
+
+// maxInternedLength is the length of the longest strings interned, longer
+// ones being unlikely to repeat.
+const maxInternedLength = 64
+
+// interner shares the memory of equal strings, such as the field names of the
+// maps of a store. It is not safe for concurrent use.
+type interner map[string]string
+
+// intern returns the shared copy of s, adding it unless the interner is full.
+func (in interner) intern(s string) string {
+	if len(s) > maxInternedLength {
+		return s
+	}
+
+	if interned, ok := in[s]; ok {
+		return interned
+	}
+
+	if len(in) < maxInterned {
+		in[s] = s
+	}
+
+	return s
+}
+
+// slab copies small byte slices into shared buffers, to reduce the number of
+// allocations the garbage collector tracks. A buffer is freed once none of
+// its slices is referenced. It is not safe for concurrent use.
+type slab struct {
+	size int
+	buf  []byte
+}
+
+// newSlab returns a slab allocating buffers of the given size.
+func newSlab(size int) *slab {
+	return &slab{size: size}
+}
+
+// copy returns a copy of value in the current buffer, or value itself if it
+// is larger than an eighth of a buffer.
+func (b *slab) copy(value []byte) []byte {
+	if len(value) == 0 || len(value) > b.size/8 {
+		return value
+	}
+
+	if len(b.buf)+len(value) > cap(b.buf) {
+		b.buf = make([]byte, 0, b.size)
+	}
+
+	start := len(b.buf)
+	b.buf = append(b.buf, value...)
+
+	// The capacity is capped so that appending to the copy reallocates it.
+	return b.buf[start:len(b.buf):len(b.buf)]
+}
+
+// compact returns the value to store, with its strings interned and its byte
+// slices copied to the slab. Maps and slices are copied rather than modified.
+// It is called with the lock held.
+func (s *segment) compact(value interface{}) interface{} {
+	if s.strings == nil && s.slab == nil {
+		return value
+	}
+
+	switch v := value.(type) {
+	case string:
+		if s.strings != nil {
+			return s.strings.intern(v)
+		}
+	case []byte:
+		if s.slab != nil {
+			return s.slab.copy(v)
+		}
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for field, fieldValue := range v {
+			if s.strings != nil {
+				field = s.strings.intern(field)
+			}
+			m[field] = s.compact(fieldValue)
+		}
+		return m
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i, element := range v {
+			values[i] = s.compact(element)
+		}
+		return values
+	}
+
+	return value
+}
@@ -0,0 +1,70 @@
+package gokvstores
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterner(t *testing.T) {
+	is := assert.New(t)
+
+	in := interner{}
+
+	is.Equal("field", in.intern(string([]byte("field"))))
+	is.Equal("field", in.intern(string([]byte("field"))))
+	is.Len(in, 1)
+
+	long := string(make([]byte, maxInternedLength+1))
+	in.intern(long)
+	is.Len(in, 1)
+}
+
+func TestSlab(t *testing.T) {
+	is := assert.New(t)
+
+	b := newSlab(64)
+
+	value := []byte("value")
+	copied := b.copy(value)
+	is.Equal(value, copied)
+	is.Equal(len(copied), cap(copied))
+
+	value[0] = 'V'
+	is.Equal("value", string(copied))
+
+	// Appending to a copy does not overwrite the next one.
+	next := b.copy([]byte("next"))
+	_ = append(copied, 'x')
+	is.Equal("next", string(next))
+
+	large := make([]byte, 9)
+	is.True(&large[0] == &b.copy(large)[0])
+}
+
+func TestMemoryStoreCompaction(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{
+		Capacity:      100,
+		Shards:        4,
+		InternStrings: true,
+		SlabSize:      1024,
+	})
+	is.Nil(err)
+
+	testStore(t, store)
+
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		is.Nil(store.SetMap(key, map[string]interface{}{"name": key, "data": []byte(key)}))
+	}
+
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		m, err := store.GetMap(key)
+		is.Nil(err)
+		is.Equal(map[string]interface{}{"name": key, "data": []byte(key)}, m)
+	}
+}
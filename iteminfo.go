@@ -0,0 +1,132 @@
+package gokvstores
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ItemInfo describes a key of a store and its usage.
+type ItemInfo struct {
+	Key string
+
+	// Created is the time the key was first set, Updated the time it was
+	// last set, and Accessed the time it was last read, zero if never read.
+	Created  time.Time
+	Updated  time.Time
+	Accessed time.Time
+
+	// Accesses is the number of reads of the key.
+	Accesses uint64
+
+	// Expiration is the time the key expires at, zero if it never expires.
+	Expiration time.Time
+
+	// Size is the approximate size of the key and its value, as reported by
+	// KeyMemoryUsage.
+	Size int64
+}
+
+// ItemInspector is implemented by stores tracking the usage of their keys.
+type ItemInspector interface {
+	// ItemInfo returns the description of the given key, nil if it does not
+	// exist.
+	ItemInfo(key string) (*ItemInfo, error)
+}
+
+// itemMetadata is the usage of a key, updated atomically.
+type itemMetadata struct {
+	accesses uint64
+	accessed int64
+	updated  int64
+	created  int64
+}
+
+// itemTracker tracks the usage of the keys of a MemoryStore.
+type itemTracker struct {
+	items sync.Map // map[string]*itemMetadata
+	clock Clock
+}
+
+// written records a write of the given key.
+func (t *itemTracker) written(key string) {
+	now := t.clock.Now().UnixNano()
+
+	if m, ok := t.items.Load(key); ok {
+		atomic.StoreInt64(&m.(*itemMetadata).updated, now)
+		return
+	}
+
+	m, loaded := t.items.LoadOrStore(key, &itemMetadata{created: now, updated: now})
+	if loaded {
+		atomic.StoreInt64(&m.(*itemMetadata).updated, now)
+	}
+}
+
+// read records a read of the given key.
+func (t *itemTracker) read(key string) {
+	if m, ok := t.items.Load(key); ok {
+		atomic.AddUint64(&m.(*itemMetadata).accesses, 1)
+		atomic.StoreInt64(&m.(*itemMetadata).accessed, t.clock.Now().UnixNano())
+	}
+}
+
+// forget drops the usage of the given key, once removed.
+func (t *itemTracker) forget(key string) {
+	t.items.Delete(key)
+}
+
+// clear drops the usage of all the keys.
+func (t *itemTracker) clear() {
+	t.items.Range(func(key, _ interface{}) bool {
+		t.items.Delete(key)
+		return true
+	})
+}
+
+// info fills the usage of the given key.
+func (t *itemTracker) info(info *ItemInfo) {
+	m, ok := t.items.Load(info.Key)
+	if !ok {
+		return
+	}
+
+	meta := m.(*itemMetadata)
+	info.Created = time.Unix(0, meta.created)
+	info.Updated = time.Unix(0, atomic.LoadInt64(&meta.updated))
+	info.Accesses = atomic.LoadUint64(&meta.accesses)
+	if accessed := atomic.LoadInt64(&meta.accessed); accessed > 0 {
+		info.Accessed = time.Unix(0, accessed)
+	}
+}
+
+// ItemInfo returns the description of the given key, nil if it does not
+// exist. It returns ErrNotSupported unless the store was created with
+// TrackItems. Looking the key up is not counted as a read.
+func (c *MemoryStore) ItemInfo(key string) (*ItemInfo, error) {
+	if c.tracker == nil {
+		return nil, ErrNotSupported
+	}
+
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	value, expiration, found := c.shard(key).getWithExpiration(key)
+	if !found && c.spill != nil {
+		var item Item
+		item, found = c.spill.load(key)
+		value, expiration = item.Value, item.Expiration
+	}
+	if !found {
+		return nil, nil
+	}
+
+	info := &ItemInfo{
+		Key:        key,
+		Expiration: expiration,
+		Size:       int64(itemSize(key, value)),
+	}
+	c.tracker.info(info)
+
+	return info, nil
+}
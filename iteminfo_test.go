@@ -0,0 +1,61 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreItemInfo(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{TrackItems: true})
+	is.Nil(err)
+
+	inspector := store.(ItemInspector)
+
+	before := time.Now()
+	is.Nil(store.SetWithExpiration("key", "value", time.Minute))
+
+	info, err := inspector.ItemInfo("key")
+	is.Nil(err)
+	is.Equal("key", info.Key)
+	is.WithinDuration(before, info.Created, time.Second)
+	is.Equal(info.Created, info.Updated)
+	is.True(info.Accessed.IsZero())
+	is.Equal(uint64(0), info.Accesses)
+	is.WithinDuration(before.Add(time.Minute), info.Expiration, time.Second)
+	is.Equal(int64(len("key")+len("value")), info.Size)
+
+	for i := 0; i < 3; i++ {
+		_, err = store.Get("key")
+		is.Nil(err)
+	}
+
+	time.Sleep(time.Millisecond)
+	is.Nil(store.Set("key", "changed"))
+
+	info, err = inspector.ItemInfo("key")
+	is.Nil(err)
+	is.Equal(uint64(3), info.Accesses)
+	is.False(info.Accessed.IsZero())
+	is.True(info.Updated.After(info.Created))
+
+	is.Nil(store.Delete("key"))
+
+	info, err = inspector.ItemInfo("key")
+	is.Nil(err)
+	is.Nil(info)
+
+	is.Nil(store.Set("key", "value"))
+	info, err = inspector.ItemInfo("key")
+	is.Nil(err)
+	is.Equal(uint64(0), info.Accesses)
+
+	store, err = NewMemoryStoreWithOptions(nil)
+	is.Nil(err)
+
+	_, err = store.(ItemInspector).ItemInfo("key")
+	is.Equal(ErrNotSupported, err)
+}
@@ -0,0 +1,58 @@
+package gokvstores
+
+import (
+	"math/rand"
+	"time"
+)
+
+// JitterStore is a KVStore decorator randomizing expirations, so keys written
+// at the same moment don't all expire at once.
+type JitterStore struct {
+	KVStore
+
+	expiration time.Duration
+	percent    float64
+}
+
+// NewJitterStore returns a JitterStore wrapping the given store.
+//
+// Expirations are randomized by up to ± percent (e.g. 10 for ±10%).
+// Values written with Set expire after the given expiration, jittered as well;
+// a zero expiration leaves Set to the wrapped store expiration.
+func NewJitterStore(store KVStore, expiration time.Duration, percent float64) *JitterStore {
+	return &JitterStore{
+		KVStore:    store,
+		expiration: expiration,
+		percent:    percent,
+	}
+}
+
+// jitter returns the randomized expiration.
+func (s *JitterStore) jitter(expiration time.Duration) time.Duration {
+	if expiration <= 0 || s.percent <= 0 {
+		return expiration
+	}
+
+	delta := float64(expiration) * s.percent / 100 * (2*rand.Float64() - 1)
+
+	jittered := expiration + time.Duration(delta)
+	if jittered <= 0 {
+		return expiration
+	}
+
+	return jittered
+}
+
+// Set sets value for the given key with a jittered expiration.
+func (s *JitterStore) Set(key string, value interface{}) error {
+	if s.expiration <= 0 {
+		return s.KVStore.Set(key, value)
+	}
+
+	return s.KVStore.SetWithExpiration(key, value, s.jitter(s.expiration))
+}
+
+// SetWithExpiration sets value for the given key with the jittered expiration.
+func (s *JitterStore) SetWithExpiration(key string, value interface{}, expiration time.Duration) error {
+	return s.KVStore.SetWithExpiration(key, value, s.jitter(expiration))
+}
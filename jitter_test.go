@@ -0,0 +1,29 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJitterStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	store := NewJitterStore(memory, time.Minute, 10)
+
+	testStore(t, store)
+
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 100; i++ {
+		d := store.jitter(time.Minute)
+		is.True(d >= 54*time.Second && d <= 66*time.Second)
+		seen[d] = true
+	}
+	is.True(len(seen) > 1)
+
+	is.Equal(time.Duration(0), store.jitter(0))
+}
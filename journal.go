@@ -0,0 +1,164 @@
+package gokvstores
+
+import (
+	"encoding/gob"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// journalOp is the kind of change recorded by a journal entry.
+type journalOp uint8
+
+// Journal operations.
+const (
+	journalSet journalOp = iota
+	journalDelete
+	journalExpire
+	journalFlush
+)
+
+// journalEntry is a change of a MemoryStore, as written to its journal.
+type journalEntry struct {
+	Op         journalOp
+	Key        string
+	Value      interface{}
+	Expiration time.Time
+}
+
+// journal appends the changes of a MemoryStore to a file, as a gob stream, so
+// that they can be replayed after a crash.
+type journal struct {
+	sync    bool
+	onError func(err error)
+
+	mu   sync.Mutex
+	file *os.File
+	enc  *gob.Encoder
+}
+
+// openJournal replays the journal at path into the store, then rewrites it
+// with the items of the store, so that it does not grow across restarts, and
+// returns it opened for the next changes.
+func openJournal(path string, c *MemoryStore, sync bool, onError func(err error)) (*journal, error) {
+	if err := c.replayJournal(path); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return nil, err
+	}
+
+	j := &journal{sync: sync, onError: onError, file: f, enc: gob.NewEncoder(f)}
+
+	err = c.Scan(func(item Item) error {
+		if isInternalValue(item.Value) {
+			return nil
+		}
+		return j.enc.Encode(journalEntry{Op: journalSet, Key: item.Key, Value: item.Value, Expiration: item.Expiration})
+	})
+	if err == nil {
+		err = f.Sync()
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// append writes an entry, reporting the failures to onError.
+func (j *journal) append(entry journalEntry) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return
+	}
+
+	err := j.enc.Encode(entry)
+	if err == nil && j.sync {
+		err = j.file.Sync()
+	}
+
+	if err != nil && j.onError != nil {
+		j.onError(err)
+	}
+}
+
+// set records the value of the given key, unless it is not saved.
+func (j *journal) set(key string, value interface{}, expiration time.Time) {
+	value, found := plainValue(value, true)
+	if !found || isInternalValue(value) {
+		return
+	}
+
+	j.append(journalEntry{Op: journalSet, Key: key, Value: value, Expiration: expiration})
+}
+
+// close closes the file of the journal.
+func (j *journal) close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// replayJournal applies the changes recorded in the journal at path, if it
+// exists. An entry cut short by a crash ends the replay.
+func (c *MemoryStore) replayJournal(path string) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := gob.NewDecoder(f)
+	for {
+		var entry journalEntry
+		if err := dec.Decode(&entry); err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		ttl := c.remainingTTL(entry.Expiration)
+		expired := !entry.Expiration.IsZero() && ttl <= 0
+
+		switch entry.Op {
+		case journalSet:
+			if expired {
+				c.delete(entry.Key)
+			} else {
+				c.store(entry.Key, entry.Value, ttl)
+			}
+		case journalDelete:
+			c.delete(entry.Key)
+		case journalExpire:
+			if expired {
+				c.delete(entry.Key)
+			} else {
+				c.expire(entry.Key, ttl)
+			}
+		case journalFlush:
+			c.flush()
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package gokvstores
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreJournal(t *testing.T) {
+	is := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "journal")
+
+	clock := NewManualClock(time.Now())
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{JournalPath: path, Clock: clock})
+	is.Nil(err)
+
+	memory := store.(*MemoryStore)
+
+	is.Nil(store.Set("key", "value"))
+	is.Nil(store.SetMap("map", map[string]interface{}{"language": "go"}))
+	is.Nil(memory.SetMapValue("map", "version", "1.22"))
+	is.Nil(store.AppendSlice("slice", "a", "b"))
+	is.Nil(store.Set("deleted", "value"))
+	is.Nil(store.Delete("deleted"))
+	is.Nil(store.SetWithExpiration("expiring", "value", time.Hour))
+	is.Nil(memory.Expire("key", time.Hour))
+	is.Nil(store.SetWithExpiration("expired", "value", time.Millisecond))
+	_, err = memory.PFAdd("hll", "a")
+	is.Nil(err)
+	is.Nil(memory.RPush("list", "a"))
+	_, _, err = memory.BLPop(time.Second, "list")
+	is.Nil(err)
+
+	clock.Advance(5 * time.Millisecond)
+
+	// The store is not closed, as after a crash.
+	restored, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{JournalPath: path, Clock: clock})
+	is.Nil(err)
+
+	for key, expected := range map[string]interface{}{
+		"key":      "value",
+		"expiring": "value",
+		"deleted":  nil,
+		"expired":  nil,
+		"hll":      nil,
+		"list":     nil,
+	} {
+		value, err := restored.Get(key)
+		is.Nil(err)
+		is.Equal(expected, value, key)
+	}
+
+	m, err := restored.GetMap("map")
+	is.Nil(err)
+	is.Equal(map[string]interface{}{"language": "go", "version": "1.22"}, m)
+
+	slice, err := restored.GetSlice("slice")
+	is.Nil(err)
+	is.Equal([]interface{}{"a", "b"}, slice)
+
+	is.Nil(restored.(*MemoryStore).Scan(func(item Item) error {
+		if item.Key == "key" {
+			is.WithinDuration(time.Now().Add(time.Hour), item.Expiration, time.Minute)
+		}
+		return nil
+	}))
+
+	// A flush is replayed, and an entry cut short ends the replay.
+	is.Nil(restored.Flush())
+	is.Nil(restored.Set("new", "value"))
+	is.Nil(restored.Set("truncated", "value"))
+	is.Nil(restored.Close())
+
+	info, err := os.Stat(path)
+	is.Nil(err)
+	is.Nil(os.Truncate(path, info.Size()-3))
+
+	restored, err = NewMemoryStoreWithOptions(&MemoryStoreOptions{JournalPath: path})
+	is.Nil(err)
+	defer restored.Close()
+
+	stats, err := restored.(StatsProvider).Stats()
+	is.Nil(err)
+	is.Equal(int64(1), stats.Keys)
+
+	value, err := restored.Get("new")
+	is.Nil(err)
+	is.Equal("value", value)
+}
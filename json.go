@@ -0,0 +1,459 @@
+package gokvstores
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// JSONStore is implemented by stores holding JSON documents which can be
+// read and updated partially.
+//
+// Paths are a subset of JSONPath: "$" for the whole document, followed by
+// ".field" and "[index]" segments, e.g. "$.address.lines[0]".
+type JSONStore interface {
+	// SetJSON sets the document of the given key.
+	SetJSON(key string, value interface{}) error
+
+	// GetJSON returns the document of the given key, nil if it does not exist.
+	// Objects are returned as maps, arrays as slices and numbers as float64.
+	GetJSON(key string) (interface{}, error)
+
+	// JSONPath returns the value at the given path of the document of key, nil
+	// if the key or the path does not exist.
+	JSONPath(key, path string) (interface{}, error)
+
+	// SetJSONPath sets the value at the given path of the document of key.
+	// The parent of the path must exist, or ErrPathNotFound is returned.
+	SetJSONPath(key, path string, value interface{}) error
+}
+
+// jsonSegment is a segment of a JSON path: an object field, or an array
+// index if field is empty.
+type jsonSegment struct {
+	field string
+	index int
+}
+
+// parseJSONPath returns the segments of a JSON path.
+func parseJSONPath(path string) ([]jsonSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("gokvstores: invalid JSON path %q", path)
+	}
+
+	var segments []jsonSegment
+	for rest := path[1:]; rest != ""; {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[") + 1
+			if end == 0 {
+				end = len(rest)
+			}
+
+			if end == 1 {
+				return nil, fmt.Errorf("gokvstores: invalid JSON path %q", path)
+			}
+
+			segments = append(segments, jsonSegment{field: rest[1:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("gokvstores: invalid JSON path %q", path)
+			}
+
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("gokvstores: invalid JSON path %q", path)
+			}
+
+			segments = append(segments, jsonSegment{index: index})
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("gokvstores: invalid JSON path %q", path)
+		}
+	}
+
+	return segments, nil
+}
+
+// child returns the child of a decoded JSON value matching the segment.
+func (s jsonSegment) child(value interface{}) (interface{}, bool) {
+	if s.field != "" {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		child, ok := object[s.field]
+		return child, ok
+	}
+
+	array, ok := value.([]interface{})
+	if !ok || s.index >= len(array) {
+		return nil, false
+	}
+
+	return array[s.index], true
+}
+
+// jsonLookup returns the value at the path of a decoded document.
+func jsonLookup(doc interface{}, segments []jsonSegment) (interface{}, bool) {
+	value := doc
+	for _, segment := range segments {
+		var ok bool
+		if value, ok = segment.child(value); !ok {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// jsonUpdate returns the document with the value at the path replaced, or
+// added to its parent object.
+func jsonUpdate(doc interface{}, segments []jsonSegment, value interface{}) (interface{}, error) {
+	if len(segments) == 0 {
+		return value, nil
+	}
+
+	parent, ok := jsonLookup(doc, segments[:len(segments)-1])
+	if !ok {
+		return nil, ErrPathNotFound
+	}
+
+	last := segments[len(segments)-1]
+
+	if last.field != "" {
+		object, ok := parent.(map[string]interface{})
+		if !ok {
+			return nil, ErrPathNotFound
+		}
+		object[last.field] = value
+		return doc, nil
+	}
+
+	array, ok := parent.([]interface{})
+	if !ok || last.index >= len(array) {
+		return nil, ErrPathNotFound
+	}
+	array[last.index] = value
+
+	return doc, nil
+}
+
+// normalizeJSON returns the value as decoded from its JSON encoding.
+func normalizeJSON(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalized interface{}
+	err = json.Unmarshal(data, &normalized)
+	return normalized, err
+}
+
+// decodeJSON returns the document encoded in data.
+func decodeJSON(data string) (interface{}, error) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// ----------------------------------------------------------------------------
+// Memory
+// ----------------------------------------------------------------------------
+
+// SetJSON sets the document of the given key, stored as its JSON encoding.
+func (c *MemoryStore) SetJSON(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return c.Set(key, string(data))
+}
+
+// GetJSON returns the document of the given key, nil if it does not exist.
+func (c *MemoryStore) GetJSON(key string) (interface{}, error) {
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	return c.getJSON(key)
+}
+
+// getJSON returns the document of the given key, nil if it does not exist.
+func (c *MemoryStore) getJSON(key string) (interface{}, error) {
+	value, err := c.get(key)
+	if value == nil || err != nil {
+		return nil, err
+	}
+
+	data, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("gokvstores: %q is not a JSON document", key)
+	}
+
+	return decodeJSON(data)
+}
+
+// JSONPath returns the value at the given path of the document of key.
+func (c *MemoryStore) JSONPath(key, path string) (interface{}, error) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := c.GetJSON(key)
+	if doc == nil || err != nil {
+		return nil, err
+	}
+
+	value, _ := jsonLookup(doc, segments)
+	return value, nil
+}
+
+// SetJSONPath sets the value at the given path of the document of key. The
+// document is updated atomically.
+func (c *MemoryStore) SetJSONPath(key, path string, value interface{}) error {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return err
+	}
+
+	if len(segments) == 0 {
+		return c.SetJSON(key, value)
+	}
+
+	value, err = normalizeJSON(value)
+	if err != nil {
+		return err
+	}
+
+	c.txn.Lock()
+	defer c.txn.Unlock()
+
+	doc, err := c.getJSON(key)
+	if err != nil {
+		return err
+	}
+
+	if doc == nil {
+		return ErrPathNotFound
+	}
+
+	if doc, err = jsonUpdate(doc, segments, value); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	return c.set(key, string(data))
+}
+
+// ----------------------------------------------------------------------------
+// Redis
+// ----------------------------------------------------------------------------
+
+// SetJSON sets the document of the given key, with JSON.SET when the server
+// has the RedisJSON module, as a JSON string otherwise. Either way the store
+// expiration applies and the write waits for WaitReplicas.
+func (r *RedisStore) SetJSON(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	if moduleAvailable(&r.modules.json) {
+		if ok, err := r.setJSONDocument(key, string(data)); ok {
+			r.stats.write(err)
+			r.uncache(key)
+			return err
+		}
+	}
+
+	err = r.write(func(c redis.Cmdable) error {
+		return c.Set(r.ctx, key, string(data), r.expiration).Err()
+	})
+	r.uncache(key)
+	r.stats.write(err)
+	return err
+}
+
+// setJSONDocument sets the document of key with JSON.SET, then its
+// expiration as SET does, in a single pipeline followed by WAIT with
+// WaitReplicas. It reports false, without error, when the server does not
+// have the RedisJSON module.
+func (r *RedisStore) setJSONDocument(key, data string) (bool, error) {
+	set := redis.NewStatusCmd(r.ctx, "json.set", key, "$", data)
+
+	err := r.write(func(c redis.Cmdable) error {
+		// r.write hands a pipeline over when it waits for replicas.
+		pipe, queued := c.(redis.Pipeliner)
+		if !queued {
+			pipe = c.Pipeline()
+		}
+
+		pipe.Process(r.ctx, set)
+		if r.expiration > 0 {
+			pipe.PExpire(r.ctx, key, r.expiration)
+		} else {
+			pipe.Persist(r.ctx, key)
+		}
+
+		if queued {
+			return nil
+		}
+
+		_, err := pipe.Exec(r.ctx)
+		return err
+	})
+
+	if err := set.Err(); err != nil && strings.HasPrefix(err.Error(), "ERR unknown command") {
+		atomic.StoreInt32(&r.modules.json, 1)
+		return false, nil
+	}
+
+	return true, err
+}
+
+// GetJSON returns the document of the given key, nil if it does not exist.
+func (r *RedisStore) GetJSON(key string) (interface{}, error) {
+	return r.JSONPath(key, "$")
+}
+
+// JSONPath returns the value at the given path of the document of key, nil if
+// the key or the path does not exist. With RedisJSON, only the value is
+// transferred.
+func (r *RedisStore) JSONPath(key, path string) (value interface{}, err error) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { r.stats.read(value != nil, err) }()
+
+	if moduleAvailable(&r.modules.json) {
+		cmd := redis.NewStringCmd(r.ctx, "json.get", key, path)
+		if ok, err := r.moduleCommand(&r.modules.json, cmd); ok {
+			if err == redis.Nil {
+				return nil, nil
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			// JSONPath replies are the array of the matching values.
+			matches, err := decodeJSON(cmd.Val())
+			if values, _ := matches.([]interface{}); len(values) > 0 {
+				return values[0], err
+			}
+			return nil, err
+		}
+	}
+
+	data, err := r.client.Get(r.ctx, key).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	value, _ = jsonLookup(doc, segments)
+	return value, nil
+}
+
+// SetJSONPath sets the value at the given path of the document of key, with
+// JSON.SET when the server has the RedisJSON module. Otherwise the document
+// is read, updated and written back in a transaction.
+func (r *RedisStore) SetJSONPath(key, path string, value interface{}) error {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return err
+	}
+
+	if len(segments) == 0 {
+		return r.SetJSON(key, value)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	if moduleAvailable(&r.modules.json) {
+		ok, err := r.moduleCommand(&r.modules.json, redis.NewStatusCmd(r.ctx, "json.set", key, path, string(data)))
+		if ok {
+			// JSON.SET replies nil when the parent of the path doesn't exist,
+			// and fails when the key doesn't.
+			if err == redis.Nil || err != nil && strings.HasPrefix(err.Error(), "ERR new objects must be created at the root") {
+				err = ErrPathNotFound
+			}
+
+			r.stats.write(err)
+			r.uncache(key)
+			return err
+		}
+	}
+
+	if value, err = normalizeJSON(value); err != nil {
+		return err
+	}
+
+	err = r.client.Watch(r.ctx, func(tx *redis.Tx) error {
+		data, err := tx.Get(r.ctx, key).Result()
+		if err == redis.Nil {
+			return ErrPathNotFound
+		}
+
+		if err != nil {
+			return err
+		}
+
+		doc, err := decodeJSON(data)
+		if err != nil {
+			return err
+		}
+
+		if doc, err = jsonUpdate(doc, segments, value); err != nil {
+			return err
+		}
+
+		updated, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(r.ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(r.ctx, key, string(updated), redis.KeepTTL)
+			return nil
+		})
+		return err
+	}, key)
+
+	if err == redis.TxFailedErr {
+		err = ErrTxnConflict
+	}
+
+	r.uncache(key)
+	r.stats.write(err)
+	return err
+}
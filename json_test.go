@@ -0,0 +1,91 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	is := assert.New(t)
+
+	segments, err := parseJSONPath("$")
+	is.Nil(err)
+	is.Empty(segments)
+
+	segments, err = parseJSONPath("$.address.lines[1]")
+	is.Nil(err)
+	is.Equal([]jsonSegment{{field: "address"}, {field: "lines"}, {index: 1}}, segments)
+
+	for _, path := range []string{"", "address", "$.", "$[x]", "$[-1]", "$[0", "$x"} {
+		_, err = parseJSONPath(path)
+		is.NotNil(err, path)
+	}
+}
+
+func testJSONStore(t *testing.T, store KVStore) {
+	is := assert.New(t)
+
+	js := store.(JSONStore)
+
+	is.Nil(store.Delete("user"))
+
+	doc, err := js.GetJSON("user")
+	is.Nil(err)
+	is.Nil(doc)
+
+	is.Equal(ErrPathNotFound, js.SetJSONPath("user", "$.name", "Ada"))
+
+	is.Nil(js.SetJSON("user", map[string]interface{}{
+		"name":    "Ada",
+		"address": map[string]interface{}{"lines": []string{"12 Street", "London"}},
+	}))
+
+	value, err := js.JSONPath("user", "$.address.lines[1]")
+	is.Nil(err)
+	is.Equal("London", value)
+
+	value, err = js.JSONPath("user", "$.missing")
+	is.Nil(err)
+	is.Nil(value)
+
+	is.Nil(js.SetJSONPath("user", "$.address.lines[0]", "10 Street"))
+	is.Nil(js.SetJSONPath("user", "$.age", 36))
+	is.Equal(ErrPathNotFound, js.SetJSONPath("user", "$.job.title", "Countess"))
+
+	doc, err = js.GetJSON("user")
+	is.Nil(err)
+	is.Equal(map[string]interface{}{
+		"name":    "Ada",
+		"age":     float64(36),
+		"address": map[string]interface{}{"lines": []interface{}{"10 Street", "London"}},
+	}, doc)
+}
+
+func TestMemoryStoreJSON(t *testing.T) {
+	store, err := NewMemoryStore(time.Second*10, time.Second*10)
+	assert.Nil(t, err)
+
+	testJSONStore(t, store)
+}
+
+func TestRedisStoreJSONExpiration(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Minute)
+	is.Nil(err)
+
+	rs := store.(*RedisStore)
+
+	is.Nil(rs.SetJSON("document", map[string]interface{}{"name": "value"}))
+
+	ttl, err := rs.client.TTL(rs.ctx, "document").Result()
+	is.Nil(err)
+	is.True(ttl > 0 && ttl <= time.Minute)
+
+	is.Nil(store.Delete("document"))
+	is.Nil(store.Close())
+}
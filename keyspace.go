@@ -0,0 +1,155 @@
+package gokvstores
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyspaceBufferSize is the capacity of KeyspaceSubscription channels.
+const keyspaceBufferSize = 64
+
+// KeyspaceEvent is a Redis keyspace notification.
+type KeyspaceEvent struct {
+	// Event is the Redis event name (set, del, expired, hset...).
+	Event string
+
+	// Key is the key the event applies to.
+	Key string
+}
+
+// KeyspaceSubscription delivers Redis keyspace notifications.
+type KeyspaceSubscription struct {
+	pubsub *redis.PubSub
+	events chan KeyspaceEvent
+}
+
+// Events returns the channel notifications are delivered on. It is closed
+// once the subscription is closed.
+func (s *KeyspaceSubscription) Events() <-chan KeyspaceEvent {
+	return s.events
+}
+
+// Close ends the subscription.
+func (s *KeyspaceSubscription) Close() error {
+	return s.pubsub.Close()
+}
+
+// receive delivers notifications until the subscription is closed.
+func (s *KeyspaceSubscription) receive(prefix string) {
+	defer close(s.events)
+
+	for {
+		msg, err := s.pubsub.ReceiveMessage(context.Background())
+		if err != nil {
+			return
+		}
+
+		// A slow reader loses notifications instead of blocking the
+		// goroutine past Close.
+		select {
+		case s.events <- KeyspaceEvent{
+			Event: strings.TrimPrefix(msg.Channel, prefix),
+			Key:   msg.Payload,
+		}:
+		default:
+		}
+	}
+}
+
+// SubscribeKeyspace subscribes to the given keyspace events (e.g. "set", "del",
+// "expired") of the store database, or to all of them if none is given.
+//
+// The server must have keyspace notifications enabled, for instance with
+// notify-keyspace-events set to "KEA". With a cluster, notifications are only
+// received from the node the subscription is connected to. Notifications are
+// dropped while the Events channel is full.
+func (r *RedisStore) SubscribeKeyspace(events ...string) (*KeyspaceSubscription, error) {
+	if len(events) == 0 {
+		events = []string{"*"}
+	}
+
+	prefix := fmt.Sprintf("__keyevent@%d__:", r.db)
+
+	patterns := make([]string, len(events))
+	for i, event := range events {
+		patterns[i] = prefix + event
+	}
+
+	pubsub := r.client.PSubscribe(r.ctx, patterns...)
+	if _, err := pubsub.Receive(r.ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	s := &KeyspaceSubscription{
+		pubsub: pubsub,
+		events: make(chan KeyspaceEvent, keyspaceBufferSize),
+	}
+
+	go s.receive(prefix)
+
+	return s, nil
+}
+
+// keyspaceChanges maps keyspace events to change types. Other events, except
+// those only altering expirations, are reported as ChangeSet.
+var keyspaceChanges = map[string]ChangeType{
+	"del":         ChangeDelete,
+	"rename_from": ChangeDelete,
+	"expired":     ChangeExpire,
+	"evicted":     ChangeEvict,
+}
+
+// globEscaper escapes the Redis glob special characters.
+var globEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
+// Watch returns a KeyWatch receiving the changes of the keys starting with
+// keyOrPrefix, using keyspace notifications. The server must have them
+// enabled, as for SubscribeKeyspace. Changes are dropped while the Events
+// channel is full.
+func (r *RedisStore) Watch(keyOrPrefix string) (*KeyWatch, error) {
+	prefix := fmt.Sprintf("__keyspace@%d__:", r.db)
+
+	pubsub := r.client.PSubscribe(r.ctx, prefix+globEscaper.Replace(keyOrPrefix)+"*")
+	if _, err := pubsub.Receive(r.ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	w := &KeyWatch{
+		events: make(chan ChangeEvent, watchBufferSize),
+		stop:   pubsub.Close,
+	}
+
+	go func() {
+		defer close(w.events)
+
+		for {
+			msg, err := pubsub.ReceiveMessage(context.Background())
+			if err != nil {
+				return
+			}
+
+			if msg.Payload == "expire" || msg.Payload == "persist" {
+				continue
+			}
+
+			change, ok := keyspaceChanges[msg.Payload]
+			if !ok {
+				change = ChangeSet
+			}
+
+			// As with MemoryStore watches, a slow reader loses changes
+			// instead of blocking the goroutine past Close.
+			select {
+			case w.events <- ChangeEvent{Type: change, Key: strings.TrimPrefix(msg.Channel, prefix)}:
+			default:
+			}
+		}
+	}()
+
+	return w, nil
+}
@@ -0,0 +1,82 @@
+package gokvstores
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// KeyRules are the rules enforced by a KeyValidationStore.
+// Empty keys are always rejected.
+type KeyRules struct {
+	// MaxLength is the maximum length of keys, in bytes. Zero means no limit.
+	MaxLength int
+
+	// Charset lists the characters allowed in keys. Empty allows any character.
+	Charset string
+
+	// Forbidden are patterns keys must not match.
+	Forbidden []*regexp.Regexp
+}
+
+// Validate returns an InvalidKeyError if the given key breaks the rules.
+func (r *KeyRules) Validate(key string) error {
+	if key == "" {
+		return &InvalidKeyError{Key: key, Reason: "key is empty"}
+	}
+
+	if r.MaxLength > 0 && len(key) > r.MaxLength {
+		return &InvalidKeyError{Key: key, Reason: fmt.Sprintf("key is longer than %d bytes", r.MaxLength)}
+	}
+
+	if r.Charset != "" {
+		for _, c := range key {
+			if !strings.ContainsRune(r.Charset, c) {
+				return &InvalidKeyError{Key: key, Reason: fmt.Sprintf("character %q is not allowed", c)}
+			}
+		}
+	}
+
+	for _, pattern := range r.Forbidden {
+		if pattern.MatchString(key) {
+			return &InvalidKeyError{Key: key, Reason: fmt.Sprintf("key matches forbidden pattern %q", pattern)}
+		}
+	}
+
+	return nil
+}
+
+// KeyValidationStore is a KVStore decorator rejecting keys breaking the given
+// rules with an InvalidKeyError, before they reach the wrapped store.
+type KeyValidationStore struct {
+	*interceptedStore
+
+	rules KeyRules
+}
+
+// NewKeyValidationStore returns a KeyValidationStore wrapping the given store.
+// Nil rules only reject empty keys.
+func NewKeyValidationStore(store KVStore, rules *KeyRules) *KeyValidationStore {
+	if rules == nil {
+		rules = &KeyRules{}
+	}
+
+	v := &KeyValidationStore{rules: *rules}
+	v.interceptedStore = &interceptedStore{store: store, intercept: v.validate}
+
+	return v
+}
+
+// validate checks the key of the operation.
+func (v *KeyValidationStore) validate(op *operation, next func() error) error {
+	switch op.name {
+	case "Flush", "Close", "Ping":
+		return next()
+	}
+
+	if err := v.rules.Validate(op.key); err != nil {
+		return err
+	}
+
+	return next()
+}
@@ -0,0 +1,46 @@
+package gokvstores
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyValidationStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	store := NewKeyValidationStore(memory, &KeyRules{
+		MaxLength: 16,
+		Charset:   "abcdefghijklmnopqrstuvwxyz0123456789:",
+		Forbidden: []*regexp.Regexp{regexp.MustCompile(`::`)},
+	})
+
+	testStore(t, store)
+
+	for key, valid := range map[string]bool{
+		"users:1":                   true,
+		"":                          false,
+		"users:1:profile:avatar:xl": false,
+		"users:<nil>":               false,
+		"users::1":                  false,
+	} {
+		err := store.Set(key, "value")
+		if valid {
+			is.Nil(err, key)
+			continue
+		}
+
+		_, ok := err.(*InvalidKeyError)
+		is.True(ok, key)
+	}
+
+	// Without rules, only empty keys are rejected.
+	store = NewKeyValidationStore(memory, nil)
+	is.Nil(store.Set("users::<nil>", "value"))
+	is.Equal(&InvalidKeyError{Key: "", Reason: "key is empty"}, store.Set("", "value"))
+}
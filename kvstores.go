@@ -2,10 +2,18 @@ package gokvstores
 
 import (
 	"sort"
+	"time"
 
 	conv "github.com/cstockton/go-conv"
 )
 
+// NoExpiration is the expiration of values which never expire.
+const NoExpiration time.Duration = -1
+
+// ReservedPrefix prefixes the keys decorators keep their own data in, such
+// as versions and tombstones. Decorators implementing Scanner skip them.
+const ReservedPrefix = "gokvstores:"
+
 // KVStore is the KV store interface.
 type KVStore interface {
 	// Get returns value for the given key.
@@ -14,6 +22,11 @@ type KVStore interface {
 	// Set sets value for the given key.
 	Set(key string, value interface{}) error
 
+	// SetWithExpiration sets value for the given key with a specific expiration.
+	// A zero or negative expiration, such as NoExpiration, means the value
+	// never expires.
+	SetWithExpiration(key string, value interface{}, expiration time.Duration) error
+
 	// GetMap returns map for the given key.
 	GetMap(key string) (map[string]interface{}, error)
 
@@ -41,6 +54,9 @@ type KVStore interface {
 
 	// Close closes the connection to the store.
 	Close() error
+
+	// Ping checks the store is reachable.
+	Ping() error
 }
 
 func stringSlice(values []interface{}) []string {
@@ -56,3 +72,58 @@ func stringSlice(values []interface{}) []string {
 
 	return converted
 }
+
+// itemSize returns the approximate size in bytes of a key and its value.
+func itemSize(key string, value interface{}) int {
+	return len(key) + valueSize(value)
+}
+
+// valueSize returns the approximate size in bytes of a value once stringified.
+func valueSize(value interface{}) int {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case string:
+		return len(v)
+	case []byte:
+		return len(v)
+	case *hyperLogLog:
+		return hllRegisters
+	case *fieldMap:
+		return valueSize(v.values())
+	case *bloomFilter:
+		return v.size()
+	case *cuckooFilter:
+		v.mu.RLock()
+		defer v.mu.RUnlock()
+
+		size := 0
+		for item := range v.items {
+			size += len(item) + 8
+		}
+		return size
+	case *geoSet:
+		v.mu.RLock()
+		defer v.mu.RUnlock()
+
+		size := 0
+		for name := range v.locations {
+			size += len(name) + 16
+		}
+		return size
+	case map[string]interface{}:
+		size := 0
+		for k, item := range v {
+			size += len(k) + valueSize(item)
+		}
+		return size
+	case []interface{}:
+		size := 0
+		for _, item := range v {
+			size += valueSize(item)
+		}
+		return size
+	default:
+		return len(conv.String(v))
+	}
+}
@@ -3,6 +3,7 @@ package gokvstores
 import (
 	"sort"
 	"testing"
+	"time"
 
 	conv "github.com/cstockton/go-conv"
 	"github.com/stretchr/testify/assert"
@@ -11,7 +12,10 @@ import (
 func testStore(t *testing.T, store KVStore) {
 	is := assert.New(t)
 
-	err := store.Flush()
+	err := store.Ping()
+	is.Nil(err)
+
+	err = store.Flush()
 	is.Nil(err)
 
 	// Set
@@ -42,6 +46,18 @@ func testStore(t *testing.T, store KVStore) {
 	is.Nil(err)
 	is.False(exists)
 
+	// Expiration
+
+	err = store.SetWithExpiration("key", "value", time.Second*10)
+	is.Nil(err)
+
+	v, err = store.Get("key")
+	is.Nil(err)
+	is.Equal("value", conv.String(v))
+
+	err = store.Delete("key")
+	is.Nil(err)
+
 	// Map
 
 	mapResults := map[string]map[string]interface{}{
@@ -114,3 +130,55 @@ func testStore(t *testing.T, store KVStore) {
 		is.False(exists)
 	}
 }
+
+// testExpireMany tests the BatchExpirer of the store, wait letting the time
+// of the store pass.
+func testExpireMany(t *testing.T, store KVStore, wait func(d time.Duration)) {
+	is := assert.New(t)
+
+	is.Nil(store.Set("expiring", "a"))
+	is.Nil(store.SetWithExpiration("persisted", "b", 50*time.Millisecond))
+	is.Nil(store.Set("untouched", "c"))
+
+	is.Nil(store.(BatchExpirer).ExpireMany(map[string]time.Duration{
+		"expiring":  50 * time.Millisecond,
+		"persisted": 0,
+		"missing":   time.Minute,
+	}))
+
+	wait(100 * time.Millisecond)
+
+	for key, expected := range map[string]bool{"expiring": false, "persisted": true, "untouched": true, "missing": false} {
+		exists, err := store.Exists(key)
+		is.Nil(err)
+		is.Equal(expected, exists, key)
+	}
+}
+
+// testRefresher tests the Refresher of the store, wait letting the time of the
+// store pass.
+func testRefresher(t *testing.T, store KVStore, wait func(d time.Duration)) {
+	is := assert.New(t)
+
+	rs := store.(Refresher)
+
+	is.Nil(store.SetWithExpiration("session", "ada", 100*time.Millisecond))
+
+	for i := 0; i < 3; i++ {
+		wait(50 * time.Millisecond)
+
+		value, err := rs.GetAndRefresh("session", 100*time.Millisecond)
+		is.Nil(err)
+		is.Equal("ada", value)
+	}
+
+	value, err := rs.GetAndRefresh("missing", time.Minute)
+	is.Nil(err)
+	is.Nil(value)
+
+	wait(150 * time.Millisecond)
+
+	exists, err := store.Exists("session")
+	is.Nil(err)
+	is.False(exists)
+}
@@ -0,0 +1,185 @@
+// Package kvstoretest provides a conformance test suite for KVStore
+// implementations.
+package kvstoretest
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/louiseGrandjonc/gokvstores"
+	"github.com/stretchr/testify/assert"
+)
+
+// sortedStrings returns the sorted string representations of the given values,
+// as backends may not preserve slice order nor value types.
+func sortedStrings(values []interface{}) []string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = fmt.Sprint(v)
+	}
+	sort.Strings(s)
+	return s
+}
+
+// RunConformanceTests runs the KVStore contract tests against the stores
+// returned by newStore, which is called for each test and must return an
+// empty store. Stores are closed at the end of each test.
+//
+// Values may be read back as strings: the tests compare their string
+// representations.
+func RunConformanceTests(t *testing.T, newStore func() gokvstores.KVStore) {
+	tests := []struct {
+		name string
+		test func(is *assert.Assertions, store gokvstores.KVStore)
+	}{
+		{"Ping", testPing},
+		{"GetSet", testGetSet},
+		{"Exists", testExists},
+		{"Delete", testDelete},
+		{"Expiration", testExpiration},
+		{"Map", testMap},
+		{"Slice", testSlice},
+		{"AppendSlice", testAppendSlice},
+		{"Flush", testFlush},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			store := newStore()
+			defer store.Close()
+
+			tt.test(assert.New(t), store)
+		})
+	}
+}
+
+func testPing(is *assert.Assertions, store gokvstores.KVStore) {
+	is.Nil(store.Ping())
+}
+
+func testGetSet(is *assert.Assertions, store gokvstores.KVStore) {
+	v, err := store.Get("key")
+	is.Nil(err)
+	is.Nil(v, "Get of a missing key must return nil")
+
+	is.Nil(store.Set("key", "value"))
+
+	v, err = store.Get("key")
+	is.Nil(err)
+	is.Equal("value", fmt.Sprint(v))
+
+	is.Nil(store.Set("key", "changed"))
+
+	v, err = store.Get("key")
+	is.Nil(err)
+	is.Equal("changed", fmt.Sprint(v), "Set must overwrite the existing value")
+}
+
+func testExists(is *assert.Assertions, store gokvstores.KVStore) {
+	exists, err := store.Exists("key")
+	is.Nil(err)
+	is.False(exists)
+
+	is.Nil(store.Set("key", "value"))
+	is.Nil(store.SetMap("map", map[string]interface{}{"language": "go"}))
+	is.Nil(store.SetSlice("slice", []interface{}{"a"}))
+
+	for _, key := range []string{"key", "map", "slice"} {
+		exists, err = store.Exists(key)
+		is.Nil(err)
+		is.True(exists, key+" must exist")
+	}
+}
+
+func testDelete(is *assert.Assertions, store gokvstores.KVStore) {
+	is.Nil(store.Delete("unknown"), "deleting a missing key must not fail")
+
+	is.Nil(store.Set("key", "value"))
+	is.Nil(store.Delete("key"))
+
+	v, err := store.Get("key")
+	is.Nil(err)
+	is.Nil(v)
+
+	exists, err := store.Exists("key")
+	is.Nil(err)
+	is.False(exists)
+}
+
+func testExpiration(is *assert.Assertions, store gokvstores.KVStore) {
+	is.Nil(store.SetWithExpiration("short", "value", 50*time.Millisecond))
+	is.Nil(store.SetWithExpiration("forever", "value", 0))
+
+	v, err := store.Get("short")
+	is.Nil(err)
+	is.Equal("value", fmt.Sprint(v))
+
+	is.Eventually(func() bool {
+		v, err := store.Get("short")
+		return err == nil && v == nil
+	}, 5*time.Second, 10*time.Millisecond, "expired keys must not be returned")
+
+	exists, err := store.Exists("short")
+	is.Nil(err)
+	is.False(exists)
+
+	v, err = store.Get("forever")
+	is.Nil(err)
+	is.Equal("value", fmt.Sprint(v), "a zero expiration must never expire")
+}
+
+func testMap(is *assert.Assertions, store gokvstores.KVStore) {
+	m, err := store.GetMap("map")
+	is.Nil(err)
+	is.Nil(m, "GetMap of a missing key must return nil")
+
+	is.Nil(store.SetMap("map", map[string]interface{}{"language": "go", "integer": "1"}))
+
+	m, err = store.GetMap("map")
+	is.Nil(err)
+	is.Len(m, 2)
+	is.Equal("go", fmt.Sprint(m["language"]))
+	is.Equal("1", fmt.Sprint(m["integer"]))
+}
+
+func testSlice(is *assert.Assertions, store gokvstores.KVStore) {
+	s, err := store.GetSlice("slice")
+	is.Nil(err)
+	is.Nil(s, "GetSlice of a missing key must return nil")
+
+	is.Nil(store.SetSlice("slice", []interface{}{"one", "two", "three"}))
+
+	s, err = store.GetSlice("slice")
+	is.Nil(err)
+	is.Equal([]string{"one", "three", "two"}, sortedStrings(s))
+}
+
+func testAppendSlice(is *assert.Assertions, store gokvstores.KVStore) {
+	is.Nil(store.AppendSlice("slice", "one"), "AppendSlice must create missing slices")
+
+	s, err := store.GetSlice("slice")
+	is.Nil(err)
+	is.Equal([]string{"one"}, sortedStrings(s))
+
+	is.Nil(store.AppendSlice("slice", "two", "three"))
+
+	s, err = store.GetSlice("slice")
+	is.Nil(err)
+	is.Equal([]string{"one", "three", "two"}, sortedStrings(s))
+}
+
+func testFlush(is *assert.Assertions, store gokvstores.KVStore) {
+	is.Nil(store.Set("key", "value"))
+	is.Nil(store.SetMap("map", map[string]interface{}{"language": "go"}))
+
+	is.Nil(store.Flush())
+
+	for _, key := range []string{"key", "map"} {
+		exists, err := store.Exists(key)
+		is.Nil(err)
+		is.False(exists, key+" must be flushed")
+	}
+}
@@ -0,0 +1,35 @@
+package kvstoretest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/louiseGrandjonc/gokvstores"
+)
+
+func TestMemoryStore(t *testing.T) {
+	RunConformanceTests(t, func() gokvstores.KVStore {
+		store, err := gokvstores.NewMemoryStore(time.Second*10, time.Second*10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return store
+	})
+}
+
+func TestRedisStore(t *testing.T) {
+	RunConformanceTests(t, func() gokvstores.KVStore {
+		store, err := gokvstores.NewRedisClientStore(&gokvstores.RedisClientOptions{
+			Addr: "localhost:6379",
+		}, time.Second*30)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := store.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		return store
+	})
+}
@@ -0,0 +1,190 @@
+package kvstoretest
+
+import (
+	"sync"
+	"time"
+)
+
+// Call is a call recorded by a MockStore.
+type Call struct {
+	// Method is the KVStore method name (Get, SetMap...).
+	Method string
+
+	// Key is the key of the call, empty for store-wide calls.
+	Key string
+
+	// Value is the value written, the values appended for AppendSlice.
+	Value interface{}
+
+	// Expiration is the expiration given to SetWithExpiration.
+	Expiration time.Duration
+}
+
+// MockStore is a KVStore recording its calls and returning programmed
+// responses. It holds no data: reads return what Returns programmed, nil
+// (or false for Exists) otherwise, and writes succeed without effect unless
+// Fails programmed an error.
+type MockStore struct {
+	mu        sync.Mutex
+	calls     []Call
+	responses map[string]map[string]interface{}
+	errors    map[string]error
+}
+
+// NewMockStore returns an empty MockStore.
+func NewMockStore() *MockStore {
+	return &MockStore{
+		responses: map[string]map[string]interface{}{},
+		errors:    map[string]error{},
+	}
+}
+
+// Returns programs the value returned by the given read method (Get, GetMap,
+// GetSlice or Exists) for key.
+func (m *MockStore) Returns(method, key string, value interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.responses[method] == nil {
+		m.responses[method] = map[string]interface{}{}
+	}
+	m.responses[method][key] = value
+}
+
+// Fails programs the error returned by the given method. A nil error removes it.
+func (m *MockStore) Fails(method string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err == nil {
+		delete(m.errors, method)
+		return
+	}
+	m.errors[method] = err
+}
+
+// Calls returns the recorded calls, in order.
+func (m *MockStore) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo returns the recorded calls to the given method, in order.
+func (m *MockStore) CallsTo(method string) []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	calls := []Call{}
+	for _, call := range m.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset forgets the recorded calls and programmed responses.
+func (m *MockStore) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = nil
+	m.responses = map[string]map[string]interface{}{}
+	m.errors = map[string]error{}
+}
+
+// call records the call and returns its programmed response.
+func (m *MockStore) call(call Call) (interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, call)
+
+	if err, ok := m.errors[call.Method]; ok {
+		return nil, err
+	}
+
+	return m.responses[call.Method][call.Key], nil
+}
+
+// Get returns the programmed value for the given key.
+func (m *MockStore) Get(key string) (interface{}, error) {
+	return m.call(Call{Method: "Get", Key: key})
+}
+
+// Set records the call.
+func (m *MockStore) Set(key string, value interface{}) error {
+	_, err := m.call(Call{Method: "Set", Key: key, Value: value})
+	return err
+}
+
+// SetWithExpiration records the call.
+func (m *MockStore) SetWithExpiration(key string, value interface{}, expiration time.Duration) error {
+	_, err := m.call(Call{Method: "SetWithExpiration", Key: key, Value: value, Expiration: expiration})
+	return err
+}
+
+// GetMap returns the programmed map for the given key.
+func (m *MockStore) GetMap(key string) (map[string]interface{}, error) {
+	value, err := m.call(Call{Method: "GetMap", Key: key})
+	result, _ := value.(map[string]interface{})
+	return result, err
+}
+
+// SetMap records the call.
+func (m *MockStore) SetMap(key string, value map[string]interface{}) error {
+	_, err := m.call(Call{Method: "SetMap", Key: key, Value: value})
+	return err
+}
+
+// GetSlice returns the programmed slice for the given key.
+func (m *MockStore) GetSlice(key string) ([]interface{}, error) {
+	value, err := m.call(Call{Method: "GetSlice", Key: key})
+	result, _ := value.([]interface{})
+	return result, err
+}
+
+// SetSlice records the call.
+func (m *MockStore) SetSlice(key string, value []interface{}) error {
+	_, err := m.call(Call{Method: "SetSlice", Key: key, Value: value})
+	return err
+}
+
+// AppendSlice records the call.
+func (m *MockStore) AppendSlice(key string, values ...interface{}) error {
+	_, err := m.call(Call{Method: "AppendSlice", Key: key, Value: values})
+	return err
+}
+
+// Exists returns the programmed existence of the given key.
+func (m *MockStore) Exists(key string) (bool, error) {
+	value, err := m.call(Call{Method: "Exists", Key: key})
+	exists, _ := value.(bool)
+	return exists, err
+}
+
+// Delete records the call.
+func (m *MockStore) Delete(key string) error {
+	_, err := m.call(Call{Method: "Delete", Key: key})
+	return err
+}
+
+// Flush records the call.
+func (m *MockStore) Flush() error {
+	_, err := m.call(Call{Method: "Flush"})
+	return err
+}
+
+// Close records the call.
+func (m *MockStore) Close() error {
+	_, err := m.call(Call{Method: "Close"})
+	return err
+}
+
+// Ping records the call.
+func (m *MockStore) Ping() error {
+	_, err := m.call(Call{Method: "Ping"})
+	return err
+}
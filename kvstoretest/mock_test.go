@@ -0,0 +1,65 @@
+package kvstoretest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/louiseGrandjonc/gokvstores"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ gokvstores.KVStore = (*MockStore)(nil)
+
+func TestMockStore(t *testing.T) {
+	is := assert.New(t)
+
+	store := NewMockStore()
+
+	store.Returns("Get", "key", "value")
+	store.Returns("GetMap", "map", map[string]interface{}{"language": "go"})
+	store.Returns("Exists", "key", true)
+
+	v, err := store.Get("key")
+	is.Nil(err)
+	is.Equal("value", v)
+
+	v, err = store.Get("unknown")
+	is.Nil(err)
+	is.Nil(v)
+
+	m, err := store.GetMap("map")
+	is.Nil(err)
+	is.Equal(map[string]interface{}{"language": "go"}, m)
+
+	exists, err := store.Exists("key")
+	is.Nil(err)
+	is.True(exists)
+
+	is.Nil(store.SetWithExpiration("key", "value", time.Minute))
+	is.Nil(store.AppendSlice("slice", "a", "b"))
+
+	is.Equal([]Call{
+		{Method: "SetWithExpiration", Key: "key", Value: "value", Expiration: time.Minute},
+	}, store.CallsTo("SetWithExpiration"))
+	is.Equal(Call{Method: "AppendSlice", Key: "slice", Value: []interface{}{"a", "b"}}, store.CallsTo("AppendSlice")[0])
+	is.Len(store.Calls(), 6)
+
+	// Errors
+
+	failure := errors.New("failure")
+	store.Fails("Set", failure)
+
+	is.Equal(failure, store.Set("key", "value"))
+	is.Nil(store.Delete("key"))
+
+	store.Fails("Set", nil)
+	is.Nil(store.Set("key", "value"))
+
+	store.Reset()
+	is.Empty(store.Calls())
+
+	v, err = store.Get("key")
+	is.Nil(err)
+	is.Nil(v)
+}
@@ -0,0 +1,265 @@
+package gokvstores
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ListStore is implemented by stores able to hold lists, which consumers can
+// wait on for values.
+type ListStore interface {
+	// LPush inserts the values at the head of the list of the given key,
+	// creating it if needed.
+	LPush(key string, values ...interface{}) error
+
+	// RPush appends the values to the list of the given key, creating it if
+	// needed.
+	RPush(key string, values ...interface{}) error
+
+	// BLPop removes and returns the first value of the first non-empty list
+	// of the keys, and its key, waiting for at most timeout, or forever if
+	// zero, until one is pushed. It returns an empty key once timed out.
+	BLPop(timeout time.Duration, keys ...string) (string, interface{}, error)
+
+	// BRPop is BLPop, removing the last value of the list.
+	BRPop(timeout time.Duration, keys ...string) (string, interface{}, error)
+}
+
+// broadcast wakes up all the goroutines waiting for a change, as a
+// sync.Cond which can be waited on with a timeout.
+type broadcast struct {
+	mu sync.Mutex
+	ch chan struct{}
+}
+
+// wait returns a channel closed by the next call to notify.
+func (b *broadcast) wait() <-chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.ch == nil {
+		b.ch = make(chan struct{})
+	}
+	return b.ch
+}
+
+// notify wakes up the waiting goroutines.
+func (b *broadcast) notify() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.ch != nil {
+		close(b.ch)
+		b.ch = nil
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Memory
+// ----------------------------------------------------------------------------
+
+// list returns the list of the given key, and its expiration.
+func (c *MemoryStore) list(key string) ([]interface{}, time.Time, error) {
+	v, expiration, found := c.lookupWithExpiration(key)
+	if !found {
+		return nil, expiration, nil
+	}
+
+	values, ok := v.([]interface{})
+	if !ok {
+		return nil, expiration, fmt.Errorf("gokvstores: %q is not a list", key)
+	}
+
+	return values, expiration, nil
+}
+
+// push adds the values to the head, or the tail, of the list of the given
+// key. The list is copied, as readers may hold it.
+func (c *MemoryStore) push(key string, head bool, values []interface{}) error {
+	c.txn.Lock()
+	defer c.txn.Unlock()
+
+	current, expiration, err := c.list(key)
+	c.stats.write(err)
+	if err != nil {
+		return err
+	}
+
+	items := make([]interface{}, 0, len(current)+len(values))
+	if head {
+		for i := len(values) - 1; i >= 0; i-- {
+			items = append(items, values[i])
+		}
+		items = append(items, current...)
+	} else {
+		items = append(append(items, current...), values...)
+	}
+
+	ttl := c.expiration
+	if current != nil {
+		ttl = c.remainingTTL(expiration)
+	}
+
+	c.store(key, items, ttl)
+	c.watches.notify(ChangeSet, key)
+	c.pushed.notify()
+	return nil
+}
+
+// pop removes the first, or last, value of the first non-empty list of the
+// keys. Emptied lists are deleted.
+func (c *MemoryStore) pop(keys []string, head bool) (string, interface{}, bool, error) {
+	c.txn.Lock()
+	defer c.txn.Unlock()
+
+	for _, key := range keys {
+		current, expiration, err := c.list(key)
+		if err != nil {
+			return "", nil, false, err
+		}
+
+		if len(current) == 0 {
+			continue
+		}
+
+		value, rest := current[0], current[1:]
+		if !head {
+			value, rest = current[len(current)-1], current[:len(current)-1]
+		}
+
+		if len(rest) == 0 {
+			c.delete(key)
+		} else {
+			c.store(key, append([]interface{}(nil), rest...), c.remainingTTL(expiration))
+			c.watches.notify(ChangeSet, key)
+		}
+
+		return key, value, true, nil
+	}
+
+	return "", nil, false, nil
+}
+
+// blockingPop pops a value, waiting for pushes until timeout.
+func (c *MemoryStore) blockingPop(timeout time.Duration, keys []string, head bool) (string, interface{}, error) {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		// Waiting before popping, not to miss the pushes in between.
+		pushed := c.pushed.wait()
+
+		key, value, found, err := c.pop(keys, head)
+		if found || err != nil {
+			c.stats.read(found, err)
+			return key, value, err
+		}
+
+		select {
+		case <-pushed:
+		case <-expired:
+			c.stats.read(false, nil)
+			return "", nil, nil
+		}
+	}
+}
+
+// LPush inserts the values at the head of the list of the given key.
+func (c *MemoryStore) LPush(key string, values ...interface{}) error {
+	return c.push(key, true, values)
+}
+
+// RPush appends the values to the list of the given key.
+func (c *MemoryStore) RPush(key string, values ...interface{}) error {
+	return c.push(key, false, values)
+}
+
+// BLPop removes and returns the first value of the first non-empty list of
+// the keys, waiting for at most timeout until one is pushed.
+func (c *MemoryStore) BLPop(timeout time.Duration, keys ...string) (string, interface{}, error) {
+	return c.blockingPop(timeout, keys, true)
+}
+
+// BRPop removes and returns the last value of the first non-empty list of
+// the keys, waiting for at most timeout until one is pushed.
+func (c *MemoryStore) BRPop(timeout time.Duration, keys ...string) (string, interface{}, error) {
+	return c.blockingPop(timeout, keys, false)
+}
+
+// ----------------------------------------------------------------------------
+// Redis
+// ----------------------------------------------------------------------------
+
+// LPush inserts the values at the head of the list of the given key with
+// LPUSH.
+func (r *RedisStore) LPush(key string, values ...interface{}) error {
+	return r.push(key, true, values)
+}
+
+// RPush appends the values to the list of the given key with RPUSH.
+func (r *RedisStore) RPush(key string, values ...interface{}) error {
+	return r.push(key, false, values)
+}
+
+// push encodes the values and sends them with LPUSH, or RPUSH.
+func (r *RedisStore) push(key string, head bool, values []interface{}) (err error) {
+	defer func() { r.stats.write(err) }()
+
+	encoded := make([]interface{}, len(values))
+	for i, value := range values {
+		if encoded[i], err = r.encode(value); err != nil {
+			return err
+		}
+	}
+
+	return r.write(func(c redis.Cmdable) error {
+		if head {
+			return c.LPush(r.ctx, key, encoded...).Err()
+		}
+		return c.RPush(r.ctx, key, encoded...).Err()
+	})
+}
+
+// BLPop removes and returns the first value of the first non-empty list of
+// the keys with BLPOP. The keys of a cluster must be in the same slot.
+func (r *RedisStore) BLPop(timeout time.Duration, keys ...string) (string, interface{}, error) {
+	return r.blockingPop(r.client.BLPop(r.ctx, timeout, keys...))
+}
+
+// BRPop removes and returns the last value of the first non-empty list of
+// the keys with BRPOP. The keys of a cluster must be in the same slot.
+func (r *RedisStore) BRPop(timeout time.Duration, keys ...string) (string, interface{}, error) {
+	return r.blockingPop(r.client.BRPop(r.ctx, timeout, keys...))
+}
+
+// blockingPop returns the key and the value read by a BLPOP or BRPOP.
+func (r *RedisStore) blockingPop(cmd *redis.StringSliceCmd) (key string, value interface{}, err error) {
+	defer func() { r.stats.read(key != "", err) }()
+
+	reply, err := cmd.Result()
+	if err == redis.Nil {
+		return "", nil, nil
+	}
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(reply) != 2 {
+		return "", nil, fmt.Errorf("gokvstores: unexpected pop reply %v", reply)
+	}
+
+	value, err = r.decode(reply[1])
+	if err != nil {
+		return "", nil, err
+	}
+
+	return reply[0], value, nil
+}
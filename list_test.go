@@ -0,0 +1,58 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testListStore(t *testing.T, store KVStore) {
+	is := assert.New(t)
+
+	ls := store.(ListStore)
+
+	is.Nil(store.Delete("jobs"))
+	is.Nil(store.Delete("urgent"))
+
+	key, value, err := ls.BLPop(50*time.Millisecond, "urgent", "jobs")
+	is.Nil(err)
+	is.Equal("", key)
+	is.Nil(value)
+
+	is.Nil(ls.RPush("jobs", "b", "c"))
+	is.Nil(ls.LPush("jobs", "a"))
+
+	key, value, err = ls.BLPop(time.Second, "urgent", "jobs")
+	is.Nil(err)
+	is.Equal("jobs", key)
+	is.Equal("a", value)
+
+	key, value, err = ls.BRPop(time.Second, "urgent", "jobs")
+	is.Nil(err)
+	is.Equal("jobs", key)
+	is.Equal("c", value)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		ls.RPush("urgent", "d")
+	}()
+
+	key, value, err = ls.BLPop(0, "urgent")
+	is.Nil(err)
+	is.Equal("urgent", key)
+	is.Equal("d", value)
+
+	exists, err := store.Exists("urgent")
+	is.Nil(err)
+	is.False(exists)
+
+	is.Nil(store.Delete("jobs"))
+}
+
+func TestMemoryStoreList(t *testing.T) {
+	store, err := NewMemoryStore(0, time.Second*10)
+	assert.Nil(t, err)
+
+	testListStore(t, store)
+}
@@ -0,0 +1,148 @@
+package gokvstores
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Locker is implemented by stores providing locks. A lock is held by the
+// owner of a token until it is released or its TTL elapses.
+type Locker interface {
+	// Lock acquires the lock named key for ttl and returns its token.
+	// It fails with ErrLocked if the lock is held, and with ErrInvalidLockTTL
+	// if ttl is zero or negative.
+	Lock(key string, ttl time.Duration) (string, error)
+
+	// Unlock releases the lock named key if it is held with the given token.
+	// It fails with ErrLockNotHeld otherwise.
+	Unlock(key, token string) error
+}
+
+// newLockToken returns a random lock token.
+func newLockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ----------------------------------------------------------------------------
+// Memory
+// ----------------------------------------------------------------------------
+
+// memoryLock is a lock held in a MemoryStore.
+type memoryLock struct {
+	token   string
+	expires time.Time
+}
+
+// memoryLocks are the locks of a MemoryStore, kept apart from its items.
+type memoryLocks struct {
+	mu    sync.Mutex
+	locks map[string]memoryLock
+}
+
+// Lock acquires the lock named key for ttl. Locks are kept apart from the
+// cache items: key does not conflict with them.
+func (c *MemoryStore) Lock(key string, ttl time.Duration) (string, error) {
+	if ttl <= 0 {
+		return "", ErrInvalidLockTTL
+	}
+
+	token, err := newLockToken()
+	if err != nil {
+		return "", err
+	}
+
+	c.locks.mu.Lock()
+	defer c.locks.mu.Unlock()
+
+	now := c.clock.Now()
+
+	if lock, ok := c.locks.locks[key]; ok && now.Before(lock.expires) {
+		return "", ErrLocked
+	}
+
+	if c.locks.locks == nil {
+		c.locks.locks = map[string]memoryLock{}
+	}
+
+	// Drop expired locks, so abandoned ones don't pile up.
+	for k, lock := range c.locks.locks {
+		if !now.Before(lock.expires) {
+			delete(c.locks.locks, k)
+		}
+	}
+
+	c.locks.locks[key] = memoryLock{token: token, expires: now.Add(ttl)}
+
+	return token, nil
+}
+
+// Unlock releases the lock named key if it is held with the given token.
+func (c *MemoryStore) Unlock(key, token string) error {
+	c.locks.mu.Lock()
+	defer c.locks.mu.Unlock()
+
+	lock, ok := c.locks.locks[key]
+	if !ok || lock.token != token || !c.clock.Now().Before(lock.expires) {
+		return ErrLockNotHeld
+	}
+
+	delete(c.locks.locks, key)
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// Redis
+// ----------------------------------------------------------------------------
+
+// unlockScript deletes the lock key if it holds the token.
+var unlockScript = NewScript(`
+	if redis.call("GET", KEYS[1]) == ARGV[1] then
+		return redis.call("DEL", KEYS[1])
+	end
+	return 0
+`)
+
+// Lock acquires the lock named key for ttl, with SET NX PX. The lock is the
+// key itself, holding the token.
+func (r *RedisStore) Lock(key string, ttl time.Duration) (string, error) {
+	if ttl <= 0 {
+		return "", ErrInvalidLockTTL
+	}
+
+	token, err := newLockToken()
+	if err != nil {
+		return "", err
+	}
+
+	acquired, err := r.client.SetNX(r.ctx, key, token, ttl).Result()
+	if err != nil {
+		return "", err
+	}
+
+	if !acquired {
+		return "", ErrLocked
+	}
+
+	return token, nil
+}
+
+// Unlock releases the lock named key if it is held with the given token.
+func (r *RedisStore) Unlock(key, token string) error {
+	deleted, err := r.RunScript(unlockScript, []string{key}, token)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := deleted.(int64); n == 0 {
+		return ErrLockNotHeld
+	}
+
+	return nil
+}
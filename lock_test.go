@@ -0,0 +1,62 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testLocker tests the Locker of the store, wait letting the time of the store
+// pass.
+func testLocker(t *testing.T, store KVStore, wait func(d time.Duration)) {
+	is := assert.New(t)
+
+	locker := store.(Locker)
+
+	token, err := locker.Lock("lock:key", time.Minute)
+	is.Nil(err)
+	is.NotEmpty(token)
+
+	_, err = locker.Lock("lock:key", time.Minute)
+	is.Equal(ErrLocked, err)
+
+	is.Equal(ErrLockNotHeld, locker.Unlock("lock:key", "other"))
+	is.Nil(locker.Unlock("lock:key", token))
+	is.Equal(ErrLockNotHeld, locker.Unlock("lock:key", token))
+
+	// Expired locks can be acquired again.
+
+	token, err = locker.Lock("lock:key", 50*time.Millisecond)
+	is.Nil(err)
+
+	wait(100 * time.Millisecond)
+
+	other, err := locker.Lock("lock:key", time.Minute)
+	is.Nil(err)
+	is.NotEqual(token, other)
+
+	is.Equal(ErrLockNotHeld, locker.Unlock("lock:key", token))
+	is.Nil(locker.Unlock("lock:key", other))
+
+	// Locks must expire.
+
+	_, err = locker.Lock("lock:key", 0)
+	is.Equal(ErrInvalidLockTTL, err)
+
+	_, err = locker.Lock("lock:key", -time.Second)
+	is.Equal(ErrInvalidLockTTL, err)
+}
+
+func TestMemoryStoreLocker(t *testing.T) {
+	clock := NewManualClock(time.Now())
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{
+		Expiration:      time.Second * 10,
+		CleanupInterval: time.Second * 10,
+		Clock:           clock,
+	})
+	assert.Nil(t, err)
+
+	testLocker(t, store, clock.Advance)
+}
@@ -0,0 +1,121 @@
+package gokvstores
+
+import (
+	"log"
+	"time"
+)
+
+// LogLevel is the severity of a log entry.
+type LogLevel int
+
+// Log levels.
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelError
+)
+
+// String returns the level name.
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "debug"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// Logger is the interface used by LoggingStore to emit log entries.
+type Logger interface {
+	Log(level LogLevel, msg string, fields map[string]interface{})
+}
+
+// LoggerFunc is an adapter to use ordinary functions as Logger.
+type LoggerFunc func(level LogLevel, msg string, fields map[string]interface{})
+
+// Log calls f(level, msg, fields).
+func (f LoggerFunc) Log(level LogLevel, msg string, fields map[string]interface{}) {
+	f(level, msg, fields)
+}
+
+// stdLogger is the Logger writing to the standard logger.
+type stdLogger struct{}
+
+func (stdLogger) Log(level LogLevel, msg string, fields map[string]interface{}) {
+	log.Printf("[%s] %s %v", level, msg, fields)
+}
+
+// LoggingOptions are LoggingStore options.
+type LoggingOptions struct {
+	// Logger receives the log entries. Defaults to the standard logger.
+	Logger Logger
+
+	// Level is the level successful operations are logged at.
+	// Failed operations are always logged at LogLevelError.
+	Level LogLevel
+
+	// RedactKey, if set, transforms keys before they are logged.
+	RedactKey func(key string) string
+}
+
+// LoggingStore is a KVStore decorator logging each operation.
+type LoggingStore struct {
+	*interceptedStore
+
+	options LoggingOptions
+}
+
+// NewLoggingStore returns a LoggingStore wrapping the given store.
+func NewLoggingStore(store KVStore, options *LoggingOptions) *LoggingStore {
+	if options == nil {
+		options = &LoggingOptions{}
+	}
+
+	l := &LoggingStore{options: *options}
+	if l.options.Logger == nil {
+		l.options.Logger = stdLogger{}
+	}
+
+	l.interceptedStore = &interceptedStore{store: store, intercept: l.log}
+
+	return l
+}
+
+// log logs a single operation.
+func (l *LoggingStore) log(op *operation, next func() error) error {
+	start := time.Now()
+	err := next()
+
+	fields := map[string]interface{}{
+		"operation": op.name,
+		"duration":  time.Since(start),
+	}
+
+	if op.key != "" {
+		key := op.key
+		if l.options.RedactKey != nil {
+			key = l.options.RedactKey(key)
+		}
+		fields["key"] = key
+	}
+
+	if op.read() {
+		fields["hit"] = op.hit
+	}
+
+	if op.value != nil {
+		fields["size"] = valueSize(op.value)
+	}
+
+	if err != nil {
+		fields["error"] = err
+		l.options.Logger.Log(LogLevelError, "kvstore operation failed", fields)
+	} else {
+		l.options.Logger.Log(l.options.Level, "kvstore operation", fields)
+	}
+
+	return err
+}
@@ -0,0 +1,43 @@
+package gokvstores
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggingStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	entries := []map[string]interface{}{}
+
+	store := NewLoggingStore(memory, &LoggingOptions{
+		Logger: LoggerFunc(func(level LogLevel, msg string, fields map[string]interface{}) {
+			is.Equal(LogLevelInfo, level)
+			entries = append(entries, fields)
+		}),
+		Level:     LogLevelInfo,
+		RedactKey: strings.ToUpper,
+	})
+
+	testStore(t, store)
+
+	entries = entries[:0]
+
+	is.Nil(store.Set("key", "value"))
+	v, err := store.Get("key")
+	is.Nil(err)
+	is.Equal("value", v)
+
+	is.Len(entries, 2)
+	is.Equal("Set", entries[0]["operation"])
+	is.Equal("KEY", entries[0]["key"])
+	is.Equal(5, entries[0]["size"])
+	is.Equal("Get", entries[1]["operation"])
+	is.Equal(true, entries[1]["hit"])
+}
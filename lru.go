@@ -0,0 +1,175 @@
+package gokvstores
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lruEntry is a key of an lruIndex, with its weight.
+type lruEntry struct {
+	key    string
+	weight int
+}
+
+// lruIndex orders the keys of a MemoryStore from the most to the least
+// recently used, to evict the latter once there are too many, or once they
+// weigh too much. Pinned keys are counted but never evicted.
+type lruIndex struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxWeight  int
+	weight     int
+	order      *list.List
+	elements   map[string]*list.Element
+
+	// peak is the most elements held since the map was built, to shrink it
+	// once below shrinkRatio of it.
+	peak        int
+	shrinkRatio float64
+
+	// pins are the pinned keys, set or not, and pinned the entries of those
+	// which are set, kept out of order.
+	pins   map[string]bool
+	pinned map[string]*lruEntry
+}
+
+// newLRUIndex returns an lruIndex holding at most maxEntries keys weighing
+// at most maxWeight, zero meaning no limit.
+func newLRUIndex(maxEntries, maxWeight int) *lruIndex {
+	return &lruIndex{
+		maxEntries:  maxEntries,
+		maxWeight:   maxWeight,
+		order:       list.New(),
+		elements:    map[string]*list.Element{},
+		shrinkRatio: defaultShrinkRatio,
+		pins:        map[string]bool{},
+		pinned:      map[string]*lruEntry{},
+	}
+}
+
+// use marks the key as the most recently used, sets its weight unless
+// negative, and returns the least recently used keys to evict.
+func (l *lruIndex) use(key string, weight int) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.pins[key] {
+		if weight < 0 {
+			return nil
+		}
+
+		entry, ok := l.pinned[key]
+		if !ok {
+			entry = &lruEntry{key: key}
+			l.pinned[key] = entry
+		}
+		l.weight += weight - entry.weight
+		entry.weight = weight
+
+		return l.evict()
+	}
+
+	if e, ok := l.elements[key]; ok {
+		l.order.MoveToFront(e)
+		if weight < 0 {
+			return nil
+		}
+
+		entry := e.Value.(*lruEntry)
+		l.weight += weight - entry.weight
+		entry.weight = weight
+	} else {
+		if weight < 0 {
+			weight = 0
+		}
+		l.elements[key] = l.order.PushFront(&lruEntry{key: key, weight: weight})
+		l.weight += weight
+		if len(l.elements) > l.peak {
+			l.peak = len(l.elements)
+		}
+	}
+
+	return l.evict()
+}
+
+// evict removes the least recently used keys while there are too many, or
+// they weigh too much, and returns them, with the lock held.
+func (l *lruIndex) evict() []string {
+	var evicted []string
+	for l.full() && l.order.Len() > 0 {
+		e := l.order.Back()
+		entry := l.order.Remove(e).(*lruEntry)
+		delete(l.elements, entry.key)
+		l.weight -= entry.weight
+		evicted = append(evicted, entry.key)
+	}
+	l.shrink()
+
+	return evicted
+}
+
+// full reports whether keys must be evicted, with the lock held.
+func (l *lruIndex) full() bool {
+	return (l.maxEntries > 0 && l.order.Len()+len(l.pinned) > l.maxEntries) ||
+		(l.maxWeight > 0 && l.weight > l.maxWeight)
+}
+
+// remove removes the key.
+func (l *lruIndex) remove(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if e, ok := l.elements[key]; ok {
+		l.order.Remove(e)
+		delete(l.elements, key)
+		l.weight -= e.Value.(*lruEntry).weight
+		l.shrink()
+	} else if entry, ok := l.pinned[key]; ok {
+		delete(l.pinned, key)
+		l.weight -= entry.weight
+	}
+}
+
+// pin protects the key from eviction, whether it is set or not.
+func (l *lruIndex) pin(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pins[key] = true
+
+	if e, ok := l.elements[key]; ok {
+		l.pinned[key] = l.order.Remove(e).(*lruEntry)
+		delete(l.elements, key)
+	}
+}
+
+// unpin makes the key evictable again, as the most recently used one, and
+// returns the least recently used keys to evict.
+func (l *lruIndex) unpin(key string) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.pins, key)
+
+	entry, ok := l.pinned[key]
+	if !ok {
+		return nil
+	}
+
+	delete(l.pinned, key)
+	l.elements[key] = l.order.PushFront(entry)
+
+	return l.evict()
+}
+
+// clear removes all the keys, which stay pinned.
+func (l *lruIndex) clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.order.Init()
+	l.elements = map[string]*list.Element{}
+	l.peak = 0
+	l.pinned = map[string]*lruEntry{}
+	l.weight = 0
+}
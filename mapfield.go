@@ -0,0 +1,163 @@
+package gokvstores
+
+import (
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MapFieldStore is implemented by stores able to write a single field of a
+// map, with its own expiration.
+type MapFieldStore interface {
+	// SetMapValue sets a field of the map of the given key, creating it if
+	// needed. The field does not expire.
+	SetMapValue(key, field string, value interface{}) error
+
+	// SetMapValueWithExpiration sets a field of the map of the given key,
+	// which is removed from the map once expired. Zero or negative means the
+	// field never expires.
+	SetMapValueWithExpiration(key, field string, value interface{}, expiration time.Duration) error
+}
+
+// ----------------------------------------------------------------------------
+// Memory
+// ----------------------------------------------------------------------------
+
+// fieldMap is a MemoryStore map with expiring fields. It is replaced, never
+// modified, once stored.
+type fieldMap struct {
+	fields      map[string]interface{}
+	expirations map[string]time.Time
+	clock       Clock
+}
+
+// values returns the unexpired fields, nil if there are none.
+func (m *fieldMap) values() map[string]interface{} {
+	now := m.clock.Now()
+
+	var values map[string]interface{}
+	for field, value := range m.fields {
+		if expiration, ok := m.expirations[field]; ok && !now.Before(expiration) {
+			continue
+		}
+
+		if values == nil {
+			values = make(map[string]interface{}, len(m.fields))
+		}
+		values[field] = value
+	}
+
+	return values
+}
+
+// plainValue returns a value found in a MemoryStore cache as read by callers:
+// the unexpired fields of a fieldMap, which is not found if there are none.
+func plainValue(value interface{}, found bool) (interface{}, bool) {
+	m, ok := value.(*fieldMap)
+	if !ok {
+		return value, found
+	}
+
+	if values := m.values(); values != nil {
+		return values, true
+	}
+	return nil, false
+}
+
+// SetMapValue sets a field of the map of the given key.
+func (c *MemoryStore) SetMapValue(key, field string, value interface{}) error {
+	return c.SetMapValueWithExpiration(key, field, value, 0)
+}
+
+// SetMapValueWithExpiration sets a field of the map of the given key, which
+// expires separately. Expired fields are removed from the map as it is
+// written.
+func (c *MemoryStore) SetMapValueWithExpiration(key, field string, value interface{}, expiration time.Duration) error {
+	c.txn.Lock()
+	defer c.txn.Unlock()
+
+	current, keyExpiration, found := c.lookupWithExpiration(key)
+
+	m := &fieldMap{
+		fields:      map[string]interface{}{},
+		expirations: map[string]time.Time{},
+		clock:       c.clock,
+	}
+
+	switch v := current.(type) {
+	case nil:
+	case map[string]interface{}:
+		for f, value := range v {
+			m.fields[f] = value
+		}
+	case *fieldMap:
+		for f, value := range v.values() {
+			m.fields[f] = value
+			if expiration, ok := v.expirations[f]; ok {
+				m.expirations[f] = expiration
+			}
+		}
+	default:
+		c.stats.write(ErrWrongType)
+		return ErrWrongType
+	}
+
+	m.fields[field] = value
+	delete(m.expirations, field)
+	if expiration > 0 {
+		m.expirations[field] = c.clock.Now().Add(expiration)
+	}
+
+	ttl := c.expiration
+	if found {
+		ttl = c.remainingTTL(keyExpiration)
+	}
+
+	if len(m.expirations) > 0 {
+		c.store(key, m, ttl)
+	} else {
+		c.store(key, m.fields, ttl)
+	}
+
+	c.stats.write(nil)
+	c.watches.notify(ChangeSet, key)
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// Redis
+// ----------------------------------------------------------------------------
+
+// SetMapValue sets a field of the map of the given key with HSET.
+func (r *RedisStore) SetMapValue(key, field string, value interface{}) error {
+	return r.SetMapValueWithExpiration(key, field, value, 0)
+}
+
+// SetMapValueWithExpiration sets a field of the map of the given key with
+// HSET, then its expiration with HPEXPIRE. Field expirations require Redis
+// 7.4: with older servers, the field is written without expiration and
+// ErrNotSupported is returned.
+func (r *RedisStore) SetMapValueWithExpiration(key, field string, value interface{}, expiration time.Duration) (err error) {
+	defer func() { r.stats.write(err) }()
+	defer r.uncache(key)
+
+	encoded, err := r.encode(value)
+	if err != nil {
+		return err
+	}
+
+	err = r.write(func(c redis.Cmdable) error {
+		if err := c.HSet(r.ctx, key, field, encoded).Err(); err != nil || expiration <= 0 {
+			return err
+		}
+
+		return c.HPExpire(r.ctx, key, expiration, field).Err()
+	})
+
+	if err != nil && strings.HasPrefix(err.Error(), "ERR unknown command") {
+		return ErrNotSupported
+	}
+
+	return err
+}
@@ -0,0 +1,69 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testMapFieldStore tests the MapFieldStore of the store, wait letting the
+// time of the store pass.
+func testMapFieldStore(t *testing.T, store KVStore, wait func(d time.Duration)) {
+	is := assert.New(t)
+
+	fs := store.(MapFieldStore)
+
+	is.Nil(store.Delete("session"))
+
+	is.Nil(fs.SetMapValue("session", "user", "ada"))
+	is.Nil(fs.SetMapValueWithExpiration("session", "token", "secret", 50*time.Millisecond))
+
+	values, err := store.GetMap("session")
+	is.Nil(err)
+	is.Equal("ada", values["user"])
+	is.Equal("secret", values["token"])
+
+	wait(100 * time.Millisecond)
+
+	values, err = store.GetMap("session")
+	is.Nil(err)
+	is.Equal("ada", values["user"])
+	is.NotContains(values, "token")
+
+	is.Nil(fs.SetMapValueWithExpiration("session", "user", "ada", 50*time.Millisecond))
+
+	wait(100 * time.Millisecond)
+
+	values, err = store.GetMap("session")
+	is.Nil(err)
+	is.Nil(values)
+
+	exists, err := store.Exists("session")
+	is.Nil(err)
+	is.False(exists)
+}
+
+func TestMemoryStoreMapField(t *testing.T) {
+	clock := NewManualClock(time.Now())
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{CleanupInterval: time.Second * 10, Clock: clock})
+	assert.Nil(t, err)
+
+	testMapFieldStore(t, store, clock.Advance)
+
+	is := assert.New(t)
+
+	is.Nil(store.Set("string", "value"))
+	is.NotNil(store.(MapFieldStore).SetMapValue("string", "field", "value"))
+
+	is.Nil(store.SetMap("map", map[string]interface{}{"a": "1"}))
+	is.Nil(store.(MapFieldStore).SetMapValueWithExpiration("map", "b", "2", time.Minute))
+
+	items := map[string]interface{}{}
+	is.Nil(store.(Scanner).Scan(func(item Item) error {
+		items[item.Key] = item.Value
+		return nil
+	}))
+	is.Equal(map[string]interface{}{"a": "1", "b": "2"}, items["map"])
+}
@@ -1,102 +1,1233 @@
 package gokvstores
 
 import (
+	"sync"
 	"time"
+)
+
+// EvictionReason is the reason an item was removed from a MemoryStore.
+type EvictionReason int
+
+// Eviction reasons.
+const (
+	// EvictionDeleted is an item removed by Delete or Flush.
+	EvictionDeleted EvictionReason = iota
+
+	// EvictionExpired is an item removed once expired.
+	EvictionExpired
+
+	// EvictionEvicted is an item removed to free space.
+	EvictionEvicted
+)
+
+// String returns the reason name.
+func (r EvictionReason) String() string {
+	switch r {
+	case EvictionDeleted:
+		return "deleted"
+	case EvictionExpired:
+		return "expired"
+	case EvictionEvicted:
+		return "evicted"
+	}
+	return "unknown"
+}
+
+// CleanupStrategy is how a MemoryStore removes its expired items. Expired
+// items are never returned, whatever the strategy.
+type CleanupStrategy int
+
+// Cleanup strategies.
+const (
+	// CleanupActive removes the expired items every CleanupInterval, in
+	// batches of SweepBatch. It is the default.
+	CleanupActive CleanupStrategy = iota
 
-	"github.com/patrickmn/go-cache"
+	// CleanupLazy removes the expired items as they are read, without
+	// periodic cleanup, so that no sweep ever holds the locks. The expired
+	// items which are not read again stay in memory until overwritten.
+	CleanupLazy
+
+	// CleanupLazyAndActive removes the expired items as they are read, and
+	// every CleanupInterval.
+	CleanupLazyAndActive
 )
 
+// String returns the strategy name.
+func (s CleanupStrategy) String() string {
+	switch s {
+	case CleanupActive:
+		return "active"
+	case CleanupLazy:
+		return "lazy"
+	case CleanupLazyAndActive:
+		return "lazy+active"
+	}
+	return "unknown"
+}
+
+// evictionChanges maps eviction reasons to change types.
+var evictionChanges = map[EvictionReason]ChangeType{
+	EvictionDeleted: ChangeDelete,
+	EvictionExpired: ChangeExpire,
+	EvictionEvicted: ChangeEvict,
+}
+
 // MemoryStore is the in-memory implementation of KVStore.
 type MemoryStore struct {
-	cache           *cache.Cache
+	// stats comes first to keep its counters 64-bit aligned.
+	stats           statsCounter
+	shards          []*segment
 	expiration      time.Duration
 	cleanupInterval time.Duration
+	watches         watchHub
+
+	// txn is held by transactions, and shared by the other operations.
+	txn sync.RWMutex
+
+	locks  memoryLocks
+	bus    messageBus
+	pushed broadcast
+
+	delayed  delayedItems
+	promoter periodic
+	sweeper  periodic
+	clock    Clock
+
+	// sweepBatch is the number of expired items each cleanup removes per
+	// segment.
+	sweepBatch int
+
+	// snapshots is set with SnapshotPath, journal with JournalPath.
+	snapshots *snapshotter
+	journal   *journal
+
+	indexes searchIndexes
+
+	// lru is set with MaxEntries or MaxWeight, weigher with MaxWeight.
+	lru     *lruIndex
+	weigher func(key string, value interface{}) int
+
+	// spill is set with SpillDir.
+	spill          *spillStore
+	spillThreshold int
+
+	copyOnRead bool
+	copyCodec  Codec
+
+	// hot is set with HotKeys.
+	hot *hotKeys
+
+	// tracker is set with TrackItems.
+	tracker *itemTracker
+
+	mu        sync.RWMutex
+	removing  map[string]removal
+	onEvicted []func(key string, value interface{}, reason EvictionReason)
+	buckets   map[string]*MemoryBucket
+}
+
+// removal is a key being removed from the cache, by count goroutines.
+type removal struct {
+	count  int
+	reason EvictionReason
+}
+
+// MemoryStoreOptions are MemoryStore options.
+type MemoryStoreOptions struct {
+	// Expiration is the expiration of the items set without one. Zero or
+	// NoExpiration means they never expire.
+	Expiration time.Duration
+
+	// CleanupInterval is how often expired items are removed, unless Cleanup
+	// is CleanupLazy. Zero means they are only removed when overwritten, or
+	// read with a lazy Cleanup.
+	CleanupInterval time.Duration
+
+	// Cleanup is the strategy removing the expired items. Defaults to
+	// CleanupActive.
+	Cleanup CleanupStrategy
+
+	// SweepBatch is the maximum number of expired items each segment removes
+	// at each cleanup, holding its lock, so that cleanups of large stores
+	// don't cause latency spikes. The remaining ones are removed by the next
+	// cleanups, in order of expiration, and are not returned meanwhile.
+	// Defaults to 1000.
+	SweepBatch int
+
+	// SlidingExpiration resets the expiration of the items each time they are
+	// read, so that they only expire once unused for their expiration, as
+	// sessions do. Spilled items do not slide, and HotKeys is ignored.
+	SlidingExpiration bool
+
+	// MaxLifetime caps the time an item lives after it was set, whether it
+	// slides or never expires. Expire cannot extend it. Zero means no limit.
+	MaxLifetime time.Duration
+
+	// Clock tells the time the items expire by. Defaults to the system time.
+	Clock Clock
+
+	// MaxEntries caps the number of items, evicting the least recently used
+	// ones once exceeded. Zero means no limit.
+	MaxEntries int
+
+	// MaxWeight caps the total weight of the items, as computed by Weigher,
+	// evicting the least recently used ones once exceeded. An item weighing
+	// more is evicted as soon as set, which also counts as a rejected write.
+	// Zero means no limit.
+	MaxWeight int
+
+	// Weigher returns the weight of an item for MaxWeight, computed when it
+	// is set. Defaults to the approximate size of the key and its value, as
+	// computed by MemoryUsage.
+	Weigher func(key string, value interface{}) int
+
+	// Shards is the number of segments the items are spread over, each with
+	// its own lock, to reduce contention between concurrent writes. Defaults
+	// to 1.
+	Shards int
+
+	// Capacity is the number of items the store is expected to hold, its
+	// maps being allocated for them upfront, and again when flushed, instead
+	// of growing by steps.
+	Capacity int
+
+	// ShrinkRatio rebuilds the maps holding the items once they hold less
+	// than this fraction of the most items they held, after mass deletions or
+	// expirations, since Go maps never give back the memory of deleted
+	// entries. Maps of fewer than 1024 items are left alone. Defaults to
+	// 0.25. Negative disables it.
+	ShrinkRatio float64
+
+	// InternStrings shares the memory of equal short strings among the
+	// values, map field names and slice elements, such as the field names of
+	// millions of maps. Maps and slices are copied as they are set.
+	InternStrings bool
+
+	// SlabSize copies the byte slice values smaller than an eighth of it into
+	// shared buffers of that size, so that millions of small values are
+	// allocated, and scanned by the garbage collector, as a few buffers. A
+	// buffer is freed once all its values are overwritten or removed. Zero
+	// disables it.
+	SlabSize int
+
+	// SnapshotPath is a file the items are loaded from when the store is
+	// created, and saved to every SnapshotInterval and when it is closed,
+	// with their expiration. Values are gob encoded, as by Backup: custom
+	// types must be registered with gob.Register. HyperLogLog, Geo and
+	// filter keys are not saved.
+	SnapshotPath string
+
+	// SnapshotInterval is the delay between two saves. Zero only saves the
+	// items when the store is closed.
+	SnapshotInterval time.Duration
+
+	// OnSnapshotError is called when a periodic save fails.
+	OnSnapshotError func(err error)
+
+	// JournalPath is a file each change is appended to, and replayed from
+	// when the store is created, after loading SnapshotPath, so that the
+	// items survive a crash. It is then rewritten with the current items.
+	// Values are gob encoded, as by Backup. HyperLogLog, Geo and filter keys,
+	// the expirations of map fields and the sliding of expirations are not
+	// recorded.
+	JournalPath string
+
+	// JournalSync flushes the journal to disk after each change, so that it
+	// also survives a power loss, at the cost of much slower writes.
+	JournalSync bool
+
+	// OnJournalError is called when a change cannot be appended to the
+	// journal.
+	OnJournalError func(err error)
+
+	// SpillDir is a directory the values larger than SpillThreshold, and the
+	// least recently used items beyond MaxEntries, are moved to instead of
+	// being kept in memory or evicted. They are read back transparently,
+	// moving the small ones back to memory. Values are gob encoded, as by
+	// Backup. The directory is emptied when the store is created.
+	SpillDir string
+
+	// SpillThreshold is the size, computed as by MemoryUsage, above which
+	// values are stored in SpillDir. Zero only spills the items beyond
+	// MaxEntries.
+	SpillThreshold int
+
+	// CopyOnRead returns deep copies of the values read, so that callers
+	// mutating them do not change the cached values seen by other readers.
+	// Values are copied by reflection: unexported struct fields are shared,
+	// and values must not be cyclic.
+	CopyOnRead bool
+
+	// CopyCodec copies the values with CopyOnRead by encoding and decoding
+	// them instead. It must preserve their types, as GobCodec does.
+	CopyCodec Codec
+
+	// HotKeys is the number of frequently read keys served by Get from an
+	// immutable copy, without taking any lock, to remove the contention
+	// between concurrent reads. Each write to a hot key replaces the copy, so it suits
+	// read-mostly workloads. Reads it serves do not refresh the recency of the
+	// keys for MaxEntries. Zero disables it.
+	HotKeys int
+
+	// TrackItems records when each key was created, updated and last read,
+	// and how many times it was read, as returned by ItemInfo.
+	TrackItems bool
 }
 
 // Get returns item from the cache.
 func (c *MemoryStore) Get(key string) (interface{}, error) {
-	item, _ := c.cache.Get(key)
-	return item, nil
+	// Hot keys are read from their immutable copy without waiting for
+	// transactions, which replace the copy once they write the key.
+	if c.hot != nil {
+		if v, _, found := c.hot.get(key); found {
+			c.read(key)
+			item, found := plainValue(v, true)
+			c.stats.read(found, nil)
+			return c.readCopy(item)
+		}
+	}
+
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	return c.get(key)
+}
+
+// get returns item from the cache.
+func (c *MemoryStore) get(key string) (interface{}, error) {
+	item, found := plainValue(c.lookup(key))
+	c.stats.read(found, nil)
+	return c.readCopy(item)
+}
+
+// GetAndRefresh returns item from the cache, and sets its expiration to ttl.
+func (c *MemoryStore) GetAndRefresh(key string, ttl time.Duration) (interface{}, error) {
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	c.expire(key, ttl)
+	return c.get(key)
+}
+
+// shard returns the segment holding the given key.
+func (c *MemoryStore) shard(key string) *segment {
+	if len(c.shards) == 1 {
+		return c.shards[0]
+	}
+
+	// FNV-1a, without allocating.
+	hash := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		hash ^= uint32(key[i])
+		hash *= 16777619
+	}
+
+	return c.shards[hash%uint32(len(c.shards))]
+}
+
+// items returns the unexpired items of all the segments. The segments are
+// held together, so that the copy is taken at a single point in time.
+func (c *MemoryStore) items() map[string]segmentItem {
+	if len(c.shards) == 1 {
+		return c.shards[0].unexpired()
+	}
+
+	now := c.clock.Now().UnixNano()
+
+	for _, shard := range c.shards {
+		shard.mu.RLock()
+	}
+	defer func() {
+		for _, shard := range c.shards {
+			shard.mu.RUnlock()
+		}
+	}()
+
+	items := map[string]segmentItem{}
+	for _, shard := range c.shards {
+		shard.copyUnexpired(now, items)
+	}
+	return items
+}
+
+// itemCount returns the number of items of all the segments, expired ones
+// included.
+func (c *MemoryStore) itemCount() int {
+	count := 0
+	for _, shard := range c.shards {
+		count += shard.count()
+	}
+	if c.spill != nil {
+		count += c.spill.count()
+	}
+	return count
+}
+
+// deleteExpired removes the expired items of all the segments.
+func (c *MemoryStore) deleteExpired() {
+	for _, shard := range c.shards {
+		shard.deleteExpired()
+	}
+}
+
+// sweep removes a batch of expired items from each segment.
+func (c *MemoryStore) sweep() {
+	now := c.clock.Now().UnixNano()
+	for _, shard := range c.shards {
+		shard.sweep(now, c.sweepBatch)
+	}
+}
+
+// lookup returns the value of the given key, marking it as recently used.
+func (c *MemoryStore) lookup(key string) (interface{}, bool) {
+	if c.hot != nil {
+		if v, _, found := c.hot.get(key); found {
+			c.read(key)
+			return v, true
+		}
+	}
+
+	shard := c.shard(key)
+	v, found := shard.get(key)
+	if found {
+		c.used(key)
+		if c.hot != nil {
+			c.hot.read(shard, key)
+		}
+	} else if c.spill != nil {
+		v, _, found = c.spillIn(key)
+	}
+	if found {
+		c.read(key)
+	}
+	return v, found
+}
+
+// lookupWithExpiration returns the value of the given key and its expiration,
+// marking it as recently used.
+func (c *MemoryStore) lookupWithExpiration(key string) (interface{}, time.Time, bool) {
+	if c.hot != nil {
+		if v, expiration, found := c.hot.get(key); found {
+			c.read(key)
+			return v, expiration, true
+		}
+	}
+
+	shard := c.shard(key)
+	v, expiration, found := shard.getWithExpiration(key)
+	if found {
+		c.used(key)
+		if c.hot != nil {
+			c.hot.read(shard, key)
+		}
+	} else if c.spill != nil {
+		v, expiration, found = c.spillIn(key)
+	}
+	if found {
+		c.read(key)
+	}
+	return v, expiration, found
+}
+
+// read records a read of the given key with TrackItems.
+func (c *MemoryStore) read(key string) {
+	if c.tracker != nil {
+		c.tracker.read(key)
+	}
+}
+
+// store sets the value of the given key, evicting the least recently used
+// keys if there are too many, and moving it to disk if it is large.
+func (c *MemoryStore) store(key string, value interface{}, expiration time.Duration) {
+	if c.tracker != nil {
+		c.tracker.written(key)
+	}
+	if c.journal != nil {
+		c.journal.set(key, value, c.expirationTime(expiration))
+	}
+	c.put(key, value, expiration)
+}
+
+// put sets the value of the given key as store does, without recording the
+// write.
+func (c *MemoryStore) put(key string, value interface{}, expiration time.Duration) {
+	if c.spill != nil {
+		if c.spills(value) {
+			err := c.spill.save(Item{Key: key, Value: value, Expiration: c.expirationTime(expiration)})
+			if err == nil {
+				c.remove(key, evictionSpilled)
+				return
+			}
+		}
+		c.spill.remove(key)
+	}
+
+	c.shard(key).set(key, value, expiration)
+	if c.hot != nil {
+		c.hot.invalidate(key)
+	}
+	c.weighed(key, value)
+}
+
+// used marks the key as recently used, evicting the least recently used keys
+// if there are too many.
+func (c *MemoryStore) used(key string) {
+	if c.lru != nil {
+		c.evictLRU(c.lru.use(key, -1))
+	}
+}
+
+// weighed marks the key as recently used and records the weight of its new
+// value, evicting the least recently used keys if there are too many or they
+// weigh too much.
+func (c *MemoryStore) weighed(key string, value interface{}) {
+	if c.lru == nil {
+		return
+	}
+
+	weight := 1
+	if c.weigher != nil {
+		weight = c.weigher(key, value)
+	}
+
+	evicted := c.lru.use(key, weight)
+	for _, k := range evicted {
+		if k == key {
+			c.stats.reject()
+		}
+	}
+
+	c.evictLRU(evicted)
+}
+
+// evictLRU removes the keys evicted from the lru, or moves them to disk with
+// SpillDir.
+func (c *MemoryStore) evictLRU(keys []string) {
+	for _, key := range keys {
+		if c.spill != nil {
+			c.spillOut(key)
+		} else {
+			c.remove(key, EvictionEvicted)
+		}
+	}
+}
+
+// getOrAdd returns the value of the given key. When the key does not exist,
+// it is set to the value returned by newValue, unless newValue is nil.
+func (c *MemoryStore) getOrAdd(key string, newValue func() interface{}) (interface{}, bool) {
+	v, found := c.lookup(key)
+	for !found {
+		if newValue == nil {
+			return nil, false
+		}
+
+		v = newValue()
+		if c.shard(key).add(key, v, c.expiration) {
+			if c.tracker != nil {
+				c.tracker.written(key)
+			}
+			if c.hot != nil {
+				c.hot.invalidate(key)
+			}
+			c.weighed(key, v)
+			return v, true
+		}
+
+		// Added concurrently.
+		v, found = c.lookup(key)
+	}
+
+	return v, true
+}
+
+// itemExpiration returns the segment expiration of an item set with the
+// given expiration, NoExpiration when zero or negative.
+func itemExpiration(expiration time.Duration) time.Duration {
+	if expiration <= 0 {
+		return NoExpiration
+	}
+	return expiration
+}
+
+// now returns the time the items expire by.
+func (c *MemoryStore) now() time.Time {
+	return c.clock.Now()
+}
+
+// remainingTTL returns the segment expiration keeping the given expiration
+// time of an item, zero if it does not expire.
+func (c *MemoryStore) remainingTTL(expiration time.Time) time.Duration {
+	if expiration.IsZero() {
+		return NoExpiration
+	}
+	return expiration.Sub(c.clock.Now())
 }
 
 // Set sets value in the cache.
 func (c *MemoryStore) Set(key string, value interface{}) error {
-	c.cache.Set(key, value, c.expiration)
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	return c.set(key, value)
+}
+
+// set sets value in the cache.
+func (c *MemoryStore) set(key string, value interface{}) error {
+	c.store(key, value, c.expiration)
+	c.stats.write(nil)
+	c.watches.notify(ChangeSet, key)
 	return nil
 }
 
+// SetWithExpiration sets value in the cache with a specific expiration.
+func (c *MemoryStore) SetWithExpiration(key string, value interface{}, expiration time.Duration) error {
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	return c.setWithExpiration(key, value, expiration)
+}
+
+// setWithExpiration sets value in the cache with a specific expiration.
+func (c *MemoryStore) setWithExpiration(key string, value interface{}, expiration time.Duration) error {
+	c.store(key, value, itemExpiration(expiration))
+	c.stats.write(nil)
+	c.watches.notify(ChangeSet, key)
+	return nil
+}
+
+// replaceItem sets value, of any kind, for the given key as
+// SetWithExpiration does.
+func (c *MemoryStore) replaceItem(key string, value interface{}, ttl time.Duration) error {
+	return c.SetWithExpiration(key, value, ttl)
+}
+
 // GetMap returns map for the given key.
 func (c *MemoryStore) GetMap(key string) (map[string]interface{}, error) {
-	if v, found := c.cache.Get(key); found {
-		return v.(map[string]interface{}), nil
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	return c.getMap(key)
+}
+
+// getMap returns map for the given key.
+func (c *MemoryStore) getMap(key string) (map[string]interface{}, error) {
+	v, found := plainValue(c.lookup(key))
+	if found {
+		if _, ok := v.(map[string]interface{}); !ok {
+			c.stats.read(true, ErrWrongType)
+			return nil, ErrWrongType
+		}
+	}
+
+	c.stats.read(found, nil)
+	if !found {
+		return nil, nil
+	}
+
+	v, err := c.readCopy(v)
+	if err != nil {
+		return nil, err
 	}
-	return nil, nil
+	return v.(map[string]interface{}), nil
 }
 
 // SetMap sets a map for the given key.
 func (c *MemoryStore) SetMap(key string, value map[string]interface{}) error {
-	c.cache.Set(key, value, c.expiration)
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	return c.setMap(key, value)
+}
+
+// setMap sets a map for the given key.
+func (c *MemoryStore) setMap(key string, value map[string]interface{}) error {
+	return c.setMapWithExpiration(key, value, c.expiration)
+}
+
+// SetMapWithExpiration sets a map for the given key with a specific
+// expiration. A zero or negative expiration means the map never expires.
+func (c *MemoryStore) SetMapWithExpiration(key string, value map[string]interface{}, expiration time.Duration) error {
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	return c.setMapWithExpiration(key, value, itemExpiration(expiration))
+}
+
+// setMapWithExpiration sets a map for the given key with a specific
+// expiration.
+func (c *MemoryStore) setMapWithExpiration(key string, value map[string]interface{}, expiration time.Duration) error {
+	c.store(key, value, expiration)
+	c.stats.write(nil)
+	c.watches.notify(ChangeSet, key)
 	return nil
 }
 
 // GetSlice returns slice for the given key.
 func (c *MemoryStore) GetSlice(key string) ([]interface{}, error) {
-	if v, found := c.cache.Get(key); found {
-		return v.([]interface{}), nil
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	return c.getSlice(key)
+}
+
+// getSlice returns slice for the given key.
+func (c *MemoryStore) getSlice(key string) ([]interface{}, error) {
+	v, found := c.lookup(key)
+	if found {
+		if _, ok := v.([]interface{}); !ok {
+			c.stats.read(true, ErrWrongType)
+			return nil, ErrWrongType
+		}
+	}
+
+	c.stats.read(found, nil)
+	if !found {
+		return nil, nil
+	}
+
+	v, err := c.readCopy(v)
+	if err != nil {
+		return nil, err
 	}
-	return nil, nil
+	return v.([]interface{}), nil
 }
 
 // SetSlice sets slice for the given key.
 func (c *MemoryStore) SetSlice(key string, value []interface{}) error {
-	c.cache.Set(key, value, c.expiration)
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	return c.setSlice(key, value)
+}
+
+// setSlice sets slice for the given key.
+func (c *MemoryStore) setSlice(key string, value []interface{}) error {
+	return c.setSliceWithExpiration(key, value, c.expiration)
+}
+
+// SetSliceWithExpiration sets slice for the given key with a specific
+// expiration. A zero or negative expiration means the slice never expires.
+func (c *MemoryStore) SetSliceWithExpiration(key string, value []interface{}, expiration time.Duration) error {
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	return c.setSliceWithExpiration(key, value, itemExpiration(expiration))
+}
+
+// setSliceWithExpiration sets slice for the given key with a specific
+// expiration.
+func (c *MemoryStore) setSliceWithExpiration(key string, value []interface{}, expiration time.Duration) error {
+	c.store(key, value, expiration)
+	c.stats.write(nil)
+	c.watches.notify(ChangeSet, key)
 	return nil
 }
 
-// AppendSlice appends values to the given slice.
+// AppendSlice appends values to the given slice, creating it if the key does
+// not exist, as the KVStore contract requires.
 func (c *MemoryStore) AppendSlice(key string, values ...interface{}) error {
-	items, err := c.GetSlice(key)
-	if err != nil {
-		return err
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	return c.appendSlice(key, values...)
+}
+
+// appendSlice appends values to the given slice.
+func (c *MemoryStore) appendSlice(key string, values ...interface{}) error {
+	var items []interface{}
+	if v, found := c.lookup(key); found {
+		var ok bool
+		if items, ok = v.([]interface{}); !ok {
+			c.stats.write(ErrWrongType)
+			return ErrWrongType
+		}
 	}
 
 	for _, item := range values {
 		items = append(items, item)
 	}
 
-	return c.cache.Replace(key, items, c.expiration)
+	c.store(key, items, c.expiration)
+	c.stats.write(nil)
+	c.watches.notify(ChangeSet, key)
+	return nil
 }
 
-// Close does nothing for this backend.
+// Close stops the cleanup of the expired items and the promotion of the
+// values set by SetDelayed, saves the items with SnapshotPath and closes the
+// journal.
 func (c *MemoryStore) Close() error {
+	c.sweeper.close()
+	c.promoter.close()
+
+	var err error
+	if c.snapshots != nil {
+		err = c.snapshots.close(c)
+	}
+
+	if c.journal != nil {
+		if closeErr := c.journal.close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// Ping does nothing for this backend.
+func (c *MemoryStore) Ping() error {
 	return nil
 }
 
 // Flush removes all items from the cache.
 func (c *MemoryStore) Flush() error {
-	c.cache.Flush()
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	return c.flush()
+}
+
+// flush removes all items from the cache.
+func (c *MemoryStore) flush() error {
+	var items map[string]segmentItem
+	if c.watches.active() || c.hasEvictionCallbacks() {
+		items = c.items()
+	}
+
+	for _, shard := range c.shards {
+		shard.flush()
+	}
+	if c.lru != nil {
+		c.lru.clear()
+	}
+	if c.spill != nil {
+		c.spill.clear()
+	}
+	if c.hot != nil {
+		c.hot.clear()
+	}
+	if c.tracker != nil {
+		c.tracker.clear()
+	}
+	if c.journal != nil {
+		c.journal.append(journalEntry{Op: journalFlush})
+	}
+	c.stats.write(nil)
+
+	for key, item := range items {
+		c.evict(key, item.object, EvictionDeleted)
+	}
+
 	return nil
 }
 
 // Delete deletes the given key.
 func (c *MemoryStore) Delete(key string) error {
-	c.cache.Delete(key)
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	return c.delete(key)
+}
+
+// delete deletes the given key.
+func (c *MemoryStore) delete(key string) error {
+	c.remove(key, EvictionDeleted)
+	if c.spill != nil {
+		c.spill.remove(key)
+	}
+	if c.tracker != nil {
+		c.tracker.forget(key)
+	}
+	if c.journal != nil {
+		c.journal.append(journalEntry{Op: journalDelete, Key: key})
+	}
+	c.stats.write(nil)
 	return nil
 }
 
+// remove deletes the given key, reporting it to the OnEvicted functions with
+// the given reason.
+func (c *MemoryStore) remove(key string, reason EvictionReason) {
+	c.mu.Lock()
+	r := c.removing[key]
+	r.count++
+	r.reason = reason
+	c.removing[key] = r
+	c.mu.Unlock()
+
+	c.shard(key).delete(key)
+	if c.hot != nil {
+		c.hot.invalidate(key)
+	}
+
+	c.mu.Lock()
+	if r = c.removing[key]; r.count > 1 {
+		r.count--
+		c.removing[key] = r
+	} else {
+		delete(c.removing, key)
+	}
+	c.mu.Unlock()
+}
+
+// OnEvicted registers a function called with each item removed from the
+// store, and the reason it was. Expired items are removed, and reported, by the
+// periodic cleanup. Overwritten items are not reported. f is called while the
+// store may be held by the operation, or transaction, removing the item, so
+// it must not use the store.
+func (c *MemoryStore) OnEvicted(f func(key string, value interface{}, reason EvictionReason)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.onEvicted = append(c.onEvicted, f)
+}
+
+// hasEvictionCallbacks reports whether any OnEvicted function is registered.
+func (c *MemoryStore) hasEvictionCallbacks() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.onEvicted) > 0
+}
+
+// segmentEvicted is the segment eviction callback, called by Delete,
+// evictions and the cleanup of expired items.
+func (c *MemoryStore) segmentEvicted(key string, value interface{}) {
+	c.mu.RLock()
+	reason := EvictionExpired
+	if r, ok := c.removing[key]; ok {
+		reason = r.reason
+	}
+	c.mu.RUnlock()
+
+	if c.lru != nil && reason != EvictionEvicted {
+		c.lru.remove(key)
+	}
+
+	if c.hot != nil && reason == EvictionExpired {
+		c.hot.invalidate(key)
+	}
+
+	if reason == evictionSpilled {
+		return
+	}
+
+	c.stats.removed(reason)
+
+	if c.tracker != nil {
+		c.tracker.forget(key)
+	}
+
+	c.evict(key, value, reason)
+}
+
+// evict reports a removed item to the watches and OnEvicted functions.
+func (c *MemoryStore) evict(key string, value interface{}, reason EvictionReason) {
+	c.watches.notify(evictionChanges[reason], key)
+
+	c.mu.RLock()
+	callbacks := c.onEvicted
+	c.mu.RUnlock()
+
+	for _, f := range callbacks {
+		f(key, value, reason)
+	}
+}
+
 // Exists checks if the given key exists.
 func (c *MemoryStore) Exists(key string) (bool, error) {
-	if _, exists := c.cache.Get(key); exists {
-		return true, nil
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	return c.exists(key)
+}
+
+// exists checks if the given key exists.
+func (c *MemoryStore) exists(key string) (bool, error) {
+	_, exists := plainValue(c.lookup(key))
+	c.stats.read(exists, nil)
+	return exists, nil
+}
+
+// Stats returns the counters of the store. Keys includes expired items
+// which have not been cleaned up yet.
+func (c *MemoryStore) Stats() (Stats, error) {
+	return c.stats.stats(int64(c.itemCount())), nil
+}
+
+// MemoryUsage returns the approximate size of the unexpired keys and values,
+// computed from their string representations. Bookkeeping overhead is not
+// accounted for.
+func (c *MemoryStore) MemoryUsage() (int64, error) {
+	c.txn.Lock()
+	defer c.txn.Unlock()
+
+	var size int64
+	for key, item := range c.items() {
+		size += int64(itemSize(key, item.object))
 	}
-	return false, nil
+
+	return size, nil
 }
 
-// NewMemoryStore returns in-memory KVStore.
+// KeyMemoryUsage returns the approximate size of the key and its value,
+// computed as by MemoryUsage.
+func (c *MemoryStore) KeyMemoryUsage(key string) (int64, error) {
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	v, found := c.shard(key).get(key)
+	if !found {
+		return 0, nil
+	}
+
+	return int64(itemSize(key, v)), nil
+}
+
+// Watch returns a KeyWatch receiving the changes of the keys starting with
+// keyOrPrefix. Changes are dropped while its channel is full.
+func (c *MemoryStore) Watch(keyOrPrefix string) (*KeyWatch, error) {
+	return c.watches.watch(keyOrPrefix), nil
+}
+
+// Scan calls fn with each unexpired item of the cache, spilled ones included.
+// The items are copied while no transaction runs, then fn is called without
+// holding the store, so it may use it.
+func (c *MemoryStore) Scan(fn func(item Item) error) error {
+	items, err := c.scanItems()
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scanItems returns the unexpired items, spilled ones included, holding the
+// store exclusively so that no transaction is seen half applied.
+func (c *MemoryStore) scanItems() ([]Item, error) {
+	c.txn.Lock()
+	defer c.txn.Unlock()
+
+	items := c.items()
+	scanned := make([]Item, 0, len(items))
+
+	for key, item := range items {
+		value, found := plainValue(item.object, true)
+		if !found {
+			continue
+		}
+
+		value, err := c.readCopy(value)
+		if err != nil {
+			return nil, err
+		}
+
+		i := Item{Key: key, Value: value}
+		if item.expiration > 0 {
+			i.Expiration = time.Unix(0, item.expiration)
+		}
+		scanned = append(scanned, i)
+	}
+
+	if c.spill != nil {
+		err := c.spill.scan(func(key string) bool {
+			_, found := items[key]
+			return found
+		}, func(item Item) error {
+			scanned = append(scanned, item)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return scanned, nil
+}
+
+// Expire sets the expiration of the given key.
+func (c *MemoryStore) Expire(key string, expiration time.Duration) error {
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	c.expire(key, expiration)
+	return nil
+}
+
+// ExpireMany sets the expiration of each key.
+func (c *MemoryStore) ExpireMany(expirations map[string]time.Duration) error {
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	for key, expiration := range expirations {
+		c.expire(key, expiration)
+	}
+
+	return nil
+}
+
+// expire sets the expiration of the given key.
+func (c *MemoryStore) expire(key string, expiration time.Duration) {
+	expiration = itemExpiration(expiration)
+
+	if c.journal != nil {
+		c.journal.append(journalEntry{Op: journalExpire, Key: key, Expiration: c.expirationTime(expiration)})
+	}
+
+	if c.shard(key).touch(key, expiration) {
+		if c.hot != nil {
+			c.hot.invalidate(key)
+		}
+	} else if c.spill != nil {
+		if item, found := c.spill.load(key); found {
+			item.Expiration = c.expirationTime(expiration)
+			c.spill.save(item)
+		}
+	}
+}
+
+// Snapshot returns a read-only view of the cache at the current time.
+func (c *MemoryStore) Snapshot() (*Snapshot, error) {
+	items, err := c.scanItems()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{
+		time:  c.clock.Now(),
+		items: make(map[string]snapshotItem, len(items)),
+	}
+
+	for _, item := range items {
+		snapshot.items[item.Key] = snapshotItem{value: item.Value, expiration: item.Expiration}
+	}
+
+	return snapshot, nil
+}
+
+// NewMemoryStore returns in-memory KVStore. Items set without an expiration
+// expire after expiration, or never with zero or NoExpiration.
 func NewMemoryStore(expiration time.Duration, cleanupInterval time.Duration) (KVStore, error) {
-	return &MemoryStore{
-		cache:           cache.New(expiration, cleanupInterval),
-		expiration:      time.Duration(expiration) * time.Second,
-		cleanupInterval: cleanupInterval,
-	}, nil
+	return NewMemoryStoreWithOptions(&MemoryStoreOptions{
+		Expiration:      expiration,
+		CleanupInterval: cleanupInterval,
+	})
+}
+
+// NewMemoryStoreWithOptions returns in-memory KVStore with the given options.
+func NewMemoryStoreWithOptions(options *MemoryStoreOptions) (KVStore, error) {
+	if options == nil {
+		options = &MemoryStoreOptions{}
+	}
+
+	c := &MemoryStore{
+		expiration:      itemExpiration(options.Expiration),
+		cleanupInterval: options.CleanupInterval,
+		removing:        map[string]removal{},
+		copyOnRead:      options.CopyOnRead,
+		copyCodec:       options.CopyCodec,
+		clock:           options.Clock,
+	}
+
+	if c.clock == nil {
+		c.clock = systemClock{}
+	}
+
+	c.sweepBatch = options.SweepBatch
+	if c.sweepBatch <= 0 {
+		c.sweepBatch = defaultSweepBatch
+	}
+
+	shrinkRatio := options.ShrinkRatio
+	if shrinkRatio == 0 {
+		shrinkRatio = defaultShrinkRatio
+	}
+
+	if options.MaxEntries > 0 || options.MaxWeight > 0 {
+		c.lru = newLRUIndex(options.MaxEntries, options.MaxWeight)
+		c.lru.shrinkRatio = shrinkRatio
+	}
+
+	if options.MaxWeight > 0 {
+		c.weigher = options.Weigher
+		if c.weigher == nil {
+			c.weigher = itemSize
+		}
+	}
+
+	if options.TrackItems {
+		c.tracker = &itemTracker{clock: c.clock}
+	}
+
+	if options.HotKeys > 0 && !options.SlidingExpiration {
+		c.hot = newHotKeys(options.HotKeys, c.clock)
+	}
+
+	if options.SpillDir != "" {
+		spill, err := newSpillStore(options.SpillDir, c.clock)
+		if err != nil {
+			return nil, err
+		}
+		c.spill = spill
+		c.spillThreshold = options.SpillThreshold
+	}
+
+	shards := options.Shards
+	if shards <= 0 {
+		shards = 1
+	}
+
+	for i := 0; i < shards; i++ {
+		shard := newSegment(options.Capacity/shards, c.expiration, c.clock, c.segmentEvicted)
+		shard.sliding = options.SlidingExpiration
+		shard.maxLifetime = options.MaxLifetime
+		shard.shrinkRatio = shrinkRatio
+		shard.lazy = options.Cleanup == CleanupLazy || options.Cleanup == CleanupLazyAndActive
+		if options.InternStrings {
+			shard.strings = interner{}
+		}
+		if options.SlabSize > 0 {
+			shard.slab = newSlab(options.SlabSize)
+		}
+		c.shards = append(c.shards, shard)
+	}
+
+	if options.SnapshotPath != "" {
+		if err := c.loadSnapshot(options.SnapshotPath); err != nil {
+			return nil, err
+		}
+
+		c.snapshots = &snapshotter{
+			path:     options.SnapshotPath,
+			interval: options.SnapshotInterval,
+			onError:  options.OnSnapshotError,
+			stop:     make(chan struct{}),
+		}
+
+		if options.SnapshotInterval > 0 {
+			c.snapshots.wg.Add(1)
+			go c.snapshots.run(c)
+		}
+	}
+
+	if options.JournalPath != "" {
+		journal, err := openJournal(options.JournalPath, c, options.JournalSync, options.OnJournalError)
+		if err != nil {
+			return nil, err
+		}
+		c.journal = journal
+	}
+
+	if options.CleanupInterval > 0 && options.Cleanup != CleanupLazy {
+		c.sweeper.start(options.CleanupInterval, c.sweep)
+	}
+
+	return c, nil
 }
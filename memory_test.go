@@ -1,6 +1,10 @@
 package gokvstores
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,3 +17,657 @@ func TestMemoryStore(t *testing.T) {
 
 	testStore(t, store)
 }
+
+func TestMemoryStoreSnapshot(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	is.Nil(store.Set("key", "value"))
+	is.Nil(store.SetMap("map", map[string]interface{}{"language": "go"}))
+
+	snapshot, err := store.(Snapshotter).Snapshot()
+	is.Nil(err)
+
+	is.Nil(store.Set("key", "changed"))
+	is.Nil(store.Set("other", "value"))
+
+	is.Equal(2, snapshot.Len())
+	is.Equal([]string{"key", "map"}, snapshot.Keys())
+
+	v, err := snapshot.Get("key")
+	is.Nil(err)
+	is.Equal("value", v)
+
+	m, err := snapshot.GetMap("map")
+	is.Nil(err)
+	is.Equal(map[string]interface{}{"language": "go"}, m)
+
+	exists, err := snapshot.Exists("other")
+	is.Nil(err)
+	is.False(exists)
+
+	is.Equal(ErrReadOnly, snapshot.Set("key", "value"))
+}
+
+func TestMemoryStoreStats(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	is.Nil(store.Set("key", "value"))
+	is.Nil(store.SetMap("map", map[string]interface{}{"language": "go"}))
+	is.Nil(store.AppendSlice("slice", "a", "b"))
+
+	_, err = store.Get("key")
+	is.Nil(err)
+	_, err = store.GetMap("unknown")
+	is.Nil(err)
+	_, err = store.Exists("slice")
+	is.Nil(err)
+	is.Nil(store.Ping())
+
+	stats, err := store.(StatsProvider).Stats()
+	is.Nil(err)
+	is.Equal(Stats{Operations: 6, Hits: 2, Misses: 1, Keys: 3}, stats)
+}
+
+func TestMemoryStoreWatch(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	watch, err := store.(Watcher).Watch("user:")
+	is.Nil(err)
+
+	is.Nil(store.Set("user:1", "value"))
+	is.Nil(store.Set("other", "value"))
+	is.Nil(store.SetMap("user:2", map[string]interface{}{"language": "go"}))
+	is.Nil(store.Delete("user:1"))
+	is.Nil(store.Flush())
+
+	is.Equal(ChangeEvent{Type: ChangeSet, Key: "user:1"}, <-watch.Events())
+	is.Equal(ChangeEvent{Type: ChangeSet, Key: "user:2"}, <-watch.Events())
+	is.Equal(ChangeEvent{Type: ChangeDelete, Key: "user:1"}, <-watch.Events())
+	is.Equal(ChangeEvent{Type: ChangeDelete, Key: "user:2"}, <-watch.Events())
+
+	is.Nil(watch.Close())
+	is.Nil(watch.Close())
+
+	_, ok := <-watch.Events()
+	is.False(ok)
+
+	is.Nil(store.Set("user:3", "value"))
+}
+
+func TestMemoryStoreOnEvicted(t *testing.T) {
+	is := assert.New(t)
+
+	clock := NewManualClock(time.Now())
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{
+		Expiration:      time.Second * 10,
+		CleanupInterval: time.Second * 10,
+		Clock:           clock,
+	})
+	is.Nil(err)
+
+	memory := store.(*MemoryStore)
+
+	type eviction struct {
+		key    string
+		value  interface{}
+		reason EvictionReason
+	}
+
+	evictions := []eviction{}
+	memory.OnEvicted(func(key string, value interface{}, reason EvictionReason) {
+		evictions = append(evictions, eviction{key, value, reason})
+	})
+
+	watch, err := memory.Watch("")
+	is.Nil(err)
+
+	is.Nil(store.Set("key", "value"))
+	is.Nil(store.SetWithExpiration("short", "value", time.Millisecond))
+	is.Nil(store.Delete("key"))
+	is.Nil(store.Delete("unknown"))
+
+	clock.Advance(5 * time.Millisecond)
+	memory.deleteExpired()
+
+	is.Nil(store.Set("other", "value"))
+	is.Nil(store.Flush())
+
+	is.Equal([]eviction{
+		{"key", "value", EvictionDeleted},
+		{"short", "value", EvictionExpired},
+		{"other", "value", EvictionDeleted},
+	}, evictions)
+
+	changes := []ChangeEvent{}
+	for len(watch.Events()) > 0 {
+		changes = append(changes, <-watch.Events())
+	}
+
+	is.Contains(changes, ChangeEvent{Type: ChangeExpire, Key: "short"})
+	is.Equal("expired", EvictionExpired.String())
+}
+
+func TestMemoryStoreMemoryUsage(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	is.Nil(store.Set("key", "value"))
+	is.Nil(store.SetMap("map", map[string]interface{}{"language": "go"}))
+
+	usage, err := store.(MemoryReporter).MemoryUsage()
+	is.Nil(err)
+	is.Equal(int64(len("key")+len("value")+len("map")+len("language")+len("go")), usage)
+
+	usage, err = store.(KeyMemoryReporter).KeyMemoryUsage("map")
+	is.Nil(err)
+	is.Equal(int64(len("map")+len("language")+len("go")), usage)
+
+	usage, err = store.(KeyMemoryReporter).KeyMemoryUsage("missing")
+	is.Nil(err)
+	is.Equal(int64(0), usage)
+}
+
+func TestMemoryStoreRemovalStats(t *testing.T) {
+	is := assert.New(t)
+
+	clock := NewManualClock(time.Now())
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{
+		Expiration: time.Minute,
+		MaxEntries: 2,
+		Cleanup:    CleanupLazy,
+		Clock:      clock,
+	})
+	is.Nil(err)
+
+	is.Nil(store.Set("a", "value"))
+	is.Nil(store.Set("b", "value"))
+	is.Nil(store.Set("c", "value"))
+	is.Nil(store.Delete("c"))
+
+	clock.Advance(2 * time.Minute)
+
+	_, err = store.Get("b")
+	is.Nil(err)
+
+	stats, err := store.(StatsProvider).Stats()
+	is.Nil(err)
+	is.Equal(uint64(1), stats.Evictions)
+	is.Equal(uint64(1), stats.Expirations)
+	is.Equal(uint64(0), stats.Rejected)
+
+	// Items weighing more than MaxWeight are rejected.
+	store, err = NewMemoryStoreWithOptions(&MemoryStoreOptions{MaxWeight: 16})
+	is.Nil(err)
+
+	is.Nil(store.Set("small", "value"))
+	is.Nil(store.Set("large", strings.Repeat("x", 32)))
+
+	stats, err = store.(StatsProvider).Stats()
+	is.Nil(err)
+	is.Equal(uint64(2), stats.Evictions)
+	is.Equal(uint64(1), stats.Rejected)
+}
+
+func TestMemoryStoreWrongType(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewMemoryStore(NoExpiration, 0)
+	is.Nil(err)
+
+	is.Nil(store.Set("key", "value"))
+	is.Nil(store.SetMap("map", map[string]interface{}{"language": "go"}))
+
+	m, err := store.GetMap("key")
+	is.Equal(ErrWrongType, err)
+	is.Nil(m)
+
+	s, err := store.GetSlice("key")
+	is.Equal(ErrWrongType, err)
+	is.Nil(s)
+
+	s, err = store.GetSlice("map")
+	is.Equal(ErrWrongType, err)
+	is.Nil(s)
+
+	is.Equal(ErrWrongType, store.AppendSlice("key", "a"))
+	is.Equal(ErrWrongType, store.(*MemoryStore).SetMapValue("key", "field", "value"))
+
+	value, err := store.Get("key")
+	is.Nil(err)
+	is.Equal("value", value)
+
+	stats, err := store.(StatsProvider).Stats()
+	is.Nil(err)
+	is.Equal(uint64(5), stats.Errors)
+}
+
+func TestMemoryStoreExpireMany(t *testing.T) {
+	clock := NewManualClock(time.Now())
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{CleanupInterval: time.Second * 10, Clock: clock})
+	assert.Nil(t, err)
+
+	testExpireMany(t, store, clock.Advance)
+}
+
+func TestMemoryStoreGetAndRefresh(t *testing.T) {
+	clock := NewManualClock(time.Now())
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{CleanupInterval: time.Second * 10, Clock: clock})
+	assert.Nil(t, err)
+
+	testRefresher(t, store, clock.Advance)
+}
+
+func TestMemoryStoreMaxEntries(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{MaxEntries: 2})
+	is.Nil(err)
+
+	var evicted []string
+	store.(*MemoryStore).OnEvicted(func(key string, value interface{}, reason EvictionReason) {
+		if reason == EvictionEvicted {
+			evicted = append(evicted, key)
+		}
+	})
+
+	is.Nil(store.Set("a", 1))
+	is.Nil(store.Set("b", 2))
+
+	_, err = store.Get("a")
+	is.Nil(err)
+
+	is.Nil(store.Set("c", 3))
+	is.Equal([]string{"b"}, evicted)
+
+	for key, expected := range map[string]bool{"a": true, "b": false, "c": true} {
+		exists, err := store.Exists(key)
+		is.Nil(err)
+		is.Equal(expected, exists, key)
+	}
+
+	is.Nil(store.Delete("a"))
+	is.Nil(store.SetMap("d", map[string]interface{}{"field": "value"}))
+	is.Equal([]string{"b"}, evicted)
+}
+
+func TestMemoryStoreMaxWeight(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{
+		MaxWeight: 10,
+		Weigher: func(key string, value interface{}) int {
+			return value.(int)
+		},
+	})
+	is.Nil(err)
+
+	var evicted []string
+	store.(*MemoryStore).OnEvicted(func(key string, value interface{}, reason EvictionReason) {
+		if reason == EvictionEvicted {
+			evicted = append(evicted, key)
+		}
+	})
+
+	is.Nil(store.Set("a", 4))
+	is.Nil(store.Set("b", 4))
+	is.Empty(evicted)
+
+	_, err = store.Get("a")
+	is.Nil(err)
+
+	is.Nil(store.Set("c", 3))
+	is.Equal([]string{"b"}, evicted)
+
+	// Overwriting updates the weight.
+	is.Nil(store.Set("a", 7))
+	is.Equal([]string{"b"}, evicted)
+
+	is.Nil(store.Set("a", 8))
+	is.Equal([]string{"b", "c"}, evicted)
+
+	is.Nil(store.Set("d", 11))
+	is.Equal([]string{"b", "c", "a", "d"}, evicted)
+
+	store, err = NewMemoryStoreWithOptions(&MemoryStoreOptions{MaxWeight: 15})
+	is.Nil(err)
+
+	is.Nil(store.Set("key", "value"))
+	is.Nil(store.Set("other", "value"))
+	for key, expected := range map[string]bool{"key": false, "other": true} {
+		exists, err := store.Exists(key)
+		is.Nil(err)
+		is.Equal(expected, exists, key)
+	}
+}
+
+func TestMemoryStoreShards(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{Shards: 8})
+	is.Nil(err)
+
+	testStore(t, store)
+
+	for i := 0; i < 100; i++ {
+		is.Nil(store.Set(strconv.Itoa(i), i))
+	}
+
+	keys := 0
+	is.Nil(store.(Scanner).Scan(func(item Item) error {
+		keys++
+		return nil
+	}))
+	is.Equal(100, keys)
+
+	is.Nil(store.Flush())
+
+	stats, err := store.(StatsProvider).Stats()
+	is.Nil(err)
+	is.Equal(int64(0), stats.Keys)
+
+	// Copies are taken across all the segments at once: "first" is written
+	// before "second", in a later segment, so it is never behind it.
+	memory := store.(*MemoryStore)
+
+	first, second := "", ""
+	for i := 0; first == "" || second == ""; i++ {
+		key := strconv.Itoa(i)
+		if memory.shard(key) == memory.shards[0] && first == "" {
+			first = key
+		}
+		if memory.shard(key) == memory.shards[7] && second == "" {
+			second = key
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 1; i <= 1000; i++ {
+			memory.Set(first, i)
+			memory.Set(second, i)
+		}
+	}()
+
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+
+		items := memory.items()
+		a, _ := items[first].object.(int)
+		b, _ := items[second].object.(int)
+		is.True(a >= b)
+	}
+}
+
+func TestMemoryStoreExpiration(t *testing.T) {
+	is := assert.New(t)
+
+	clock := NewManualClock(time.Now())
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{
+		Expiration: 50 * time.Millisecond,
+		Clock:      clock,
+	})
+	is.Nil(err)
+
+	memory := store.(*MemoryStore)
+
+	is.Nil(store.Set("key", "value"))
+	is.Nil(store.SetWithExpiration("forever", "value", NoExpiration))
+	is.Nil(memory.SetMapWithExpiration("map", map[string]interface{}{"field": "value"}, time.Minute))
+	is.Nil(memory.SetSliceWithExpiration("slice", []interface{}{"a"}, NoExpiration))
+
+	clock.Advance(60 * time.Millisecond)
+
+	for key, expected := range map[string]bool{"key": false, "forever": true, "map": true, "slice": true} {
+		exists, err := store.Exists(key)
+		is.Nil(err)
+		is.Equal(expected, exists, key)
+	}
+
+	is.Nil(memory.Scan(func(item Item) error {
+		if item.Key == "map" {
+			is.WithinDuration(clock.Now().Add(time.Minute-60*time.Millisecond), item.Expiration, 0)
+		} else {
+			is.True(item.Expiration.IsZero(), item.Key)
+		}
+		return nil
+	}))
+
+	store, err = NewMemoryStore(NoExpiration, 0)
+	is.Nil(err)
+
+	is.Nil(store.Set("key", "value"))
+	is.Nil(store.(*MemoryStore).Scan(func(item Item) error {
+		is.True(item.Expiration.IsZero())
+		return nil
+	}))
+}
+
+func TestMemoryStoreSlidingExpiration(t *testing.T) {
+	is := assert.New(t)
+
+	clock := NewManualClock(time.Now())
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{
+		Expiration:        time.Minute,
+		SlidingExpiration: true,
+		MaxLifetime:       3 * time.Minute,
+		HotKeys:           8,
+		Clock:             clock,
+	})
+	is.Nil(err)
+
+	memory := store.(*MemoryStore)
+	is.Nil(memory.hot)
+
+	is.Nil(store.Set("session", "value"))
+	is.Nil(store.Set("idle", "value"))
+	is.Nil(store.SetWithExpiration("forever", "value", NoExpiration))
+
+	// Each read keeps the session alive for another minute.
+	for i := 0; i < 3; i++ {
+		clock.Advance(50 * time.Second)
+
+		value, err := store.Get("session")
+		is.Nil(err)
+		is.Equal("value", value)
+	}
+
+	value, err := store.Get("idle")
+	is.Nil(err)
+	is.Nil(value)
+
+	// Sliding stops at the max lifetime, which Expire cannot extend.
+	is.Nil(memory.Expire("session", time.Hour))
+
+	clock.Advance(29 * time.Second)
+	exists, err := store.Exists("session")
+	is.Nil(err)
+	is.True(exists)
+
+	clock.Advance(2 * time.Second)
+	exists, err = store.Exists("session")
+	is.Nil(err)
+	is.False(exists)
+
+	exists, err = store.Exists("forever")
+	is.Nil(err)
+	is.False(exists)
+
+	// Expired items are swept once their slid expiration is reached.
+	is.Nil(store.Set("session", "value"))
+	clock.Advance(50 * time.Second)
+	exists, err = store.Exists("session")
+	is.Nil(err)
+	is.True(exists)
+
+	clock.Advance(50 * time.Second)
+	memory.deleteExpired()
+	is.Equal(1, memory.ItemCount())
+}
+
+func TestMemoryStoreCleanup(t *testing.T) {
+	is := assert.New(t)
+
+	for _, cleanup := range []CleanupStrategy{CleanupActive, CleanupLazy, CleanupLazyAndActive} {
+		clock := NewManualClock(time.Now())
+
+		store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{
+			Expiration:      time.Second,
+			CleanupInterval: time.Hour,
+			Cleanup:         cleanup,
+			Clock:           clock,
+		})
+		is.Nil(err)
+
+		var mu sync.Mutex
+		var expired []string
+		store.(*MemoryStore).OnEvicted(func(key string, value interface{}, reason EvictionReason) {
+			mu.Lock()
+			defer mu.Unlock()
+			expired = append(expired, key)
+		})
+
+		is.Nil(store.Set("read", "value"))
+		is.Nil(store.Set("unread", "value"))
+		clock.Advance(2 * time.Second)
+
+		value, err := store.Get("read")
+		is.Nil(err)
+		is.Nil(value)
+
+		mu.Lock()
+		if cleanup == CleanupActive {
+			is.NotContains(expired, "read", cleanup.String())
+		} else {
+			is.Equal([]string{"read"}, expired, cleanup.String())
+		}
+		mu.Unlock()
+
+		// Run the cleanup the sweeper would, if started.
+		memory := store.(*MemoryStore)
+		is.Equal(cleanup != CleanupLazy, memory.sweeper.stop != nil, cleanup.String())
+		if cleanup != CleanupLazy {
+			memory.sweep()
+		}
+
+		stats, err := store.(StatsProvider).Stats()
+		is.Nil(err)
+		if cleanup == CleanupLazy {
+			is.Equal(int64(1), stats.Keys, cleanup.String())
+		} else {
+			is.Equal(int64(0), stats.Keys, cleanup.String())
+		}
+
+		is.Nil(store.Close())
+	}
+}
+
+func TestMemoryStoreHotKeys(t *testing.T) {
+	is := assert.New(t)
+
+	clock := NewManualClock(time.Now())
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{HotKeys: 2, Clock: clock})
+	is.Nil(err)
+
+	memory := store.(*MemoryStore)
+
+	is.Nil(store.Set("key", "value"))
+	is.Nil(store.SetWithExpiration("expiring", "value", 50*time.Millisecond))
+
+	for i := 0; i < hotPromoteAfter; i++ {
+		for _, key := range []string{"key", "expiring"} {
+			value, err := store.Get(key)
+			is.Nil(err)
+			is.Equal("value", value)
+		}
+	}
+
+	_, _, found := memory.hot.get("key")
+	is.True(found)
+	_, _, found = memory.hot.get("expiring")
+	is.True(found)
+
+	// Hot keys are read while a transaction holds the store.
+	memory.txn.Lock()
+	read := make(chan interface{})
+	go func() {
+		value, _ := store.Get("key")
+		read <- value
+	}()
+	select {
+	case value := <-read:
+		is.Equal("value", value)
+	case <-time.After(time.Second):
+		t.Error("hot key read waited for the transaction")
+	}
+	memory.txn.Unlock()
+
+	// Writes replace the hot value.
+	is.Nil(store.Set("key", "changed"))
+	value, err := store.Get("key")
+	is.Nil(err)
+	is.Equal("changed", value)
+
+	clock.Advance(60 * time.Millisecond)
+	exists, err := store.Exists("expiring")
+	is.Nil(err)
+	is.False(exists)
+
+	is.Nil(store.Delete("key"))
+	exists, err = store.Exists("key")
+	is.Nil(err)
+	is.False(exists)
+}
+
+func BenchmarkMemoryStoreSet(b *testing.B) {
+	for _, shards := range []int{1, 16} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			store, _ := NewMemoryStoreWithOptions(&MemoryStoreOptions{Shards: shards})
+
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					store.Set(strconv.Itoa(i%1000), i)
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkMemoryStoreGet(b *testing.B) {
+	for _, hotKeys := range []int{0, 64} {
+		b.Run(fmt.Sprintf("hotkeys=%d", hotKeys), func(b *testing.B) {
+			store, _ := NewMemoryStoreWithOptions(&MemoryStoreOptions{HotKeys: hotKeys})
+			for i := 0; i < 16; i++ {
+				store.Set(strconv.Itoa(i), i)
+			}
+
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					store.Get(strconv.Itoa(i % 16))
+				}
+			})
+		})
+	}
+}
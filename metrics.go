@@ -0,0 +1,163 @@
+package gokvstores
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsOptions are MetricsStore options.
+type MetricsOptions struct {
+	// Namespace and Subsystem prefix the metric names.
+	Namespace string
+	Subsystem string
+
+	// ConstLabels are added to every metric (e.g. {"backend": "redis"}).
+	ConstLabels prometheus.Labels
+
+	// Buckets are the latency histogram buckets, in seconds.
+	// Defaults to prometheus.DefBuckets.
+	Buckets []float64
+}
+
+// MetricsStore is a KVStore decorator recording Prometheus metrics for each operation.
+// It implements prometheus.Collector, so it can be registered directly.
+//
+// Hit ratios are derived from the hits and misses counters, for instance:
+//
+//	rate(kvstore_hits_total[5m]) / (rate(kvstore_hits_total[5m]) + rate(kvstore_misses_total[5m]))
+//
+// When the wrapped store implements StatsProvider, its evictions, expirations
+// and rejected writes are exported as well, read from Stats when collected.
+type MetricsStore struct {
+	*interceptedStore
+
+	operations *prometheus.CounterVec
+	errors     *prometheus.CounterVec
+	hits       *prometheus.CounterVec
+	misses     *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+
+	evictions   *prometheus.Desc
+	expirations *prometheus.Desc
+	rejected    *prometheus.Desc
+
+	// registerer registered the store, which is unregistered once closed.
+	registerer prometheus.Registerer
+}
+
+// NewMetricsStore returns a MetricsStore wrapping the given store.
+func NewMetricsStore(store KVStore, options *MetricsOptions) *MetricsStore {
+	if options == nil {
+		options = &MetricsOptions{}
+	}
+
+	buckets := options.Buckets
+	if buckets == nil {
+		buckets = prometheus.DefBuckets
+	}
+
+	counter := func(name, help string) *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   options.Namespace,
+			Subsystem:   options.Subsystem,
+			Name:        name,
+			Help:        help,
+			ConstLabels: options.ConstLabels,
+		}, []string{"operation"})
+	}
+
+	m := &MetricsStore{
+		operations: counter("kvstore_operations_total", "Number of KV store operations."),
+		errors:     counter("kvstore_errors_total", "Number of KV store operations that returned an error."),
+		hits:       counter("kvstore_hits_total", "Number of KV store lookups that found the key."),
+		misses:     counter("kvstore_misses_total", "Number of KV store lookups that did not find the key."),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   options.Namespace,
+			Subsystem:   options.Subsystem,
+			Name:        "kvstore_operation_duration_seconds",
+			Help:        "Latency of KV store operations.",
+			ConstLabels: options.ConstLabels,
+			Buckets:     buckets,
+		}, []string{"operation"}),
+	}
+
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(options.Namespace, options.Subsystem, name), help, nil, options.ConstLabels)
+	}
+
+	m.evictions = desc("kvstore_evictions_total", "Number of KV store keys removed to free space.")
+	m.expirations = desc("kvstore_expirations_total", "Number of KV store keys removed once expired.")
+	m.rejected = desc("kvstore_rejected_writes_total", "Number of KV store writes refused because of a size limit.")
+
+	m.interceptedStore = &interceptedStore{store: store, intercept: m.observe}
+
+	return m
+}
+
+// observe records the metrics of a single operation.
+func (m *MetricsStore) observe(op *operation, next func() error) error {
+	start := time.Now()
+	err := next()
+
+	m.duration.WithLabelValues(op.name).Observe(time.Since(start).Seconds())
+	m.operations.WithLabelValues(op.name).Inc()
+
+	switch {
+	case err != nil:
+		m.errors.WithLabelValues(op.name).Inc()
+	case !op.read():
+	case op.hit:
+		m.hits.WithLabelValues(op.name).Inc()
+	default:
+		m.misses.WithLabelValues(op.name).Inc()
+	}
+
+	return err
+}
+
+// Close closes the wrapped store, unregistering the metrics if they were
+// registered by NewStoreFromConfig.
+func (m *MetricsStore) Close() error {
+	if m.registerer != nil {
+		m.registerer.Unregister(m)
+	}
+
+	return m.interceptedStore.Close()
+}
+
+// Describe implements prometheus.Collector.
+func (m *MetricsStore) Describe(ch chan<- *prometheus.Desc) {
+	m.operations.Describe(ch)
+	m.errors.Describe(ch)
+	m.hits.Describe(ch)
+	m.misses.Describe(ch)
+	m.duration.Describe(ch)
+	ch <- m.evictions
+	ch <- m.expirations
+	ch <- m.rejected
+}
+
+// Collect implements prometheus.Collector.
+func (m *MetricsStore) Collect(ch chan<- prometheus.Metric) {
+	m.operations.Collect(ch)
+	m.errors.Collect(ch)
+	m.hits.Collect(ch)
+	m.misses.Collect(ch)
+	m.duration.Collect(ch)
+
+	provider, ok := m.store.(StatsProvider)
+	if !ok {
+		return
+	}
+
+	stats, err := provider.Stats()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(m.evictions, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(m.evictions, prometheus.CounterValue, float64(stats.Evictions))
+	ch <- prometheus.MustNewConstMetric(m.expirations, prometheus.CounterValue, float64(stats.Expirations))
+	ch <- prometheus.MustNewConstMetric(m.rejected, prometheus.CounterValue, float64(stats.Rejected))
+}
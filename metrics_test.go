@@ -0,0 +1,62 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	testStore(t, NewMetricsStore(memory, nil))
+
+	store := NewMetricsStore(memory, nil)
+
+	is.Nil(store.Set("key", "value"))
+
+	_, err = store.Get("key")
+	is.Nil(err)
+
+	_, err = store.Get("unknown")
+	is.Nil(err)
+
+	is.Equal(float64(2), testutil.ToFloat64(store.operations.WithLabelValues("Get")))
+	is.Equal(float64(1), testutil.ToFloat64(store.hits.WithLabelValues("Get")))
+	is.Equal(float64(1), testutil.ToFloat64(store.misses.WithLabelValues("Get")))
+	is.Equal(float64(0), testutil.ToFloat64(store.errors.WithLabelValues("Get")))
+
+	// Removals are read from the stats of the wrapped store.
+	memory, err = NewMemoryStoreWithOptions(&MemoryStoreOptions{MaxEntries: 1})
+	is.Nil(err)
+
+	store = NewMetricsStore(NewSizeGuardStore(memory, &SizeGuardOptions{MaxSize: 8}), nil)
+
+	is.Nil(store.Set("a", "value"))
+	is.Nil(store.Set("b", "value"))
+	is.NotNil(store.Set("c", "oversized value"))
+
+	registry := prometheus.NewPedanticRegistry()
+	is.Nil(registry.Register(store))
+
+	families, err := registry.Gather()
+	is.Nil(err)
+
+	removals := map[string]float64{}
+	for _, family := range families {
+		if family.GetType() == dto.MetricType_COUNTER && len(family.GetMetric()) == 1 {
+			removals[family.GetName()] = family.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+
+	is.Equal(float64(1), removals["kvstore_evictions_total"])
+	is.Equal(float64(0), removals["kvstore_expirations_total"])
+	is.Equal(float64(1), removals["kvstore_rejected_writes_total"])
+}
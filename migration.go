@@ -0,0 +1,186 @@
+package gokvstores
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// MigrationStats are the read counters of a MigrationStore.
+type MigrationStats struct {
+	// Reads is the number of lookups.
+	Reads uint64
+
+	// Fallbacks is the number of lookups missing in the new store.
+	Fallbacks uint64
+
+	// FallbackHits is the number of lookups served by the old store.
+	FallbackHits uint64
+}
+
+// MigrationStore is a KVStore migrating data from an old store to a new one
+// without downtime: writes go to both stores, reads go to the new store and
+// fall back to the old one on miss.
+//
+// Once FallbackHits stops increasing, the old store can be retired.
+type MigrationStore struct {
+	old KVStore
+	new KVStore
+
+	reads        uint64
+	fallbacks    uint64
+	fallbackHits uint64
+}
+
+// NewMigrationStore returns a MigrationStore from the old store to the new one.
+func NewMigrationStore(oldStore KVStore, newStore KVStore) *MigrationStore {
+	return &MigrationStore{old: oldStore, new: newStore}
+}
+
+// Stats returns the read counters.
+func (m *MigrationStore) Stats() MigrationStats {
+	return MigrationStats{
+		Reads:        atomic.LoadUint64(&m.reads),
+		Fallbacks:    atomic.LoadUint64(&m.fallbacks),
+		FallbackHits: atomic.LoadUint64(&m.fallbackHits),
+	}
+}
+
+// read runs a lookup against the new store, then the old one on miss.
+func (m *MigrationStore) read(lookup func(store KVStore) (bool, error)) error {
+	atomic.AddUint64(&m.reads, 1)
+
+	found, err := lookup(m.new)
+	if err != nil || found {
+		return err
+	}
+
+	atomic.AddUint64(&m.fallbacks, 1)
+
+	found, err = lookup(m.old)
+	if err == nil && found {
+		atomic.AddUint64(&m.fallbackHits, 1)
+	}
+
+	return err
+}
+
+// write runs a write against both stores.
+func (m *MigrationStore) write(write func(store KVStore) error) error {
+	if err := write(m.old); err != nil {
+		return err
+	}
+
+	return write(m.new)
+}
+
+// Get returns value for the given key.
+func (m *MigrationStore) Get(key string) (interface{}, error) {
+	var value interface{}
+
+	err := m.read(func(store KVStore) (found bool, err error) {
+		value, err = store.Get(key)
+		return value != nil, err
+	})
+
+	return value, err
+}
+
+// Set sets value for the given key.
+func (m *MigrationStore) Set(key string, value interface{}) error {
+	return m.write(func(store KVStore) error {
+		return store.Set(key, value)
+	})
+}
+
+// SetWithExpiration sets value for the given key with a specific expiration.
+func (m *MigrationStore) SetWithExpiration(key string, value interface{}, expiration time.Duration) error {
+	return m.write(func(store KVStore) error {
+		return store.SetWithExpiration(key, value, expiration)
+	})
+}
+
+// GetMap returns map for the given key.
+func (m *MigrationStore) GetMap(key string) (map[string]interface{}, error) {
+	var value map[string]interface{}
+
+	err := m.read(func(store KVStore) (found bool, err error) {
+		value, err = store.GetMap(key)
+		return value != nil, err
+	})
+
+	return value, err
+}
+
+// SetMap sets map for the given key.
+func (m *MigrationStore) SetMap(key string, value map[string]interface{}) error {
+	return m.write(func(store KVStore) error {
+		return store.SetMap(key, value)
+	})
+}
+
+// GetSlice returns slice for the given key.
+func (m *MigrationStore) GetSlice(key string) ([]interface{}, error) {
+	var value []interface{}
+
+	err := m.read(func(store KVStore) (found bool, err error) {
+		value, err = store.GetSlice(key)
+		return value != nil, err
+	})
+
+	return value, err
+}
+
+// SetSlice sets slice for the given key.
+func (m *MigrationStore) SetSlice(key string, value []interface{}) error {
+	return m.write(func(store KVStore) error {
+		return store.SetSlice(key, value)
+	})
+}
+
+// AppendSlice appends values to an existing slice.
+// If key does not exist, creates slice.
+func (m *MigrationStore) AppendSlice(key string, values ...interface{}) error {
+	return m.write(func(store KVStore) error {
+		return store.AppendSlice(key, values...)
+	})
+}
+
+// Exists checks if the given key exists.
+func (m *MigrationStore) Exists(key string) (bool, error) {
+	var exists bool
+
+	err := m.read(func(store KVStore) (found bool, err error) {
+		exists, err = store.Exists(key)
+		return exists, err
+	})
+
+	return exists, err
+}
+
+// Delete deletes the given key.
+func (m *MigrationStore) Delete(key string) error {
+	return m.write(func(store KVStore) error {
+		return store.Delete(key)
+	})
+}
+
+// Flush flushes both stores.
+func (m *MigrationStore) Flush() error {
+	return m.write(func(store KVStore) error {
+		return store.Flush()
+	})
+}
+
+// Close closes both stores.
+func (m *MigrationStore) Close() error {
+	return m.write(func(store KVStore) error {
+		return store.Close()
+	})
+}
+
+// Ping checks both stores are reachable.
+func (m *MigrationStore) Ping() error {
+	return m.write(func(store KVStore) error {
+		return store.Ping()
+	})
+}
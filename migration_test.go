@@ -0,0 +1,48 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrationStore(t *testing.T) {
+	is := assert.New(t)
+
+	oldStore, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	newStore, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	testStore(t, NewMigrationStore(oldStore, newStore))
+
+	is.Nil(oldStore.Set("legacy", "value"))
+
+	store := NewMigrationStore(oldStore, newStore)
+
+	is.Nil(store.Set("key", "value"))
+
+	v, err := newStore.Get("key")
+	is.Nil(err)
+	is.Equal("value", v)
+
+	v, err = oldStore.Get("key")
+	is.Nil(err)
+	is.Equal("value", v)
+
+	v, err = store.Get("key")
+	is.Nil(err)
+	is.Equal("value", v)
+
+	v, err = store.Get("legacy")
+	is.Nil(err)
+	is.Equal("value", v)
+
+	v, err = store.Get("unknown")
+	is.Nil(err)
+	is.Nil(v)
+
+	is.Equal(MigrationStats{Reads: 3, Fallbacks: 2, FallbackHits: 1}, store.Stats())
+}
@@ -0,0 +1,183 @@
+package gokvstores
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// AlertKind is the metric an alert is about.
+type AlertKind int
+
+// Alert kinds.
+const (
+	AlertErrorRate AlertKind = iota
+	AlertLatency
+)
+
+// String returns the alert kind name.
+func (k AlertKind) String() string {
+	switch k {
+	case AlertErrorRate:
+		return "error rate"
+	case AlertLatency:
+		return "p99 latency"
+	}
+	return "unknown"
+}
+
+// Alert is a threshold crossing reported by a MonitorStore.
+type Alert struct {
+	Kind AlertKind
+
+	// Firing is true when the threshold is exceeded, false when the metric
+	// went back under it.
+	Firing bool
+
+	// Value and Threshold are the measured value and its threshold: a fraction
+	// of operations for error rates, seconds for latencies.
+	Value     float64
+	Threshold float64
+}
+
+// MonitorOptions are MonitorStore options.
+type MonitorOptions struct {
+	// Window is the period metrics are computed over. Defaults to a minute.
+	Window time.Duration
+
+	// MinOperations is the number of operations a window needs to be
+	// evaluated. Defaults to 10. Firing alerts recover, with a zero Value,
+	// at the end of windows with fewer operations.
+	MinOperations int
+
+	// ErrorRate is the fraction (between 0 and 1) of failing operations
+	// firing an alert. Zero disables error rate alerts.
+	ErrorRate float64
+
+	// Latency is the p99 latency firing an alert. Zero disables latency alerts.
+	Latency time.Duration
+
+	// OnAlert is called when an alert fires or recovers.
+	OnAlert func(alert Alert)
+}
+
+// MonitorStore is a KVStore decorator calling a hook when the error rate or
+// the p99 latency of the operations over a window crosses a threshold, so
+// applications can switch to a degraded mode.
+//
+// Windows are evaluated by the first operation following their end.
+type MonitorStore struct {
+	*interceptedStore
+
+	options MonitorOptions
+	now     func() time.Time
+
+	mu        sync.Mutex
+	start     time.Time
+	latencies []time.Duration
+	errors    int
+	firing    map[AlertKind]bool
+}
+
+// NewMonitorStore returns a MonitorStore wrapping the given store.
+func NewMonitorStore(store KVStore, options *MonitorOptions) *MonitorStore {
+	if options == nil {
+		options = &MonitorOptions{}
+	}
+
+	m := &MonitorStore{
+		options: *options,
+		now:     time.Now,
+		firing:  map[AlertKind]bool{},
+	}
+
+	if m.options.Window <= 0 {
+		m.options.Window = time.Minute
+	}
+
+	if m.options.MinOperations <= 0 {
+		m.options.MinOperations = 10
+	}
+
+	m.start = m.now()
+	m.interceptedStore = &interceptedStore{store: store, intercept: m.monitor}
+
+	return m
+}
+
+// monitor records a single operation.
+func (m *MonitorStore) monitor(op *operation, next func() error) error {
+	start := m.now()
+	err := next()
+	end := m.now()
+
+	m.mu.Lock()
+
+	var alerts []Alert
+	if end.Sub(m.start) >= m.options.Window {
+		alerts = m.evaluate()
+		m.start, m.latencies, m.errors = end, m.latencies[:0], 0
+	}
+
+	m.latencies = append(m.latencies, end.Sub(start))
+	if err != nil {
+		m.errors++
+	}
+
+	m.mu.Unlock()
+
+	if m.options.OnAlert != nil {
+		for _, alert := range alerts {
+			m.options.OnAlert(alert)
+		}
+	}
+
+	return err
+}
+
+// evaluate returns the alerts changing state at the end of the current window.
+func (m *MonitorStore) evaluate() []Alert {
+	var alerts []Alert
+
+	// Windows without enough operations can't tell whether an alert still
+	// holds: firing alerts recover instead of staying stuck.
+	total := len(m.latencies)
+	if total < m.options.MinOperations {
+		for _, kind := range []AlertKind{AlertErrorRate, AlertLatency} {
+			if m.firing[kind] {
+				m.firing[kind] = false
+				alerts = append(alerts, Alert{Kind: kind, Threshold: m.threshold(kind)})
+			}
+		}
+		return alerts
+	}
+
+	check := func(kind AlertKind, value, threshold float64) {
+		if threshold <= 0 {
+			return
+		}
+
+		if firing := value > threshold; firing != m.firing[kind] {
+			m.firing[kind] = firing
+			alerts = append(alerts, Alert{Kind: kind, Firing: firing, Value: value, Threshold: threshold})
+		}
+	}
+
+	check(AlertErrorRate, float64(m.errors)/float64(total), m.options.ErrorRate)
+
+	if m.options.Latency > 0 {
+		sort.Slice(m.latencies, func(i, j int) bool { return m.latencies[i] < m.latencies[j] })
+		p99 := m.latencies[int(0.99*float64(total-1))]
+		check(AlertLatency, p99.Seconds(), m.options.Latency.Seconds())
+	}
+
+	return alerts
+}
+
+// threshold returns the threshold of the given alert kind.
+func (m *MonitorStore) threshold(kind AlertKind) float64 {
+	if kind == AlertLatency {
+		return m.options.Latency.Seconds()
+	}
+	return m.options.ErrorRate
+}
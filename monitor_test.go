@@ -0,0 +1,132 @@
+package gokvstores
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonitorStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	testStore(t, NewMonitorStore(memory, nil))
+
+	is.Nil(memory.Set("key", "value"))
+
+	flaky := &flakyStore{KVStore: memory, err: syscall.ECONNRESET, failures: 5}
+
+	alerts := []Alert{}
+	store := NewMonitorStore(flaky, &MonitorOptions{
+		Window:        time.Minute,
+		MinOperations: 5,
+		ErrorRate:     0.1,
+		OnAlert:       func(alert Alert) { alerts = append(alerts, alert) },
+	})
+
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	store.start = now
+
+	// First window: 5 failures out of 10 operations.
+
+	for i := 0; i < 10; i++ {
+		store.Get("key")
+	}
+	is.Empty(alerts)
+
+	now = now.Add(time.Minute)
+	store.Get("key")
+
+	is.Equal([]Alert{{Kind: AlertErrorRate, Firing: true, Value: 0.5, Threshold: 0.1}}, alerts)
+
+	// Second window: no failure.
+
+	for i := 0; i < 9; i++ {
+		store.Get("key")
+	}
+
+	now = now.Add(time.Minute)
+	store.Get("key")
+
+	is.Len(alerts, 2)
+	is.Equal(Alert{Kind: AlertErrorRate, Firing: false, Value: 0, Threshold: 0.1}, alerts[1])
+	is.Equal("error rate", AlertErrorRate.String())
+}
+
+func TestMonitorStoreLowTraffic(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	flaky := &flakyStore{KVStore: memory, err: syscall.ECONNRESET, failures: 5}
+
+	alerts := []Alert{}
+	store := NewMonitorStore(flaky, &MonitorOptions{
+		Window:        time.Minute,
+		MinOperations: 5,
+		ErrorRate:     0.1,
+		OnAlert:       func(alert Alert) { alerts = append(alerts, alert) },
+	})
+
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	store.start = now
+
+	// First window: 5 failures out of 5 operations.
+
+	for i := 0; i < 5; i++ {
+		store.Get("key")
+	}
+
+	now = now.Add(time.Minute)
+	store.Get("key")
+
+	is.Equal([]Alert{{Kind: AlertErrorRate, Firing: true, Value: 1, Threshold: 0.1}}, alerts)
+
+	// Second window: too few operations to be evaluated.
+
+	now = now.Add(time.Minute)
+	store.Get("key")
+
+	is.Len(alerts, 2)
+	is.Equal(Alert{Kind: AlertErrorRate, Firing: false, Threshold: 0.1}, alerts[1])
+
+	// Third window: still too few operations, the alert already recovered.
+
+	now = now.Add(time.Minute)
+	store.Get("key")
+
+	is.Len(alerts, 2)
+}
+
+func TestMonitorStoreLatency(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	slow := &slowStore{KVStore: memory, delay: 5 * time.Millisecond}
+
+	alerts := []Alert{}
+	store := NewMonitorStore(slow, &MonitorOptions{
+		Window:        50 * time.Millisecond,
+		MinOperations: 1,
+		Latency:       time.Millisecond,
+		OnAlert:       func(alert Alert) { alerts = append(alerts, alert) },
+	})
+
+	for len(alerts) == 0 {
+		_, err := store.Get("key")
+		is.Nil(err)
+	}
+
+	is.Equal(AlertLatency, alerts[0].Kind)
+	is.True(alerts[0].Firing)
+	is.True(alerts[0].Value >= 0.005)
+}
@@ -0,0 +1,69 @@
+package gokvstores
+
+import "sync"
+
+// task is a unit of work run by parallel, reporting whether it did anything.
+type task func() (bool, error)
+
+// parallel runs the tasks sent by produce on concurrency goroutines, 8 if
+// zero or negative. It stops at the first failure, which send then returns,
+// and returns the number of tasks which did something. onProgress, if set, is
+// called after each of them with the number so far. Calls are serialized.
+func parallel(concurrency int, onProgress func(done int), produce func(send func(task task) error) error) (int, error) {
+	if concurrency <= 0 {
+		concurrency = 8
+	}
+
+	var (
+		mu       sync.Mutex
+		done     int
+		firstErr error
+		wg       sync.WaitGroup
+	)
+
+	failed := make(chan struct{})
+	tasks := make(chan task)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for run := range tasks {
+				ok, err := run()
+
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+						close(failed)
+					}
+				} else if ok {
+					done++
+					if onProgress != nil {
+						onProgress(done)
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	err := produce(func(run task) error {
+		select {
+		case tasks <- run:
+			return nil
+		case <-failed:
+			return firstErr
+		}
+	})
+
+	close(tasks)
+	wg.Wait()
+
+	if firstErr != nil {
+		return done, firstErr
+	}
+
+	return done, err
+}
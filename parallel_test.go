@@ -0,0 +1,46 @@
+package gokvstores
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParallel(t *testing.T) {
+	is := assert.New(t)
+
+	var progress []int
+
+	done, err := parallel(4, func(done int) { progress = append(progress, done) }, func(send func(task task) error) error {
+		for i := 0; i < 10; i++ {
+			skipped := i%2 == 0
+			if err := send(func() (bool, error) { return !skipped, nil }); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	is.Nil(err)
+	is.Equal(5, done)
+	is.Equal([]int{1, 2, 3, 4, 5}, progress)
+
+	failure := errors.New("failure")
+
+	done, err = parallel(1, nil, func(send func(task task) error) error {
+		for i := 0; ; i++ {
+			n := i
+			err := send(func() (bool, error) {
+				if n == 3 {
+					return false, failure
+				}
+				return n < 3, nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+	})
+	is.Equal(failure, err)
+	is.Equal(3, done)
+}
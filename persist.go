@@ -0,0 +1,103 @@
+package gokvstores
+
+import (
+	"encoding/gob"
+	"os"
+	"sync"
+	"time"
+)
+
+// snapshotter saves the items of a MemoryStore to a file, periodically and
+// when closed.
+type snapshotter struct {
+	path     string
+	interval time.Duration
+	onError  func(err error)
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+// run saves the store every interval until closed.
+func (s *snapshotter) run(c *MemoryStore) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := c.saveSnapshot(s.path); err != nil && s.onError != nil {
+				s.onError(err)
+			}
+		}
+	}
+}
+
+// close stops the periodic saves, and saves the store a last time.
+func (s *snapshotter) close(c *MemoryStore) error {
+	var err error
+	s.once.Do(func() {
+		close(s.stop)
+		s.wg.Wait()
+
+		err = c.saveSnapshot(s.path)
+	})
+	return err
+}
+
+// isInternalValue reports whether the value is one of the MemoryStore types
+// backing HyperLogLog, Geo and filter keys, which are not saved.
+func isInternalValue(value interface{}) bool {
+	switch value.(type) {
+	case *hyperLogLog, *geoSet, *bloomFilter, *cuckooFilter:
+		return true
+	}
+	return false
+}
+
+// saveSnapshot writes the items of the store to the file at path, in the
+// Backup format. The file is replaced once fully written.
+func (c *MemoryStore) saveSnapshot(path string) error {
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return err
+	}
+
+	enc := gob.NewEncoder(f)
+	err = enc.Encode(backupHeader{Version: backupVersion, Time: time.Now()})
+	if err == nil {
+		err = c.Scan(func(item Item) error {
+			if isInternalValue(item.Value) {
+				return nil
+			}
+			return enc.Encode(item)
+		})
+	}
+
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+
+	return (&dirBackupFile{File: f, path: path}).Close()
+}
+
+// loadSnapshot loads the items saved to the file at path, if it exists.
+func (c *MemoryStore) loadSnapshot(path string) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return Restore(c, f, nil)
+}
@@ -0,0 +1,63 @@
+package gokvstores
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreSnapshotPath(t *testing.T) {
+	is := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{
+		SnapshotPath:     path,
+		SnapshotInterval: 50 * time.Millisecond,
+	})
+	is.Nil(err)
+
+	is.Nil(store.Set("key", "value"))
+	is.Nil(store.SetWithExpiration("expiring", "value", time.Minute))
+	is.Nil(store.SetWithExpiration("short", "value", 100*time.Millisecond))
+	is.Nil(store.SetMap("map", map[string]interface{}{"field": "value"}))
+
+	_, err = store.(HyperLogLog).PFAdd("visitors", "a")
+	is.Nil(err)
+
+	time.Sleep(120 * time.Millisecond)
+
+	_, err = os.Stat(path)
+	is.Nil(err)
+
+	is.Nil(store.Close())
+
+	restored, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{SnapshotPath: path})
+	is.Nil(err)
+
+	value, err := restored.Get("key")
+	is.Nil(err)
+	is.Equal("value", value)
+
+	values, err := restored.GetMap("map")
+	is.Nil(err)
+	is.Equal(map[string]interface{}{"field": "value"}, values)
+
+	for key, expected := range map[string]bool{"expiring": true, "short": false, "visitors": false} {
+		exists, err := restored.Exists(key)
+		is.Nil(err)
+		is.Equal(expected, exists, key)
+	}
+
+	is.Nil(restored.(Scanner).Scan(func(item Item) error {
+		if item.Key == "expiring" {
+			is.WithinDuration(time.Now().Add(time.Minute), item.Expiration, time.Second)
+		}
+		return nil
+	}))
+
+	is.Nil(restored.Close())
+}
@@ -0,0 +1,36 @@
+package gokvstores
+
+// Pinner is implemented by stores able to protect keys from eviction.
+type Pinner interface {
+	// Pin protects the given key from being evicted to free space, whether
+	// it is already set or not. It can still be deleted or expire.
+	Pin(key string) error
+
+	// Unpin makes the given key evictable again.
+	Unpin(key string) error
+}
+
+// Pin protects the given key from the eviction of the least recently used
+// keys beyond MaxEntries or MaxWeight, so that critical entries, such as
+// feature flags, survive while the others churn. Pinned keys still count
+// towards these limits. The key stays pinned once deleted, expired or
+// flushed, until Unpin. Without MaxEntries and MaxWeight, keys are never
+// evicted and Pin does nothing.
+func (c *MemoryStore) Pin(key string) error {
+	if c.lru != nil {
+		c.lru.pin(key)
+	}
+	return nil
+}
+
+// Unpin makes the given key evictable again, as the most recently used one,
+// evicting the least recently used keys if pinned ones took their space.
+func (c *MemoryStore) Unpin(key string) error {
+	if c.lru != nil {
+		c.txn.RLock()
+		defer c.txn.RUnlock()
+
+		c.evictLRU(c.lru.unpin(key))
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package gokvstores
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStorePin(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{MaxEntries: 3})
+	is.Nil(err)
+
+	var pinner Pinner = store.(*MemoryStore)
+
+	is.Nil(store.Set("flags", "on"))
+	is.Nil(pinner.Pin("flags"))
+	is.Nil(pinner.Pin("config"))
+	is.Nil(store.Set("config", "value"))
+
+	for i := 0; i < 10; i++ {
+		is.Nil(store.Set(strconv.Itoa(i), i))
+	}
+
+	for key, expected := range map[string]bool{"flags": true, "config": true, "8": false, "9": true} {
+		exists, err := store.Exists(key)
+		is.Nil(err)
+		is.Equal(expected, exists, key)
+	}
+
+	// A pinned key stays pinned once deleted.
+	is.Nil(store.Delete("config"))
+	is.Nil(store.Set("0", 0))
+	is.Nil(store.Set("config", "value"))
+	is.Nil(store.Set("1", 1))
+
+	for key, expected := range map[string]bool{"flags": true, "config": true, "0": false, "1": true} {
+		exists, err := store.Exists(key)
+		is.Nil(err)
+		is.Equal(expected, exists, key)
+	}
+
+	// Unpinned keys are evicted as the others.
+	is.Nil(pinner.Unpin("flags"))
+	is.Nil(store.Set("2", 2))
+	is.Nil(store.Set("3", 3))
+
+	for key, expected := range map[string]bool{"flags": false, "config": true, "2": true, "3": true} {
+		exists, err := store.Exists(key)
+		is.Nil(err)
+		is.Equal(expected, exists, key)
+	}
+}
@@ -0,0 +1,157 @@
+package gokvstores
+
+import "time"
+
+// PipelineResult is the result of an operation queued in a Pipeline. It is
+// set once the pipeline is executed.
+type PipelineResult struct {
+	value interface{}
+	err   error
+}
+
+// Value returns the value read: an interface{} for Get, a map[string]interface{}
+// for GetMap, a []interface{} for GetSlice and a bool for Exists.
+func (r *PipelineResult) Value() interface{} {
+	return r.value
+}
+
+// Err returns the error of the operation.
+func (r *PipelineResult) Err() error {
+	return r.err
+}
+
+// Pipeline queues operations and sends them together to the store.
+type Pipeline interface {
+	Get(key string) *PipelineResult
+	Set(key string, value interface{}) *PipelineResult
+	SetWithExpiration(key string, value interface{}, expiration time.Duration) *PipelineResult
+	GetMap(key string) *PipelineResult
+	SetMap(key string, value map[string]interface{}) *PipelineResult
+	GetSlice(key string) *PipelineResult
+	SetSlice(key string, values []interface{}) *PipelineResult
+	AppendSlice(key string, values ...interface{}) *PipelineResult
+	Exists(key string) *PipelineResult
+	Delete(key string) *PipelineResult
+
+	// Exec executes the queued operations, in order, and empties the queue.
+	// It returns the first error of the operations.
+	Exec() error
+}
+
+// Pipeliner is implemented by stores able to execute several operations in
+// a single round trip.
+type Pipeliner interface {
+	// Pipeline returns a new, empty pipeline.
+	Pipeline() Pipeline
+}
+
+// NewPipeline returns a pipeline for the given store: its own if it
+// implements Pipeliner, otherwise one running the queued operations one after
+// the other on Exec.
+func NewPipeline(store KVStore) Pipeline {
+	if pipeliner, ok := store.(Pipeliner); ok {
+		return pipeliner.Pipeline()
+	}
+	return &batchPipeline{store: store}
+}
+
+// batchedOperation is an operation queued in a batchPipeline.
+type batchedOperation struct {
+	run    func() (interface{}, error)
+	result *PipelineResult
+}
+
+// batchPipeline is the Pipeline of stores without native pipelining.
+type batchPipeline struct {
+	store KVStore
+	queue []batchedOperation
+}
+
+// enqueue queues an operation, returning its result.
+func (p *batchPipeline) enqueue(run func() (interface{}, error)) *PipelineResult {
+	result := &PipelineResult{}
+	p.queue = append(p.queue, batchedOperation{run: run, result: result})
+	return result
+}
+
+// write queues a write operation.
+func (p *batchPipeline) write(run func() error) *PipelineResult {
+	return p.enqueue(func() (interface{}, error) {
+		return nil, run()
+	})
+}
+
+func (p *batchPipeline) Get(key string) *PipelineResult {
+	return p.enqueue(func() (interface{}, error) {
+		return p.store.Get(key)
+	})
+}
+
+func (p *batchPipeline) Set(key string, value interface{}) *PipelineResult {
+	return p.write(func() error {
+		return p.store.Set(key, value)
+	})
+}
+
+func (p *batchPipeline) SetWithExpiration(key string, value interface{}, expiration time.Duration) *PipelineResult {
+	return p.write(func() error {
+		return p.store.SetWithExpiration(key, value, expiration)
+	})
+}
+
+func (p *batchPipeline) GetMap(key string) *PipelineResult {
+	return p.enqueue(func() (interface{}, error) {
+		return p.store.GetMap(key)
+	})
+}
+
+func (p *batchPipeline) SetMap(key string, value map[string]interface{}) *PipelineResult {
+	return p.write(func() error {
+		return p.store.SetMap(key, value)
+	})
+}
+
+func (p *batchPipeline) GetSlice(key string) *PipelineResult {
+	return p.enqueue(func() (interface{}, error) {
+		return p.store.GetSlice(key)
+	})
+}
+
+func (p *batchPipeline) SetSlice(key string, values []interface{}) *PipelineResult {
+	return p.write(func() error {
+		return p.store.SetSlice(key, values)
+	})
+}
+
+func (p *batchPipeline) AppendSlice(key string, values ...interface{}) *PipelineResult {
+	return p.write(func() error {
+		return p.store.AppendSlice(key, values...)
+	})
+}
+
+func (p *batchPipeline) Exists(key string) *PipelineResult {
+	return p.enqueue(func() (interface{}, error) {
+		return p.store.Exists(key)
+	})
+}
+
+func (p *batchPipeline) Delete(key string) *PipelineResult {
+	return p.write(func() error {
+		return p.store.Delete(key)
+	})
+}
+
+func (p *batchPipeline) Exec() error {
+	queue := p.queue
+	p.queue = nil
+
+	var err error
+	for _, op := range queue {
+		op.result.value, op.result.err = op.run()
+		if err == nil {
+			err = op.result.err
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,76 @@
+package gokvstores
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingDeleteStore is a KVStore whose Delete always fails with err.
+type failingDeleteStore struct {
+	KVStore
+	err error
+}
+
+func (s *failingDeleteStore) Delete(key string) error {
+	return s.err
+}
+
+func testPipeline(t *testing.T, store KVStore) {
+	is := assert.New(t)
+
+	is.Nil(store.Flush())
+
+	pipe := NewPipeline(store)
+
+	set := pipe.Set("key", "value")
+	setMap := pipe.SetMap("map", map[string]interface{}{"language": "go"})
+	appended := pipe.AppendSlice("slice", "a", "b")
+	get := pipe.Get("key")
+	getMap := pipe.GetMap("map")
+	getSlice := pipe.GetSlice("slice")
+	exists := pipe.Exists("unknown")
+	missing := pipe.Get("unknown")
+
+	is.Nil(get.Value())
+
+	is.Nil(pipe.Exec())
+
+	is.Nil(set.Err())
+	is.Nil(setMap.Err())
+	is.Nil(appended.Err())
+	is.Equal("value", get.Value())
+	is.Equal(map[string]interface{}{"language": "go"}, getMap.Value())
+	is.Equal([]string{"a", "b"}, stringSlice(getSlice.Value().([]interface{})))
+	is.Equal(false, exists.Value())
+	is.Nil(missing.Value())
+
+	deleted := pipe.Delete("key")
+	is.Nil(pipe.Exec())
+	is.Nil(deleted.Err())
+
+	v, err := store.Get("key")
+	is.Nil(err)
+	is.Nil(v)
+}
+
+func TestPipeline(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	testPipeline(t, memory)
+
+	failure := errors.New("failure")
+	pipe := NewPipeline(&failingDeleteStore{KVStore: memory, err: failure})
+
+	deleted := pipe.Delete("key")
+	set := pipe.Set("key", "value")
+
+	is.Equal(failure, pipe.Exec())
+	is.Equal(failure, deleted.Err())
+	is.Nil(set.Err())
+}
@@ -0,0 +1,123 @@
+package gokvstores
+
+import (
+	"strings"
+	"sync"
+)
+
+// otherPrefix groups the prefixes exceeding PrefixStatsOptions.MaxPrefixes.
+const otherPrefix = "(other)"
+
+// KeyPrefix returns the part of the key before its first colon, the whole key
+// if it has none. It is the default prefix extraction of PrefixStatsStore.
+func KeyPrefix(key string) string {
+	if i := strings.IndexByte(key, ':'); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
+
+// PrefixStats are the lookup counters of a key prefix.
+type PrefixStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// HitRatio returns the fraction of lookups which found the key, zero without lookups.
+func (s PrefixStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// PrefixStatsOptions are PrefixStatsStore options.
+type PrefixStatsOptions struct {
+	// Prefix extracts the prefix of a key. Defaults to KeyPrefix.
+	Prefix func(key string) string
+
+	// MaxPrefixes caps the number of tracked prefixes, the next ones being
+	// counted together as "(other)". Defaults to 1000.
+	MaxPrefixes int
+}
+
+// PrefixStatsStore is a KVStore decorator counting hits and misses per key
+// prefix, to compare the effectiveness of cache namespaces.
+type PrefixStatsStore struct {
+	*interceptedStore
+
+	options PrefixStatsOptions
+
+	mu    sync.Mutex
+	stats map[string]*PrefixStats
+}
+
+// NewPrefixStatsStore returns a PrefixStatsStore wrapping the given store.
+func NewPrefixStatsStore(store KVStore, options *PrefixStatsOptions) *PrefixStatsStore {
+	if options == nil {
+		options = &PrefixStatsOptions{}
+	}
+
+	p := &PrefixStatsStore{
+		options: *options,
+		stats:   map[string]*PrefixStats{},
+	}
+
+	if p.options.Prefix == nil {
+		p.options.Prefix = KeyPrefix
+	}
+
+	if p.options.MaxPrefixes <= 0 {
+		p.options.MaxPrefixes = 1000
+	}
+
+	p.interceptedStore = &interceptedStore{store: store, intercept: p.count}
+
+	return p
+}
+
+// count records the outcome of a successful lookup.
+func (p *PrefixStatsStore) count(op *operation, next func() error) error {
+	err := next()
+	if err != nil || !op.read() {
+		return err
+	}
+
+	prefix := p.options.Prefix(op.key)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats, ok := p.stats[prefix]
+	if !ok {
+		if len(p.stats) >= p.options.MaxPrefixes {
+			prefix = otherPrefix
+		}
+
+		if stats, ok = p.stats[prefix]; !ok {
+			stats = &PrefixStats{}
+			p.stats[prefix] = stats
+		}
+	}
+
+	if op.hit {
+		stats.Hits++
+	} else {
+		stats.Misses++
+	}
+
+	return nil
+}
+
+// PrefixStats returns the counters of each prefix.
+func (p *PrefixStatsStore) PrefixStats() map[string]PrefixStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make(map[string]PrefixStats, len(p.stats))
+	for prefix, s := range p.stats {
+		stats[prefix] = *s
+	}
+
+	return stats
+}
@@ -0,0 +1,41 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixStatsStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	testStore(t, NewPrefixStatsStore(memory, nil))
+
+	store := NewPrefixStatsStore(memory, &PrefixStatsOptions{MaxPrefixes: 2})
+
+	is.Nil(store.Set("user:1", "value"))
+
+	for _, key := range []string{"user:1", "user:1", "user:2", "session:1", "page:1", "other"} {
+		_, err := store.Get(key)
+		is.Nil(err)
+	}
+
+	_, err = store.Exists("user:1")
+	is.Nil(err)
+
+	stats := store.PrefixStats()
+	is.Equal(map[string]PrefixStats{
+		"user":    {Hits: 3, Misses: 1},
+		"session": {Misses: 1},
+		"(other)": {Misses: 2},
+	}, stats)
+	is.Equal(0.75, stats["user"].HitRatio())
+	is.Equal(float64(0), PrefixStats{}.HitRatio())
+
+	is.Equal("user", KeyPrefix("user:1:name"))
+	is.Equal("key", KeyPrefix("key"))
+}
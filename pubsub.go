@@ -0,0 +1,148 @@
+package gokvstores
+
+import (
+	"context"
+	"sync"
+)
+
+// subscriptionBufferSize is the capacity of Subscription channels.
+const subscriptionBufferSize = 64
+
+// Message is a message published on a channel.
+type Message struct {
+	Channel string
+	Payload string
+}
+
+// PubSub is implemented by stores able to broadcast messages.
+type PubSub interface {
+	// Publish sends the message to the subscribers of the channel.
+	Publish(channel, message string) error
+
+	// Subscribe returns a subscription receiving the messages published on
+	// the given channels.
+	Subscribe(channels ...string) (*Subscription, error)
+}
+
+// Subscription delivers the messages published on channels.
+type Subscription struct {
+	messages chan Message
+	stop     func() error
+}
+
+// Messages returns the channel messages are delivered on. It is closed once
+// the subscription is closed.
+func (s *Subscription) Messages() <-chan Message {
+	return s.messages
+}
+
+// Close ends the subscription.
+func (s *Subscription) Close() error {
+	return s.stop()
+}
+
+// ----------------------------------------------------------------------------
+// Memory
+// ----------------------------------------------------------------------------
+
+// messageBus is the in-process PubSub of a MemoryStore. Messages are dropped
+// for subscriptions whose channel is full, so a slow reader never blocks publishers.
+type messageBus struct {
+	mu            sync.RWMutex
+	subscriptions map[*Subscription]map[string]bool
+}
+
+// Publish sends the message to the subscribers of the channel.
+func (c *MemoryStore) Publish(channel, message string) error {
+	c.bus.mu.RLock()
+	defer c.bus.mu.RUnlock()
+
+	for s, channels := range c.bus.subscriptions {
+		if !channels[channel] {
+			continue
+		}
+
+		select {
+		case s.messages <- Message{Channel: channel, Payload: message}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Subscribe returns a subscription receiving the messages published on the
+// given channels of this store. Messages are dropped while its channel is full.
+func (c *MemoryStore) Subscribe(channels ...string) (*Subscription, error) {
+	s := &Subscription{messages: make(chan Message, subscriptionBufferSize)}
+
+	set := make(map[string]bool, len(channels))
+	for _, channel := range channels {
+		set[channel] = true
+	}
+
+	var once sync.Once
+	s.stop = func() error {
+		once.Do(func() {
+			c.bus.mu.Lock()
+			delete(c.bus.subscriptions, s)
+			c.bus.mu.Unlock()
+
+			close(s.messages)
+		})
+		return nil
+	}
+
+	c.bus.mu.Lock()
+	if c.bus.subscriptions == nil {
+		c.bus.subscriptions = map[*Subscription]map[string]bool{}
+	}
+	c.bus.subscriptions[s] = set
+	c.bus.mu.Unlock()
+
+	return s, nil
+}
+
+// ----------------------------------------------------------------------------
+// Redis
+// ----------------------------------------------------------------------------
+
+// Publish sends the message to the subscribers of the channel.
+func (r *RedisStore) Publish(channel, message string) error {
+	return r.client.Publish(r.ctx, channel, message).Err()
+}
+
+// Subscribe returns a subscription receiving the messages published on the
+// given channels. Messages are dropped while its channel is full.
+func (r *RedisStore) Subscribe(channels ...string) (*Subscription, error) {
+	pubsub := r.client.Subscribe(r.ctx, channels...)
+	if _, err := pubsub.Receive(r.ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	s := &Subscription{
+		messages: make(chan Message, subscriptionBufferSize),
+		stop:     pubsub.Close,
+	}
+
+	go func() {
+		defer close(s.messages)
+
+		for {
+			msg, err := pubsub.ReceiveMessage(context.Background())
+			if err != nil {
+				return
+			}
+
+			// As with MemoryStore, a slow reader loses messages instead of
+			// blocking the goroutine past Close.
+			select {
+			case s.messages <- Message{Channel: msg.Channel, Payload: msg.Payload}:
+			default:
+			}
+		}
+	}()
+
+	return s, nil
+}
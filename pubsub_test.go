@@ -0,0 +1,37 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testPubSub(t *testing.T, store KVStore) {
+	is := assert.New(t)
+
+	pubsub := store.(PubSub)
+
+	sub, err := pubsub.Subscribe("invalidate", "other")
+	is.Nil(err)
+
+	// Redis subscriptions are asynchronous.
+	time.Sleep(50 * time.Millisecond)
+
+	is.Nil(pubsub.Publish("ignored", "key"))
+	is.Nil(pubsub.Publish("invalidate", "key"))
+
+	is.Equal(Message{Channel: "invalidate", Payload: "key"}, <-sub.Messages())
+
+	is.Nil(sub.Close())
+
+	for range sub.Messages() {
+	}
+}
+
+func TestMemoryStorePubSub(t *testing.T) {
+	store, err := NewMemoryStore(time.Second*10, time.Second*10)
+	assert.Nil(t, err)
+
+	testPubSub(t, store)
+}
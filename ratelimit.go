@@ -0,0 +1,131 @@
+package gokvstores
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimit is a token bucket limit.
+type RateLimit struct {
+	// Rate is the number of operations allowed per second.
+	Rate float64
+
+	// Burst is the maximum number of operations allowed at once.
+	// Defaults to Rate, with a minimum of 1.
+	Burst int
+}
+
+// limiter returns the rate.Limiter enforcing the limit.
+func (l RateLimit) limiter() *rate.Limiter {
+	burst := l.Burst
+	if burst <= 0 {
+		burst = int(l.Rate)
+	}
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	return rate.NewLimiter(rate.Limit(l.Rate), burst)
+}
+
+// RateLimitOptions are RateLimitStore options.
+type RateLimitOptions struct {
+	// Limit, if set, is shared by all operations.
+	Limit *RateLimit
+
+	// Operations sets additional limits per operation, keyed by method name (e.g. "Set").
+	Operations map[string]RateLimit
+
+	// FailFast returns ErrRateLimited instead of waiting for the limits to allow the operation.
+	FailFast bool
+}
+
+// RateLimitStore is a KVStore decorator capping the rate of operations sent to
+// the wrapped store. Close is never limited.
+type RateLimitStore struct {
+	*interceptedStore
+
+	limiter    *rate.Limiter
+	operations map[string]*rate.Limiter
+	failFast   bool
+}
+
+// NewRateLimitStore returns a RateLimitStore wrapping the given store.
+func NewRateLimitStore(store KVStore, options *RateLimitOptions) *RateLimitStore {
+	if options == nil {
+		options = &RateLimitOptions{}
+	}
+
+	r := &RateLimitStore{
+		operations: make(map[string]*rate.Limiter, len(options.Operations)),
+		failFast:   options.FailFast,
+	}
+
+	if options.Limit != nil {
+		r.limiter = options.Limit.limiter()
+	}
+
+	for name, limit := range options.Operations {
+		r.operations[name] = limit.limiter()
+	}
+
+	r.interceptedStore = &interceptedStore{store: store, intercept: r.limit}
+
+	return r
+}
+
+// limit waits for, or checks, the limits applying to the operation.
+func (r *RateLimitStore) limit(op *operation, next func() error) error {
+	if op.name == "Close" {
+		return next()
+	}
+
+	limiters := []*rate.Limiter{r.operations[op.name], r.limiter}
+
+	if r.failFast {
+		if !allow(limiters) {
+			return ErrRateLimited
+		}
+		return next()
+	}
+
+	for _, limiter := range limiters {
+		if limiter == nil {
+			continue
+		}
+
+		if err := limiter.Wait(context.Background()); err != nil {
+			return err
+		}
+	}
+
+	return next()
+}
+
+// allow takes a token from all the given limiters or from none of them, so
+// that an operation rejected by one limit does not use up the others.
+func allow(limiters []*rate.Limiter) bool {
+	now := time.Now()
+
+	var reservations []*rate.Reservation
+	for _, limiter := range limiters {
+		if limiter == nil {
+			continue
+		}
+
+		reservation := limiter.ReserveN(now, 1)
+		reservations = append(reservations, reservation)
+
+		if !reservation.OK() || reservation.DelayFrom(now) > 0 {
+			for _, reservation := range reservations {
+				reservation.CancelAt(now)
+			}
+			return false
+		}
+	}
+
+	return true
+}
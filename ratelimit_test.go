@@ -0,0 +1,60 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	testStore(t, NewRateLimitStore(memory, nil))
+
+	store := NewRateLimitStore(memory, &RateLimitOptions{
+		Operations: map[string]RateLimit{"Set": {Rate: 1, Burst: 2}},
+		FailFast:   true,
+	})
+
+	is.Nil(store.Set("key", "value"))
+	is.Nil(store.Set("key", "value"))
+	is.Equal(ErrRateLimited, store.Set("key", "value"))
+
+	_, err = store.Get("key")
+	is.Nil(err)
+
+	// Operations rejected by their own limit leave the shared one untouched.
+
+	store = NewRateLimitStore(memory, &RateLimitOptions{
+		Limit:      &RateLimit{Rate: 0.001, Burst: 2},
+		Operations: map[string]RateLimit{"Set": {Rate: 0.001, Burst: 1}},
+		FailFast:   true,
+	})
+
+	is.Nil(store.Set("key", "value"))
+	is.Equal(ErrRateLimited, store.Set("key", "value"))
+	is.Equal(ErrRateLimited, store.Set("key", "value"))
+
+	_, err = store.Get("key")
+	is.Nil(err)
+
+	_, err = store.Get("key")
+	is.Equal(ErrRateLimited, err)
+
+	// Waiting
+
+	store = NewRateLimitStore(memory, &RateLimitOptions{
+		Limit: &RateLimit{Rate: 20, Burst: 1},
+	})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err = store.Get("key")
+		is.Nil(err)
+	}
+	is.True(time.Since(start) >= 90*time.Millisecond)
+}
@@ -0,0 +1,47 @@
+package gokvstores
+
+import "time"
+
+// LoaderFunc loads the value of a key missing from the cache.
+// Returning a nil value means the key does not exist.
+type LoaderFunc func(key string) (interface{}, error)
+
+// ReadThroughStore is a KVStore decorator loading missing values on Get and
+// storing them in the wrapped store.
+//
+// Wrap it in a SingleflightStore to load each key once under concurrent misses.
+type ReadThroughStore struct {
+	KVStore
+
+	loader     LoaderFunc
+	expiration time.Duration
+}
+
+// NewReadThroughStore returns a ReadThroughStore wrapping the given store.
+// Loaded values are stored with the given expiration.
+func NewReadThroughStore(store KVStore, loader LoaderFunc, expiration time.Duration) *ReadThroughStore {
+	return &ReadThroughStore{
+		KVStore:    store,
+		loader:     loader,
+		expiration: expiration,
+	}
+}
+
+// Get returns value for the given key, loading it on cache miss.
+func (s *ReadThroughStore) Get(key string) (interface{}, error) {
+	value, err := s.KVStore.Get(key)
+	if err != nil || value != nil {
+		return value, err
+	}
+
+	value, err = s.loader(key)
+	if err != nil || value == nil {
+		return nil, err
+	}
+
+	if err := s.KVStore.SetWithExpiration(key, value, s.expiration); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
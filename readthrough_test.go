@@ -0,0 +1,54 @@
+package gokvstores
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadThroughStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	loads := 0
+	failure := errors.New("origin down")
+
+	store := NewReadThroughStore(memory, func(key string) (interface{}, error) {
+		loads++
+		switch key {
+		case "missing":
+			return nil, nil
+		case "failing":
+			return nil, failure
+		}
+		return "loaded:" + key, nil
+	}, time.Minute)
+
+	v, err := store.Get("key")
+	is.Nil(err)
+	is.Equal("loaded:key", v)
+
+	v, err = store.Get("key")
+	is.Nil(err)
+	is.Equal("loaded:key", v)
+	is.Equal(1, loads)
+
+	v, err = memory.Get("key")
+	is.Nil(err)
+	is.Equal("loaded:key", v)
+
+	v, err = store.Get("missing")
+	is.Nil(err)
+	is.Nil(v)
+
+	exists, err := memory.Exists("missing")
+	is.Nil(err)
+	is.False(exists)
+
+	_, err = store.Get("failing")
+	is.Equal(failure, err)
+}
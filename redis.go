@@ -1,11 +1,16 @@
 package gokvstores
 
 import (
+	"context"
+	"crypto/tls"
 	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	conv "github.com/cstockton/go-conv"
-	redis "gopkg.in/redis.v5"
+	"github.com/redis/go-redis/v9"
 )
 
 // ----------------------------------------------------------------------------
@@ -14,25 +19,17 @@ import (
 
 // RedisClient is an interface thats allows to use Redis cluster or a redis single client seamlessly.
 type RedisClient interface {
-	Ping() *redis.StatusCmd
-	Exists(key string) *redis.BoolCmd
-	Del(keys ...string) *redis.IntCmd
-	FlushDb() *redis.StatusCmd
-	Close() error
-	Process(cmd redis.Cmder) error
-	Get(key string) *redis.StringCmd
-	Set(key string, value interface{}, expiration time.Duration) *redis.StatusCmd
-	HGetAll(key string) *redis.StringStringMapCmd
-	HMSet(key string, fields map[string]string) *redis.StatusCmd
-	SMembers(key string) *redis.StringSliceCmd
-	SAdd(key string, members ...interface{}) *redis.IntCmd
-}
-
-// RedisClientOptions are Redis client options.
+	redis.UniversalClient
+}
+
+// RedisClientOptions are Redis client options. IdleCheckFrequency is
+// ignored: idle connections are closed when reused after IdleTimeout.
+// MaxRetries zero means no retries, as with go-redis v5.
 type RedisClientOptions struct {
 	Network            string
 	Addr               string
 	Dialer             func() (net.Conn, error)
+	Username           string
 	Password           string
 	DB                 int
 	MaxRetries         int
@@ -44,14 +41,49 @@ type RedisClientOptions struct {
 	IdleTimeout        time.Duration
 	IdleCheckFrequency time.Duration
 	ReadOnly           bool
+	TLSConfig          *tls.Config
+	Codec              Codec
+
+	// ClientSideCache enables the client-side cache of the values read by
+	// Get, GetMap and GetSlice, which the server invalidates when the keys
+	// change (Redis 6+). Writes sent through pipelines and transactions are
+	// only seen once the server invalidates them.
+	ClientSideCache *ClientSideCacheOptions
+
+	// ReplicaAddrs are the addresses of replicas of Addr, which Get, GetMap
+	// and GetSlice read from in turn. Other operations use Addr. It can't be
+	// combined with ClientSideCache.
+	ReplicaAddrs []string
+
+	// MaxReplicaStaleness, when set, skips the replicas which are
+	// disconnected from Addr, or have not heard from it for longer, as
+	// reported by INFO replication every second. Reads use Addr when no
+	// replica is fresh enough.
+	MaxReplicaStaleness time.Duration
+
+	// WaitReplicas, when set, makes writes wait until that many replicas
+	// acknowledged them, for at most WaitTimeout, or forever if zero. See
+	// RedisStore.WithWait.
+	WaitReplicas int
+	WaitTimeout  time.Duration
+
+	// Unlink deletes keys with UNLINK rather than DEL, so the server frees
+	// large values in the background without blocking (Redis 4+).
+	Unlink bool
+
+	// ClientName is set with CLIENT SETNAME on every connection of the store,
+	// so CLIENT LIST shows which service owns them. It can't contain spaces.
+	ClientName string
 }
 
-// RedisClusterOptions are Redis cluster options.
+// RedisClusterOptions are Redis cluster options. IdleCheckFrequency is
+// ignored, as for RedisClientOptions.
 type RedisClusterOptions struct {
 	Addrs              []string
 	MaxRedirects       int
 	ReadOnly           bool
 	RouteByLatency     bool
+	Username           string
 	Password           string
 	DialTimeout        time.Duration
 	ReadTimeout        time.Duration
@@ -60,6 +92,66 @@ type RedisClusterOptions struct {
 	PoolTimeout        time.Duration
 	IdleTimeout        time.Duration
 	IdleCheckFrequency time.Duration
+	TLSConfig          *tls.Config
+	Codec              Codec
+
+	// Unlink deletes keys with UNLINK, as for RedisClientOptions.
+	Unlink bool
+
+	// ClientName names the connections, as for RedisClientOptions.
+	ClientName string
+}
+
+// RedisUniversalOptions are the options of a store connecting to a single
+// node, a cluster, or a Sentinel-managed failover group. MaxRetries zero
+// means no retries, as for RedisClientOptions.
+type RedisUniversalOptions struct {
+	// Addrs are the node addresses, or the Sentinel addresses with MasterName.
+	// A single address connects to a single node, several to a cluster.
+	Addrs []string
+
+	// MasterName is the name of the master monitored by Sentinel.
+	MasterName string
+
+	Username       string
+	Password       string
+	DB             int
+	MaxRetries     int
+	MaxRedirects   int
+	ReadOnly       bool
+	RouteByLatency bool
+	DialTimeout    time.Duration
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	PoolSize       int
+	PoolTimeout    time.Duration
+	IdleTimeout    time.Duration
+	TLSConfig      *tls.Config
+	Codec          Codec
+
+	// Unlink deletes keys with UNLINK, as for RedisClientOptions.
+	Unlink bool
+
+	// ClientName names the connections, as for RedisClientOptions.
+	ClientName string
+}
+
+// PoolStats are the statistics of a Redis connection pool.
+type PoolStats struct {
+	// Hits is the number of times a free connection was found in the pool.
+	Hits uint32 `json:"hits"`
+
+	// Misses is the number of times a connection had to be created or waited for.
+	Misses uint32 `json:"misses"`
+
+	// Timeouts is the number of times no connection could be obtained in time.
+	Timeouts uint32 `json:"timeouts"`
+
+	// TotalConns is the number of connections in the pool.
+	TotalConns uint32 `json:"total_conns"`
+
+	// IdleConns is the number of idle connections in the pool.
+	IdleConns uint32 `json:"idle_conns"`
 }
 
 // ----------------------------------------------------------------------------
@@ -68,170 +160,836 @@ type RedisClusterOptions struct {
 
 // RedisStore is the Redis implementation of KVStore.
 type RedisStore struct {
+	stats      *statsCounter
+	ctx        context.Context
 	client     RedisClient
+	local      *localCache
+	replicas   *replicaSet
+	wait       *replicaWait
+	modules    *redisModules
+	indexes    *searchIndexes
+	promoter   *periodic
 	expiration time.Duration
+	codec      Codec
+	db         int
+	unlink     bool
 }
 
-// Get returns value for the given key.
-func (r *RedisStore) Get(key string) (interface{}, error) {
-	cmd := redis.NewCmd("get", key)
+// redisModules flags the server modules found missing, once their commands
+// were rejected as unknown.
+type redisModules struct {
+	json   int32
+	search int32
+	bloom  int32
+}
 
-	if err := r.client.Process(cmd); err != nil {
-		return nil, err
+// moduleAvailable reports whether the commands of a module, flagged by
+// missing, should be tried.
+func moduleAvailable(missing *int32) bool {
+	return atomic.LoadInt32(missing) == 0
+}
+
+// moduleCommand sends the command of a module. It reports false, without
+// error, when the server does not have the module.
+func (r *RedisStore) moduleCommand(missing *int32, cmd redis.Cmder) (bool, error) {
+	err := r.client.Process(r.ctx, cmd)
+	if err != nil && strings.HasPrefix(err.Error(), "ERR unknown command") {
+		atomic.StoreInt32(missing, 1)
+		return false, nil
 	}
 
-	return cmd.Val(), cmd.Err()
+	return true, err
 }
 
-// Set sets the value for the given key.
-func (r *RedisStore) Set(key string, value interface{}) error {
-	return r.client.Set(key, value, r.expiration).Err()
+// encode returns the serialized value.
+func (r *RedisStore) encode(value interface{}) (string, error) {
+	data, err := r.codec.Encode(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
 }
 
-// GetMap returns map for the given key.
-func (r *RedisStore) GetMap(key string) (map[string]interface{}, error) {
-	values, err := r.client.HGetAll(key).Result()
+// decode returns the value from its serialized form.
+func (r *RedisStore) decode(data string) (interface{}, error) {
+	return r.codec.Decode([]byte(data))
+}
+
+// encodeMap returns the serialized map fields.
+func (r *RedisStore) encodeMap(values map[string]interface{}) (map[string]string, error) {
+	fields := make(map[string]string, len(values))
+	for k, v := range values {
+		encoded, err := r.encode(v)
+		if err != nil {
+			return nil, err
+		}
+		fields[k] = encoded
+	}
+
+	return fields, nil
+}
+
+// encodeSlice returns the serialized non-nil values.
+func (r *RedisStore) encodeSlice(values []interface{}) ([]interface{}, error) {
+	members := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+
+		encoded, err := r.encode(v)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, encoded)
+	}
+
+	return members, nil
+}
+
+// decodeString returns the value read by a GET, nil if the key is missing.
+func (r *RedisStore) decodeString(cmd *redis.StringCmd) (interface{}, error) {
+	data, err := cmd.Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+
 	if err != nil {
 		return nil, err
 	}
 
-	if len(values) == 0 {
-		return nil, nil
+	return r.decode(data)
+}
+
+// decodeMap returns the map read by a HGETALL, nil if the key is missing.
+func (r *RedisStore) decodeMap(cmd *redis.MapStringStringCmd) (map[string]interface{}, error) {
+	fields, err := cmd.Result()
+	if err != nil || len(fields) == 0 {
+		return nil, err
 	}
 
-	newValues := make(map[string]interface{}, len(values))
-	for k, v := range values {
-		newValues[k] = v
+	values := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		if values[k], err = r.decode(v); err != nil {
+			return nil, err
+		}
 	}
 
-	return newValues, nil
+	return values, nil
 }
 
-// SetMap sets map for the given key.
-func (r *RedisStore) SetMap(key string, values map[string]interface{}) error {
-	newValues := make(map[string]string, len(values))
+// decodeSlice returns the slice read by a SMEMBERS, nil if the key is missing.
+func (r *RedisStore) decodeSlice(cmd *redis.StringSliceCmd) ([]interface{}, error) {
+	members, err := cmd.Result()
+	if err != nil || len(members) == 0 {
+		return nil, err
+	}
 
-	for k, v := range values {
-		newValues[k] = conv.String(v)
+	values := make([]interface{}, 0, len(members))
+	for _, v := range members {
+		decoded, err := r.decode(v)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, decoded)
 	}
 
-	return r.client.HMSet(key, newValues).Err()
+	return values, nil
 }
 
-// GetSlice returns slice for the given key.
-func (r *RedisStore) GetSlice(key string) ([]interface{}, error) {
-	values, err := r.client.SMembers(key).Result()
+// Get returns value for the given key.
+func (r *RedisStore) Get(key string) (value interface{}, err error) {
+	defer func() { r.stats.read(value != nil, err) }()
+
+	return r.cached(cachedGet, key, func() (interface{}, error) {
+		return r.decodeString(r.reader().Get(r.ctx, key))
+	})
+}
+
+// GetAndRefresh returns the value for the given key, and sets its expiration
+// to ttl with GETEX (Redis 6.2+). It always reads from the primary.
+func (r *RedisStore) GetAndRefresh(key string, ttl time.Duration) (value interface{}, err error) {
+	defer func() { r.stats.read(value != nil, err) }()
+
+	// Zero sends GETEX PERSIST.
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	return r.decodeString(r.client.GetEx(r.ctx, key, ttl))
+}
+
+// Set sets the value for the given key.
+func (r *RedisStore) Set(key string, value interface{}) (err error) {
+	defer func() { r.stats.write(err) }()
+	defer r.uncache(key)
+
+	encoded, err := r.encode(value)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if len(values) == 0 {
-		return nil, nil
+	return r.write(func(c redis.Cmdable) error {
+		return c.Set(r.ctx, key, encoded, r.expiration).Err()
+	})
+}
+
+// SetWithExpiration sets the value for the given key with a specific expiration.
+func (r *RedisStore) SetWithExpiration(key string, value interface{}, expiration time.Duration) (err error) {
+	defer func() { r.stats.write(err) }()
+	defer r.uncache(key)
+
+	if expiration < 0 {
+		expiration = 0
 	}
 
-	newValues := make([]interface{}, len(values))
-	for _, v := range values {
-		newValues = append(newValues, v)
+	encoded, err := r.encode(value)
+	if err != nil {
+		return err
+	}
+
+	return r.write(func(c redis.Cmdable) error {
+		return c.Set(r.ctx, key, encoded, expiration).Err()
+	})
+}
+
+// GetMap returns map for the given key.
+func (r *RedisStore) GetMap(key string) (value map[string]interface{}, err error) {
+	defer func() { r.stats.read(value != nil, err) }()
+
+	cached, err := r.cached(cachedGetMap, key, func() (interface{}, error) {
+		return r.decodeMap(r.reader().HGetAll(r.ctx, key))
+	})
+	value, _ = cached.(map[string]interface{})
+	return value, err
+}
+
+// SetMap sets map for the given key.
+func (r *RedisStore) SetMap(key string, values map[string]interface{}) (err error) {
+	defer func() { r.stats.write(err) }()
+	defer r.uncache(key)
+
+	fields, err := r.encodeMap(values)
+	if err != nil {
+		return err
 	}
 
-	return newValues, nil
+	return r.write(func(c redis.Cmdable) error {
+		return c.HSet(r.ctx, key, fields).Err()
+	})
+}
+
+// GetSlice returns slice for the given key.
+func (r *RedisStore) GetSlice(key string) (value []interface{}, err error) {
+	defer func() { r.stats.read(value != nil, err) }()
+
+	cached, err := r.cached(cachedGetSlice, key, func() (interface{}, error) {
+		return r.decodeSlice(r.reader().SMembers(r.ctx, key))
+	})
+	value, _ = cached.([]interface{})
+	return value, err
 }
 
 // SetSlice sets map for the given key.
 func (r *RedisStore) SetSlice(key string, values []interface{}) error {
-	for _, v := range values {
-		if v != nil {
-			if err := r.client.SAdd(key, v).Err(); err != nil {
-				return err
-			}
-		}
+	err := r.addToSet(key, values)
+	r.stats.write(err)
+	return err
+}
+
+// addToSet adds the non-nil values to the set stored at key.
+func (r *RedisStore) addToSet(key string, values []interface{}) error {
+	members, err := r.encodeSlice(values)
+	if err != nil || len(members) == 0 {
+		return err
 	}
 
-	return nil
+	err = r.write(func(c redis.Cmdable) error {
+		return c.SAdd(r.ctx, key, members...).Err()
+	})
+	r.uncache(key)
+	return err
 }
 
 // AppendSlice appends values to the given slice.
 func (r *RedisStore) AppendSlice(key string, values ...interface{}) error {
-	return r.SetSlice(key, values)
+	err := r.addToSet(key, values)
+	r.stats.write(err)
+	return err
 }
 
 // Exists checks key existence.
 func (r *RedisStore) Exists(key string) (bool, error) {
-	cmd := r.client.Exists(key)
-	return cmd.Val(), cmd.Err()
+	n, err := r.client.Exists(r.ctx, key).Result()
+	r.stats.read(n > 0, err)
+	return n > 0, err
+}
+
+// del deletes the keys with UNLINK or DEL, depending on the store options.
+func (r *RedisStore) del(c redis.Cmdable, keys ...string) *redis.IntCmd {
+	if r.unlink {
+		return c.Unlink(r.ctx, keys...)
+	}
+	return c.Del(r.ctx, keys...)
 }
 
 // Delete deletes key.
 func (r *RedisStore) Delete(key string) error {
-	return r.client.Del(key).Err()
+	err := r.write(func(c redis.Cmdable) error {
+		return r.del(c, key).Err()
+	})
+	r.uncache(key)
+	r.stats.write(err)
+	return err
 }
 
 // Flush flushes the current database.
 func (r *RedisStore) Flush() error {
-	return r.client.FlushDb().Err()
+	err := r.write(func(c redis.Cmdable) error {
+		return c.FlushDB(r.ctx).Err()
+	})
+	if r.local != nil {
+		r.local.clear()
+	}
+	r.stats.write(err)
+	return err
 }
 
 // Close closes the client connection.
 func (r *RedisStore) Close() error {
+	if r.local != nil {
+		r.local.close()
+	}
+
+	if r.replicas != nil {
+		r.replicas.close()
+	}
+
+	r.promoter.close()
+
 	return r.client.Close()
 }
 
+// Ping checks the Redis server is reachable.
+func (r *RedisStore) Ping() error {
+	return r.client.Ping(r.ctx).Err()
+}
+
+// Stats returns the counters of the store. Keys is the size of the current
+// database, of a single node when using a cluster.
+func (r *RedisStore) Stats() (Stats, error) {
+	keys, err := r.client.DBSize(r.ctx).Result()
+	if err != nil {
+		return Stats{}, err
+	}
+
+	return r.stats.stats(keys), nil
+}
+
+// Scan calls fn with each item of the current database whose type is
+// supported (strings, hashes and sets), of every master node with a cluster.
+func (r *RedisStore) Scan(fn func(item Item) error) error {
+	return r.scanKeys("", func(key string) error {
+		item, found, err := r.item(key)
+		if err != nil || !found {
+			return err
+		}
+
+		return fn(item)
+	})
+}
+
+// Keys returns the keys matching the given glob-style pattern, of every
+// master node with a cluster.
+func (r *RedisStore) Keys(pattern string) ([]string, error) {
+	var keys []string
+	err := r.scanKeys(pattern, func(key string) error {
+		keys = append(keys, key)
+		return nil
+	})
+
+	return keys, err
+}
+
+// DeletePattern deletes the keys matching the given glob-style pattern, of
+// every master node with a cluster, and returns the number of deleted keys.
+func (r *RedisStore) DeletePattern(pattern string) (int64, error) {
+	var deleted int64
+	err := r.scanKeys(pattern, func(key string) error {
+		n, err := r.del(r.client, key).Result()
+		r.uncache(key)
+		r.stats.write(err)
+		deleted += n
+		return err
+	})
+
+	return deleted, err
+}
+
+// scanKeys calls fn with the keys matching the pattern, all if empty. With a
+// cluster, the master nodes are scanned concurrently, but fn is called by one
+// at a time; once it fails, the other nodes stop.
+func (r *RedisStore) scanKeys(pattern string, fn func(key string) error) error {
+	cluster, ok := r.client.(*redis.ClusterClient)
+	if !ok {
+		return scanNode(r.ctx, r.client, pattern, fn)
+	}
+
+	var (
+		mu     sync.Mutex
+		failed error
+	)
+
+	return cluster.ForEachMaster(r.ctx, func(ctx context.Context, node *redis.Client) error {
+		return scanNode(ctx, node, pattern, func(key string) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if failed == nil {
+				failed = fn(key)
+			}
+			return failed
+		})
+	})
+}
+
+// scanNode calls fn with the keys of a single node matching the pattern.
+func scanNode(ctx context.Context, client redis.Cmdable, pattern string, fn func(key string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, pattern, 100).Result()
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			if err := fn(key); err != nil {
+				return err
+			}
+		}
+
+		if cursor = next; cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// item returns the item stored at key, if its type is supported and it still exists.
+func (r *RedisStore) item(key string) (Item, bool, error) {
+	kind, err := r.client.Type(r.ctx, key).Result()
+	if err != nil {
+		return Item{}, false, err
+	}
+
+	item := Item{Key: key}
+
+	switch kind {
+	case "string":
+		item.Value, err = r.Get(key)
+	case "hash":
+		item.Value, err = r.GetMap(key)
+	case "set":
+		item.Value, err = r.GetSlice(key)
+	default:
+		return Item{}, false, nil
+	}
+
+	if err != nil || item.Value == nil {
+		return Item{}, false, err
+	}
+
+	ttl, err := r.client.PTTL(r.ctx, key).Result()
+	if err != nil {
+		return Item{}, false, err
+	}
+
+	if ttl > 0 {
+		item.Expiration = time.Now().Add(ttl)
+	}
+
+	return item, true, nil
+}
+
+// Expire sets the expiration of the given key.
+func (r *RedisStore) Expire(key string, expiration time.Duration) error {
+	return r.expire(r.client, key, expiration).Err()
+}
+
+// replaceItem deletes the given key, then writes value, of any kind, and
+// its expiration in a MULTI/EXEC block.
+func (r *RedisStore) replaceItem(key string, value interface{}, ttl time.Duration) (err error) {
+	defer func() { r.stats.write(err) }()
+	defer r.uncache(key)
+
+	var set func(pipe redis.Pipeliner)
+	switch value := value.(type) {
+	case map[string]interface{}:
+		fields, err := r.encodeMap(value)
+		if err != nil {
+			return err
+		}
+		set = func(pipe redis.Pipeliner) {
+			if len(fields) > 0 {
+				pipe.HSet(r.ctx, key, fields)
+			}
+		}
+	case []interface{}:
+		members, err := r.encodeSlice(value)
+		if err != nil {
+			return err
+		}
+		set = func(pipe redis.Pipeliner) {
+			if len(members) > 0 {
+				pipe.SAdd(r.ctx, key, members...)
+			}
+		}
+	default:
+		encoded, err := r.encode(value)
+		if err != nil {
+			return err
+		}
+		set = func(pipe redis.Pipeliner) {
+			pipe.Set(r.ctx, key, encoded, 0)
+		}
+	}
+
+	_, err = r.client.TxPipelined(r.ctx, func(pipe redis.Pipeliner) error {
+		r.del(pipe, key)
+		set(pipe)
+		if ttl > 0 {
+			pipe.PExpire(r.ctx, key, ttl)
+		}
+		return nil
+	})
+	return err
+}
+
+// expireBatchSize is the number of EXPIRE commands pipelined together by
+// ExpireMany.
+const expireBatchSize = 1000
+
+// ExpireMany sets the expiration of each key, pipelining the commands. It
+// returns the first error, once all the commands were sent.
+func (r *RedisStore) ExpireMany(expirations map[string]time.Duration) error {
+	var (
+		pipe     = r.client.Pipeline()
+		firstErr error
+	)
+
+	exec := func() {
+		if _, err := pipe.Exec(r.ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	for key, expiration := range expirations {
+		r.expire(pipe, key, expiration)
+		if pipe.Len() >= expireBatchSize {
+			exec()
+		}
+	}
+
+	if pipe.Len() > 0 {
+		exec()
+	}
+
+	return firstErr
+}
+
+// expire sends the EXPIRE, or PERSIST, of the given key.
+func (r *RedisStore) expire(c redis.Cmdable, key string, expiration time.Duration) *redis.BoolCmd {
+	if expiration <= 0 {
+		return c.Persist(r.ctx, key)
+	}
+
+	return c.Expire(r.ctx, key, expiration)
+}
+
+// MemoryUsage returns the memory used by the server data, as reported by
+// INFO memory: used_memory_dataset, or used_memory on servers older than
+// Redis 4. With a cluster, only a single node is reported.
+func (r *RedisStore) MemoryUsage() (int64, error) {
+	info, err := r.client.Info(r.ctx, "memory").Result()
+	if err != nil {
+		return 0, err
+	}
+
+	fields := parseInfo(info)
+
+	for _, field := range []string{"used_memory_dataset", "used_memory"} {
+		if value, ok := fields[field]; ok {
+			return strconv.ParseInt(value, 10, 64)
+		}
+	}
+
+	return 0, ErrNotSupported
+}
+
+// KeyMemoryUsage returns the number of bytes used by the key and its value,
+// as reported by MEMORY USAGE. Large aggregate values are sampled by the
+// server.
+func (r *RedisStore) KeyMemoryUsage(key string) (int64, error) {
+	usage, err := r.client.MemoryUsage(r.ctx, key).Result()
+	if err == redis.Nil {
+		return 0, nil
+	}
+
+	return usage, err
+}
+
+// parseInfo returns the fields of an INFO reply.
+func parseInfo(info string) map[string]string {
+	fields := map[string]string{}
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if i := strings.IndexByte(line, ':'); i > 0 {
+			fields[line[:i]] = line[i+1:]
+		}
+	}
+	return fields
+}
+
+// PoolStats returns the statistics of the client connection pool.
+func (r *RedisStore) PoolStats() PoolStats {
+	stats := r.client.PoolStats()
+
+	return PoolStats{
+		Hits:       stats.Hits,
+		Misses:     stats.Misses,
+		Timeouts:   stats.Timeouts,
+		TotalConns: stats.TotalConns,
+		IdleConns:  stats.IdleConns,
+	}
+}
+
+// newRedisStore returns a RedisStore using the given client.
+func newRedisStore(client RedisClient, expiration time.Duration, codec Codec) *RedisStore {
+	if codec == nil {
+		codec = StringCodec{}
+	}
+
+	// A negative expiration, such as NoExpiration, is redis.KeepTTL for
+	// go-redis, which would keep the TTL of the keys overwritten by Set.
+	if expiration < 0 {
+		expiration = 0
+	}
+
+	return &RedisStore{
+		stats:      &statsCounter{},
+		modules:    &redisModules{},
+		indexes:    &searchIndexes{},
+		promoter:   &periodic{},
+		ctx:        context.Background(),
+		client:     client,
+		expiration: expiration,
+		codec:      codec,
+	}
+}
+
+// WithContext returns a copy of the store sending its commands with ctx,
+// which cancels them once done. The copy shares the client and the counters
+// of the store: closing either closes both.
+func (r *RedisStore) WithContext(ctx context.Context) *RedisStore {
+	store := *r
+	store.ctx = ctx
+	return &store
+}
+
+// WithDB returns a store using the logical database db of the same server,
+// with the same options. The store has its own connections and counters, and
+// must be closed separately. Clusters and client-side caching do not support
+// it.
+func (r *RedisStore) WithDB(db int) (*RedisStore, error) {
+	client, ok := r.client.(*redis.Client)
+	if !ok || r.local != nil {
+		return nil, ErrNotSupported
+	}
+
+	opts := *client.Options()
+	opts.DB = db
+
+	c := redis.NewClient(&opts)
+	if err := c.Ping(r.ctx).Err(); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	store := newRedisStore(c, r.expiration, r.codec)
+	store.ctx = r.ctx
+	store.modules = r.modules
+	store.wait = r.wait
+	store.db = db
+	store.unlink = r.unlink
+
+	if r.replicas != nil {
+		store.replicas = r.replicas.withDB(db)
+	}
+
+	return store, nil
+}
+
+// maxRetries returns the go-redis MaxRetries of the given number of retries.
+// go-redis v9 retries 3 times when zero, -1 disabling retries.
+func maxRetries(n int) int {
+	if n == 0 {
+		return -1
+	}
+	return n
+}
+
+// readOnlyConnect sends READONLY on new connections, allowing reads from
+// cluster replicas.
+func readOnlyConnect(ctx context.Context, conn *redis.Conn) error {
+	return conn.ReadOnly(ctx).Err()
+}
+
 // NewRedisClientStore returns Redis client instance of KVStore.
 func NewRedisClientStore(options *RedisClientOptions, expiration time.Duration) (KVStore, error) {
 	opts := &redis.Options{
-		Network:            options.Network,
-		Addr:               options.Addr,
-		Dialer:             options.Dialer,
-		Password:           options.Password,
-		DB:                 options.DB,
-		MaxRetries:         options.MaxRetries,
-		DialTimeout:        options.DialTimeout,
-		ReadTimeout:        options.ReadTimeout,
-		WriteTimeout:       options.WriteTimeout,
-		PoolSize:           options.PoolSize,
-		PoolTimeout:        options.PoolTimeout,
-		IdleTimeout:        options.IdleTimeout,
-		IdleCheckFrequency: options.IdleCheckFrequency,
-		ReadOnly:           options.ReadOnly,
+		Network:         options.Network,
+		Addr:            options.Addr,
+		Username:        options.Username,
+		Password:        options.Password,
+		DB:              options.DB,
+		MaxRetries:      maxRetries(options.MaxRetries),
+		DialTimeout:     options.DialTimeout,
+		ReadTimeout:     options.ReadTimeout,
+		WriteTimeout:    options.WriteTimeout,
+		PoolSize:        options.PoolSize,
+		PoolTimeout:     options.PoolTimeout,
+		ConnMaxIdleTime: options.IdleTimeout,
+		TLSConfig:       options.TLSConfig,
+		ClientName:      options.ClientName,
+	}
+
+	if options.Dialer != nil {
+		opts.Dialer = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return options.Dialer()
+		}
+	}
+
+	if options.ReadOnly {
+		opts.OnConnect = readOnlyConnect
+	}
+
+	if options.ClientSideCache != nil && len(options.ReplicaAddrs) > 0 {
+		return nil, ErrNotSupported
+	}
+
+	var replicas *replicaSet
+	if len(options.ReplicaAddrs) > 0 {
+		replicas = newReplicaSet(*opts, options.ReplicaAddrs, options.MaxReplicaStaleness)
+	}
+
+	var local *localCache
+	if options.ClientSideCache != nil {
+		var (
+			redirect int64
+			err      error
+		)
+
+		local, redirect, err = newLocalCache(*opts, options.ClientSideCache)
+		if err != nil {
+			return nil, err
+		}
+
+		opts.OnConnect = trackingConnect(redirect, opts.OnConnect)
 	}
 
 	client := redis.NewClient(opts)
 
-	if err := client.Ping().Err(); err != nil {
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		if local != nil {
+			local.close()
+		}
+		if replicas != nil {
+			replicas.close()
+		}
 		return nil, err
 	}
 
-	return &RedisStore{
-		client:     client,
-		expiration: expiration,
-	}, nil
+	store := newRedisStore(client, expiration, options.Codec)
+	store.db = options.DB
+	store.unlink = options.Unlink
+	store.local = local
+	store.replicas = replicas
+
+	if options.WaitReplicas > 0 {
+		store.wait = &replicaWait{replicas: options.WaitReplicas, timeout: options.WaitTimeout}
+	}
+
+	return store, nil
 }
 
 // NewRedisClusterStore returns Redis cluster client instance of KVStore.
 func NewRedisClusterStore(options *RedisClusterOptions, expiration time.Duration) (KVStore, error) {
 	opts := &redis.ClusterOptions{
-		Addrs:              options.Addrs,
-		MaxRedirects:       options.MaxRedirects,
-		ReadOnly:           options.ReadOnly,
-		RouteByLatency:     options.RouteByLatency,
-		Password:           options.Password,
-		DialTimeout:        options.DialTimeout,
-		ReadTimeout:        options.ReadTimeout,
-		WriteTimeout:       options.WriteTimeout,
-		PoolSize:           options.PoolSize,
-		PoolTimeout:        options.PoolTimeout,
-		IdleTimeout:        options.IdleTimeout,
-		IdleCheckFrequency: options.IdleCheckFrequency,
+		Addrs:           options.Addrs,
+		MaxRedirects:    options.MaxRedirects,
+		ReadOnly:        options.ReadOnly,
+		RouteByLatency:  options.RouteByLatency,
+		Username:        options.Username,
+		Password:        options.Password,
+		DialTimeout:     options.DialTimeout,
+		ReadTimeout:     options.ReadTimeout,
+		WriteTimeout:    options.WriteTimeout,
+		PoolSize:        options.PoolSize,
+		PoolTimeout:     options.PoolTimeout,
+		ConnMaxIdleTime: options.IdleTimeout,
+		TLSConfig:       options.TLSConfig,
+		ClientName:      options.ClientName,
 	}
 
 	client := redis.NewClusterClient(opts)
 
-	if err := client.Ping().Err(); err != nil {
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
 		return nil, err
 	}
 
-	return &RedisStore{
-		client:     client,
-		expiration: expiration,
-	}, nil
+	store := newRedisStore(client, expiration, options.Codec)
+	store.unlink = options.Unlink
+
+	return store, nil
+}
+
+// NewRedisUniversalStore returns a KVStore connected to a single node, a
+// cluster, or a Sentinel-managed failover group, depending on the options.
+func NewRedisUniversalStore(options *RedisUniversalOptions, expiration time.Duration) (KVStore, error) {
+	client := redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs:           options.Addrs,
+		MasterName:      options.MasterName,
+		Username:        options.Username,
+		Password:        options.Password,
+		DB:              options.DB,
+		MaxRetries:      maxRetries(options.MaxRetries),
+		MaxRedirects:    options.MaxRedirects,
+		ReadOnly:        options.ReadOnly,
+		RouteByLatency:  options.RouteByLatency,
+		DialTimeout:     options.DialTimeout,
+		ReadTimeout:     options.ReadTimeout,
+		WriteTimeout:    options.WriteTimeout,
+		PoolSize:        options.PoolSize,
+		PoolTimeout:     options.PoolTimeout,
+		ConnMaxIdleTime: options.IdleTimeout,
+		TLSConfig:       options.TLSConfig,
+		ClientName:      options.ClientName,
+	})
+
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	store := newRedisStore(client, expiration, options.Codec)
+	store.db = options.DB
+	store.unlink = options.Unlink
+
+	return store, nil
 }
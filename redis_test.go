@@ -1,9 +1,12 @@
 package gokvstores
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	conv "github.com/cstockton/go-conv"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -20,3 +23,684 @@ func TestRedisStore(t *testing.T) {
 
 	assert.Nil(t, store.Close())
 }
+
+func TestRedisStoreGobCodec(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr:  "localhost:6379",
+		Codec: GobCodec{},
+	}, time.Second*30)
+
+	assert.Nil(t, err)
+
+	testStore(t, store)
+
+	item := map[string]interface{}{"integer": 1}
+
+	assert.Nil(t, store.Set("item", item))
+
+	v, err := store.Get("item")
+	assert.Nil(t, err)
+	assert.Equal(t, item, v)
+
+	assert.Nil(t, store.Close())
+}
+
+func TestRedisStoreNoExpiration(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, NoExpiration)
+	is.Nil(err)
+
+	rs := store.(*RedisStore)
+
+	is.Nil(store.SetWithExpiration("key", "value", time.Minute))
+	is.Nil(store.Set("key", "other"))
+
+	ttl, err := rs.client.TTL(rs.ctx, "key").Result()
+	is.Nil(err)
+	is.Equal(time.Duration(-1), ttl)
+
+	is.Nil(store.Close())
+}
+
+func TestRedisStoreStats(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+
+	assert.Nil(t, err)
+	assert.Nil(t, store.Flush())
+
+	assert.Nil(t, store.Set("key", "value"))
+
+	_, err = store.Get("key")
+	assert.Nil(t, err)
+	_, err = store.Get("unknown")
+	assert.Nil(t, err)
+
+	stats, err := store.(StatsProvider).Stats()
+	assert.Nil(t, err)
+	assert.Equal(t, Stats{Operations: 4, Hits: 1, Misses: 1, Keys: 1}, stats)
+
+	assert.Nil(t, store.Close())
+}
+
+func TestRedisStorePoolStats(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+
+	assert.Nil(t, err)
+
+	_, err = store.Get("key")
+	assert.Nil(t, err)
+
+	stats := store.(*RedisStore).PoolStats()
+	assert.NotZero(t, stats.TotalConns)
+	assert.Equal(t, stats.TotalConns, stats.IdleConns)
+	assert.Zero(t, stats.Timeouts)
+
+	assert.Nil(t, store.Close())
+}
+
+func TestRedisStoreSubscribeKeyspace(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+
+	assert.Nil(t, err)
+
+	rs := store.(*RedisStore)
+	assert.Nil(t, rs.client.Process(rs.ctx, redis.NewStatusCmd(rs.ctx, "config", "set", "notify-keyspace-events", "KEA")))
+
+	sub, err := rs.SubscribeKeyspace("set", "del")
+	assert.Nil(t, err)
+
+	assert.Nil(t, store.Set("key", "value"))
+	assert.Nil(t, store.Delete("key"))
+
+	assert.Equal(t, KeyspaceEvent{Event: "set", Key: "key"}, <-sub.Events())
+	assert.Equal(t, KeyspaceEvent{Event: "del", Key: "key"}, <-sub.Events())
+
+	assert.Nil(t, sub.Close())
+	assert.Nil(t, store.Close())
+}
+
+func TestRedisStoreWatch(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+
+	assert.Nil(t, err)
+
+	rs := store.(*RedisStore)
+	assert.Nil(t, rs.client.Process(rs.ctx, redis.NewStatusCmd(rs.ctx, "config", "set", "notify-keyspace-events", "KEA")))
+
+	watch, err := rs.Watch("user:")
+	assert.Nil(t, err)
+
+	assert.Nil(t, store.Set("other", "value"))
+	assert.Nil(t, store.Set("user:1", "value"))
+	assert.Nil(t, store.Delete("user:1"))
+
+	assert.Equal(t, ChangeEvent{Type: ChangeSet, Key: "user:1"}, <-watch.Events())
+	assert.Equal(t, ChangeEvent{Type: ChangeDelete, Key: "user:1"}, <-watch.Events())
+
+	assert.Nil(t, watch.Close())
+	assert.Nil(t, store.Close())
+}
+
+func TestParseInfo(t *testing.T) {
+	info := "# Memory\r\nused_memory:1048576\r\nused_memory_human:1.00M\r\nused_memory_dataset:4096\r\n"
+
+	assert.Equal(t, map[string]string{
+		"used_memory":         "1048576",
+		"used_memory_human":   "1.00M",
+		"used_memory_dataset": "4096",
+	}, parseInfo(info))
+}
+
+func TestRedisStoreMemoryUsage(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+
+	assert.Nil(t, err)
+
+	usage, err := store.(MemoryReporter).MemoryUsage()
+	assert.Nil(t, err)
+	assert.True(t, usage > 0)
+
+	assert.Nil(t, store.Set("key", "value"))
+
+	usage, err = store.(KeyMemoryReporter).KeyMemoryUsage("key")
+	assert.Nil(t, err)
+	assert.True(t, usage > 0)
+
+	assert.Nil(t, store.Delete("missing"))
+
+	usage, err = store.(KeyMemoryReporter).KeyMemoryUsage("missing")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), usage)
+
+	assert.Nil(t, store.Close())
+}
+
+func TestRedisStorePipeline(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+
+	assert.Nil(t, err)
+
+	_, ok := NewPipeline(store).(*redisPipeline)
+	assert.True(t, ok)
+
+	testPipeline(t, store)
+
+	assert.Nil(t, store.Close())
+}
+
+func TestRedisStoreTxn(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+
+	assert.Nil(t, err)
+
+	testTxn(t, store)
+
+	// A key modified between the read and the write aborts the transaction.
+
+	err = store.(Transactioner).Txn(func(tx Txn) error {
+		v, err := tx.Get("key")
+		assert.Nil(t, err)
+
+		assert.Nil(t, store.Set("key", "changed"))
+
+		tx.Set("key", conv.String(v)+"!")
+		return nil
+	}, "key")
+	assert.Equal(t, ErrTxnConflict, err)
+
+	v, err := store.Get("key")
+	assert.Nil(t, err)
+	assert.Equal(t, "changed", v)
+
+	assert.Nil(t, store.Close())
+}
+
+func TestRedisStoreScript(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+
+	assert.Nil(t, err)
+
+	rs := store.(*RedisStore)
+	assert.Nil(t, rs.client.Process(rs.ctx, redis.NewStatusCmd(rs.ctx, "script", "flush")))
+
+	setIfEqual := NewScript(`
+		if redis.call("GET", KEYS[1]) == ARGV[1] then
+			return redis.call("SET", KEYS[1], ARGV[2])
+		end
+		return false
+	`)
+
+	assert.Nil(t, store.Set("key", "old"))
+
+	v, err := rs.RunScript(setIfEqual, []string{"key"}, "old", "new")
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", v)
+
+	v, err = rs.RunScript(setIfEqual, []string{"key"}, "old", "newer")
+	assert.Nil(t, err)
+	assert.Nil(t, v)
+
+	v, err = store.Get("key")
+	assert.Nil(t, err)
+	assert.Equal(t, "new", v)
+
+	assert.Nil(t, rs.LoadScript(setIfEqual))
+
+	v, err = rs.Eval(`return ARGV[1]`, nil, "echo")
+	assert.Nil(t, err)
+	assert.Equal(t, "echo", v)
+
+	assert.Nil(t, store.Close())
+}
+
+func TestRedisStoreLocker(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+
+	assert.Nil(t, err)
+
+	testLocker(t, store, time.Sleep)
+
+	assert.Nil(t, store.Close())
+}
+
+func TestRedisStorePubSub(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+
+	assert.Nil(t, err)
+
+	testPubSub(t, store)
+
+	assert.Nil(t, store.Close())
+}
+
+func TestRedisStoreStreams(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+	is.Nil(err)
+
+	streams := store.(Streams)
+
+	is.Nil(store.Delete("events"))
+	is.Nil(streams.XGroupCreate("events", "workers", "$"))
+
+	id, err := streams.XAdd("events", map[string]interface{}{"type": "created"})
+	is.Nil(err)
+	is.NotEmpty(id)
+
+	messages, err := streams.XRead(StreamReadArgs{Streams: map[string]string{"events": "0"}})
+	is.Nil(err)
+	is.Equal([]StreamMessage{{Stream: "events", ID: id, Values: map[string]interface{}{"type": "created"}}}, messages)
+
+	messages, err = streams.XRead(StreamReadArgs{Streams: map[string]string{"events": id}})
+	is.Nil(err)
+	is.Nil(messages)
+
+	messages, err = streams.XReadGroup("workers", "w1", StreamReadArgs{Streams: map[string]string{"events": ">"}, Count: 10})
+	is.Nil(err)
+	is.Len(messages, 1)
+	is.Nil(streams.XAck("events", "workers", id))
+
+	messages, err = streams.XReadGroup("workers", "w1", StreamReadArgs{Streams: map[string]string{"events": "0"}})
+	is.Nil(err)
+	is.Empty(messages)
+
+	is.Nil(store.Delete("events"))
+	is.Nil(store.Close())
+}
+
+func TestRedisStoreHyperLogLog(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+
+	assert.Nil(t, err)
+
+	testHyperLogLog(t, store)
+
+	assert.Nil(t, store.Close())
+}
+
+func TestRedisStoreGeo(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+
+	assert.Nil(t, err)
+
+	testGeo(t, store)
+
+	assert.Nil(t, store.Close())
+}
+
+func TestWithContext(t *testing.T) {
+	is := assert.New(t)
+
+	store := newRedisStore(nil, time.Second, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scoped := store.WithContext(ctx)
+	is.Equal(ctx, scoped.ctx)
+	is.Equal(context.Background(), store.ctx)
+	is.True(store.stats == scoped.stats)
+}
+
+func TestWithDB(t *testing.T) {
+	store := newRedisStore(redis.NewClusterClient(&redis.ClusterOptions{}), time.Second, nil)
+
+	_, err := store.WithDB(1)
+	assert.Equal(t, ErrNotSupported, err)
+}
+
+func TestRedisStoreWithDB(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+	is.Nil(err)
+
+	other, err := store.(*RedisStore).WithDB(1)
+	is.Nil(err)
+	is.Equal(1, other.db)
+
+	is.Nil(store.Delete("db"))
+	is.Nil(other.Set("db", "one"))
+
+	value, err := store.Get("db")
+	is.Nil(err)
+	is.Nil(value)
+
+	value, err = other.Get("db")
+	is.Nil(err)
+	is.Equal("one", value)
+
+	is.Nil(other.Delete("db"))
+	is.Nil(other.Close())
+	is.Nil(store.Close())
+}
+
+func TestRedisStoreWithContext(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+	is.Nil(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = store.(*RedisStore).WithContext(ctx).Get("key")
+	is.Equal(context.Canceled, err)
+
+	is.Nil(store.Close())
+}
+
+func TestRedisStoreKeys(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+	is.Nil(err)
+
+	is.Nil(store.Flush())
+	is.Nil(store.Set("session:1", "a"))
+	is.Nil(store.Set("session:2", "b"))
+	is.Nil(store.Set("user:1", "c"))
+
+	rs := store.(*RedisStore)
+
+	keys, err := rs.Keys("session:*")
+	is.Nil(err)
+	is.ElementsMatch([]string{"session:1", "session:2"}, keys)
+
+	deleted, err := rs.DeletePattern("session:*")
+	is.Nil(err)
+	is.Equal(int64(2), deleted)
+
+	keys, err = rs.Keys("*")
+	is.Nil(err)
+	is.Equal([]string{"user:1"}, keys)
+
+	is.Nil(store.Close())
+}
+
+func TestRedisStoreAnalyzeKeyspace(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+	is.Nil(err)
+
+	is.Nil(store.Flush())
+	is.Nil(store.Set("small", "a"))
+	is.Nil(store.SetMap("map", map[string]interface{}{"a": 1, "b": 2}))
+	is.Nil(store.(*RedisStore).client.Persist(context.Background(), "small").Err())
+
+	report, err := AnalyzeKeyspace(store, nil)
+	is.Nil(err)
+	is.Equal(int64(2), report.Keys)
+	is.Equal(map[string]int64{"string": 1, "hash": 1}, report.Types)
+	is.Equal(int64(1), report.Expiring)
+	is.Len(report.Largest, 2)
+	is.True(report.Size > 0)
+
+	is.Nil(store.Close())
+}
+
+func TestRedisStoreExpireMany(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, 0)
+
+	assert.Nil(t, err)
+
+	testExpireMany(t, store, time.Sleep)
+
+	assert.Nil(t, store.Close())
+}
+
+func TestRedisStoreRange(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, 0)
+
+	assert.Nil(t, err)
+
+	testRangeStore(t, store)
+
+	assert.Nil(t, store.Close())
+}
+
+func TestRedisStoreMapField(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+
+	assert.Nil(t, err)
+
+	testMapFieldStore(t, store, time.Sleep)
+
+	assert.Nil(t, store.Close())
+}
+
+func TestRedisStoreList(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+
+	assert.Nil(t, err)
+
+	testListStore(t, store)
+
+	assert.Nil(t, store.Close())
+}
+
+func TestRedisStoreDelayed(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+
+	assert.Nil(t, err)
+
+	testDelayedSetter(t, store)
+
+	// Promotion goes on once the context scheduling the value is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	is := assert.New(t)
+	is.Nil(store.(*RedisStore).WithContext(ctx).SetDelayed("cancelled", "visible", time.Now().Add(200*time.Millisecond)))
+	cancel()
+
+	time.Sleep(500 * time.Millisecond)
+
+	value, err := store.Get("cancelled")
+	is.Nil(err)
+	is.Equal("visible", value)
+
+	assert.Nil(t, store.Close())
+}
+
+func TestRedisStoreClientName(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr:       "localhost:6379",
+		ClientName: "sessions-cache",
+	}, time.Second*30)
+	is.Nil(err)
+
+	rs := store.(*RedisStore)
+
+	name, err := rs.client.ClientGetName(rs.ctx).Result()
+	is.Nil(err)
+	is.Equal("sessions-cache", name)
+
+	is.Nil(store.Close())
+}
+
+func TestRedisStoreGetAndRefresh(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+
+	assert.Nil(t, err)
+
+	testRefresher(t, store, time.Sleep)
+
+	assert.Nil(t, store.Close())
+}
+
+func TestRedisStoreUnlink(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr:   "localhost:6379",
+		Unlink: true,
+	}, time.Second*30)
+	is.Nil(err)
+
+	is.Nil(store.SetMap("big", map[string]interface{}{"a": 1, "b": 2}))
+	is.Nil(store.Delete("big"))
+
+	exists, err := store.Exists("big")
+	is.Nil(err)
+	is.False(exists)
+
+	is.Nil(store.Close())
+}
+
+func TestRedisStoreClientSideCache(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr:            "localhost:6379",
+		ClientSideCache: &ClientSideCacheOptions{},
+	}, time.Second*30)
+	is.Nil(err)
+
+	other, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+	is.Nil(err)
+
+	is.Nil(store.Set("cached", "a"))
+
+	value, err := store.Get("cached")
+	is.Nil(err)
+	is.Equal("a", value)
+
+	is.Nil(other.Set("cached", "b"))
+
+	// Invalidations are pushed asynchronously.
+	time.Sleep(100 * time.Millisecond)
+
+	value, err = store.Get("cached")
+	is.Nil(err)
+	is.Equal("b", value)
+
+	is.Nil(store.Set("cached", "c"))
+
+	value, err = store.Get("cached")
+	is.Nil(err)
+	is.Equal("c", value)
+
+	is.Nil(store.Close())
+	is.Nil(other.Close())
+}
+
+func TestRedisStoreWithWait(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+	is.Nil(err)
+
+	rs := store.(*RedisStore)
+
+	is.Nil(rs.WithWait(0, 0).Set("durable", "a"))
+
+	// The test server has no replica.
+	err = rs.WithWait(1, 50*time.Millisecond).Set("durable", "b")
+	is.Equal(&NotReplicatedError{Replicas: 1, Acknowledged: 0}, err)
+
+	value, err := store.Get("durable")
+	is.Nil(err)
+	is.Equal("b", value)
+
+	is.Nil(store.Close())
+}
+
+func TestRedisStoreJSON(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+
+	assert.Nil(t, err)
+
+	testJSONStore(t, store)
+
+	assert.Nil(t, store.Close())
+}
+
+func TestRedisStoreSearch(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+
+	assert.Nil(t, err)
+
+	testSearch(t, store)
+
+	assert.Nil(t, store.Close())
+}
+
+func TestRedisStoreBloomFilter(t *testing.T) {
+	store, err := NewRedisClientStore(&RedisClientOptions{
+		Addr: "localhost:6379",
+	}, time.Second*30)
+
+	assert.Nil(t, err)
+
+	testBloomFilter(t, store)
+
+	assert.Nil(t, store.Close())
+}
+
+func TestMaxRetries(t *testing.T) {
+	is := assert.New(t)
+
+	is.Equal(-1, maxRetries(0))
+	is.Equal(-1, maxRetries(-1))
+	is.Equal(5, maxRetries(5))
+}
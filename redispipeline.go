@@ -0,0 +1,184 @@
+package gokvstores
+
+import (
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisPipeline is the Pipeline of RedisStore, sending the queued operations
+// in a single round trip.
+type redisPipeline struct {
+	store *RedisStore
+	pipe  redis.Pipeliner
+
+	// finish complete the results of the queued operations once executed.
+	finish []func() error
+}
+
+// Pipeline returns a pipeline sending its operations in a single round trip.
+func (r *RedisStore) Pipeline() Pipeline {
+	return &redisPipeline{store: r, pipe: r.client.Pipeline()}
+}
+
+// read queues the completion of a lookup.
+func (p *redisPipeline) read(result *PipelineResult, complete func() (interface{}, bool, error)) {
+	p.finish = append(p.finish, func() error {
+		value, hit, err := complete()
+		p.store.stats.read(hit, err)
+		result.value, result.err = value, err
+		return err
+	})
+}
+
+// write queues the completion of a write.
+func (p *redisPipeline) write(result *PipelineResult, cmd redis.Cmder) {
+	p.finish = append(p.finish, func() error {
+		err := cmd.Err()
+		p.store.stats.write(err)
+		result.err = err
+		return err
+	})
+}
+
+// done queues an operation completed without being sent, because it failed
+// or had nothing to send.
+func (p *redisPipeline) done(result *PipelineResult, err error) {
+	p.finish = append(p.finish, func() error {
+		p.store.stats.write(err)
+		result.err = err
+		return err
+	})
+}
+
+func (p *redisPipeline) Get(key string) *PipelineResult {
+	result := &PipelineResult{}
+	cmd := p.pipe.Get(p.store.ctx, key)
+
+	p.read(result, func() (interface{}, bool, error) {
+		value, err := p.store.decodeString(cmd)
+		return value, value != nil, err
+	})
+
+	return result
+}
+
+func (p *redisPipeline) Set(key string, value interface{}) *PipelineResult {
+	return p.SetWithExpiration(key, value, p.store.expiration)
+}
+
+func (p *redisPipeline) SetWithExpiration(key string, value interface{}, expiration time.Duration) *PipelineResult {
+	result := &PipelineResult{}
+
+	if expiration < 0 {
+		expiration = 0
+	}
+
+	encoded, err := p.store.encode(value)
+	if err != nil {
+		p.done(result, err)
+		return result
+	}
+
+	p.write(result, p.pipe.Set(p.store.ctx, key, encoded, expiration))
+
+	return result
+}
+
+func (p *redisPipeline) GetMap(key string) *PipelineResult {
+	result := &PipelineResult{}
+	cmd := p.pipe.HGetAll(p.store.ctx, key)
+
+	p.read(result, func() (interface{}, bool, error) {
+		value, err := p.store.decodeMap(cmd)
+		return value, value != nil, err
+	})
+
+	return result
+}
+
+func (p *redisPipeline) SetMap(key string, value map[string]interface{}) *PipelineResult {
+	result := &PipelineResult{}
+
+	fields, err := p.store.encodeMap(value)
+	if err != nil {
+		p.done(result, err)
+		return result
+	}
+
+	p.write(result, p.pipe.HSet(p.store.ctx, key, fields))
+
+	return result
+}
+
+func (p *redisPipeline) GetSlice(key string) *PipelineResult {
+	result := &PipelineResult{}
+	cmd := p.pipe.SMembers(p.store.ctx, key)
+
+	p.read(result, func() (interface{}, bool, error) {
+		value, err := p.store.decodeSlice(cmd)
+		return value, value != nil, err
+	})
+
+	return result
+}
+
+func (p *redisPipeline) SetSlice(key string, values []interface{}) *PipelineResult {
+	result := &PipelineResult{}
+
+	members, err := p.store.encodeSlice(values)
+	if err != nil || len(members) == 0 {
+		p.done(result, err)
+		return result
+	}
+
+	p.write(result, p.pipe.SAdd(p.store.ctx, key, members...))
+
+	return result
+}
+
+func (p *redisPipeline) AppendSlice(key string, values ...interface{}) *PipelineResult {
+	return p.SetSlice(key, values)
+}
+
+func (p *redisPipeline) Exists(key string) *PipelineResult {
+	result := &PipelineResult{}
+	cmd := p.pipe.Exists(p.store.ctx, key)
+
+	p.read(result, func() (interface{}, bool, error) {
+		n, err := cmd.Result()
+		return n > 0, n > 0, err
+	})
+
+	return result
+}
+
+func (p *redisPipeline) Delete(key string) *PipelineResult {
+	result := &PipelineResult{}
+	p.write(result, p.store.del(p.pipe, key))
+	return result
+}
+
+func (p *redisPipeline) Exec() error {
+	// Exec returns the first command error, redis.Nil for missing keys, and
+	// sets network errors on every command: results are read from the commands.
+	p.pipe.Exec(p.store.ctx)
+
+	return p.complete()
+}
+
+// complete sets the results of the executed operations and returns the
+// first error.
+func (p *redisPipeline) complete() error {
+	finish := p.finish
+	p.finish = nil
+
+	var err error
+	for _, f := range finish {
+		if ferr := f(); err == nil {
+			err = ferr
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,93 @@
+package gokvstores
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+)
+
+// StoreFactory returns the store described by a URL whose scheme is the name
+// it was registered with. The URL query holds the options of the store.
+type StoreFactory func(u *url.URL) (KVStore, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]StoreFactory{}
+)
+
+func init() {
+	Register("redis", redisFactory)
+	Register("rediss", redisFactory)
+	Register("memory", memoryFactory)
+	Register("dummy", func(u *url.URL) (KVStore, error) {
+		return DummyStore{}, nil
+	})
+}
+
+// Register makes a backend available under the given name to
+// NewStoreFromURL, as the scheme of the URL, and to the other constructors
+// built on it, so that backends defined by other packages are selected by
+// configuration as the ones of this package. It is meant to be called from
+// the init function of the package defining the backend. Like sql.Register,
+// it panics if factory is nil or the name is already registered.
+func Register(name string, factory StoreFactory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if factory == nil {
+		panic("gokvstores: Register factory is nil")
+	}
+	if _, dup := factories[name]; dup {
+		panic("gokvstores: Register called twice for backend " + name)
+	}
+
+	factories[name] = factory
+}
+
+// Backends returns the sorted names of the registered backends.
+func Backends() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// factory returns the factory registered with the given name.
+func factory(name string) (StoreFactory, error) {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	f, ok := factories[name]
+	if !ok {
+		return nil, fmt.Errorf("gokvstores: unknown backend %q (forgotten import?)", name)
+	}
+
+	return f, nil
+}
+
+// redisFactory is the StoreFactory of the redis and rediss backends.
+func redisFactory(u *url.URL) (KVStore, error) {
+	options, expiration, err := redisURLOptions(u)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRedisUniversalStore(options, expiration)
+}
+
+// memoryFactory is the StoreFactory of the memory backend.
+func memoryFactory(u *url.URL) (KVStore, error) {
+	options, err := memoryURLOptions(u)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMemoryStoreWithOptions(options)
+}
@@ -0,0 +1,40 @@
+package gokvstores
+
+import (
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	registerTestBackend sync.Once
+	received            *url.URL
+)
+
+func TestRegister(t *testing.T) {
+	is := assert.New(t)
+
+	registerTestBackend.Do(func() {
+		Register("test", func(u *url.URL) (KVStore, error) {
+			received = u
+			return NewMemoryStore(0, 0)
+		})
+	})
+
+	is.Equal([]string{"dummy", "memory", "redis", "rediss", "test"}, Backends())
+
+	store, err := NewStoreFromURL("test://host/path?option=value")
+	is.Nil(err)
+	is.IsType(&MemoryStore{}, store)
+	is.Equal("host", received.Host)
+	is.Equal("value", received.Query().Get("option"))
+
+	store, err = NewStoreFromURL("dummy://")
+	is.Nil(err)
+	is.Equal(DummyStore{}, store)
+
+	is.Panics(func() { Register("memory", memoryFactory) })
+	is.Panics(func() { Register("nil", nil) })
+}
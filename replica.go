@@ -0,0 +1,162 @@
+package gokvstores
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// replicaCheckInterval is how often the staleness of replicas is checked.
+const replicaCheckInterval = time.Second
+
+// replicaSet are the replicas a RedisStore reads from.
+type replicaSet struct {
+	clients      []*redis.Client
+	maxStaleness time.Duration
+
+	// healthy flags the replicas reads can be routed to.
+	healthy []int32
+	next    uint32
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newReplicaSet returns a replicaSet connecting to addrs with the given
+// options. With maxStaleness, replicas are checked periodically, and skipped
+// while lagging.
+func newReplicaSet(opts redis.Options, addrs []string, maxStaleness time.Duration) *replicaSet {
+	s := &replicaSet{
+		maxStaleness: maxStaleness,
+		healthy:      make([]int32, len(addrs)),
+		stop:         make(chan struct{}),
+	}
+
+	for i, addr := range addrs {
+		opts.Addr = addr
+		s.clients = append(s.clients, redis.NewClient(&opts))
+		s.healthy[i] = 1
+	}
+
+	if maxStaleness > 0 {
+		s.check()
+
+		s.wg.Add(1)
+		go s.run()
+	}
+
+	return s
+}
+
+// withDB returns a new replicaSet connecting to the same replicas, using the
+// logical database db.
+func (s *replicaSet) withDB(db int) *replicaSet {
+	opts := *s.clients[0].Options()
+	opts.DB = db
+
+	addrs := make([]string, len(s.clients))
+	for i, client := range s.clients {
+		addrs[i] = client.Options().Addr
+	}
+
+	return newReplicaSet(opts, addrs, s.maxStaleness)
+}
+
+// run checks the replicas until the set is closed.
+func (s *replicaSet) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(replicaCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.check()
+		}
+	}
+}
+
+// check flags the replicas whose link to the primary is up, and was last
+// used within maxStaleness, as healthy.
+func (s *replicaSet) check() {
+	for i, client := range s.clients {
+		ctx, cancel := context.WithTimeout(context.Background(), replicaCheckInterval)
+		info, err := client.Info(ctx, "replication").Result()
+		cancel()
+
+		healthy := int32(0)
+		if err == nil && replicaFresh(parseInfo(info), s.maxStaleness) {
+			healthy = 1
+		}
+
+		atomic.StoreInt32(&s.healthy[i], healthy)
+	}
+}
+
+// replicaFresh reports whether the INFO replication fields of a replica show
+// it is connected to its primary, and heard from it within maxStaleness.
+func replicaFresh(fields map[string]string, maxStaleness time.Duration) bool {
+	if fields["role"] != "slave" || fields["master_link_status"] != "up" {
+		return false
+	}
+
+	seconds, err := strconv.Atoi(fields["master_last_io_seconds_ago"])
+	if err != nil {
+		return false
+	}
+
+	return time.Duration(seconds)*time.Second <= maxStaleness
+}
+
+// client returns the next healthy replica, nil if there is none.
+func (s *replicaSet) client() *redis.Client {
+	if i := s.pick(); i >= 0 {
+		return s.clients[i]
+	}
+
+	return nil
+}
+
+// pick returns the index of the next healthy replica, -1 if there is none.
+func (s *replicaSet) pick() int {
+	n := uint32(len(s.clients))
+	start := atomic.AddUint32(&s.next, 1)
+
+	for i := uint32(0); i < n; i++ {
+		j := (start + i) % n
+		if atomic.LoadInt32(&s.healthy[j]) == 1 {
+			return int(j)
+		}
+	}
+
+	return -1
+}
+
+// close stops the checks and closes the replica connections.
+func (s *replicaSet) close() {
+	close(s.stop)
+	s.wg.Wait()
+
+	for _, client := range s.clients {
+		client.Close()
+	}
+}
+
+// reader returns the client reads are sent to: a healthy replica if any,
+// the primary otherwise.
+func (r *RedisStore) reader() redis.Cmdable {
+	if r.replicas != nil {
+		if client := r.replicas.client(); client != nil {
+			return client
+		}
+	}
+
+	return r.client
+}
@@ -0,0 +1,45 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplicaFresh(t *testing.T) {
+	is := assert.New(t)
+
+	fields := parseInfo("# Replication\r\nrole:slave\r\nmaster_link_status:up\r\nmaster_last_io_seconds_ago:2\r\n")
+	is.True(replicaFresh(fields, 5*time.Second))
+	is.False(replicaFresh(fields, time.Second))
+
+	fields["master_link_status"] = "down"
+	is.False(replicaFresh(fields, 5*time.Second))
+
+	is.False(replicaFresh(parseInfo("role:master\r\n"), 5*time.Second))
+}
+
+func TestReplicaSetClient(t *testing.T) {
+	is := assert.New(t)
+
+	s := newReplicaSet(redis.Options{}, []string{"replica1:6379", "replica2:6379"}, 0)
+	defer s.close()
+
+	first, second := s.pick(), s.pick()
+	is.NotEqual(first, second)
+	is.NotNil(s.client())
+
+	s.healthy[0] = 0
+	is.Equal(1, s.pick())
+	is.Equal(1, s.pick())
+
+	s.healthy[1] = 0
+	is.Equal(-1, s.pick())
+	is.Nil(s.client())
+
+	store := newRedisStore(nil, 0, nil)
+	store.replicas = s
+	is.Nil(store.reader())
+}
@@ -0,0 +1,170 @@
+package gokvstores
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy defines how a failed operation is retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// A value lower than 2 disables retries.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between two attempts.
+	MaxBackoff time.Duration
+
+	// Multiplier is the backoff growth factor between attempts. Defaults to 2.
+	Multiplier float64
+
+	// Jitter is the fraction (between 0 and 1) of each delay which is randomized.
+	Jitter float64
+}
+
+// backoff returns the delay to wait before the given retry (starting at 1).
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+
+	delay := float64(p.InitialBackoff)
+	for i := 1; i < retry; i++ {
+		delay *= multiplier
+	}
+
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(delay)
+}
+
+// DefaultRetryPolicy is the policy used when none is configured.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 10 * time.Millisecond,
+	MaxBackoff:     time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// RetryOptions are RetryStore options.
+type RetryOptions struct {
+	// Policy is the retry policy of all operations. Defaults to DefaultRetryPolicy.
+	Policy *RetryPolicy
+
+	// Operations overrides the policy per operation, keyed by method name (e.g. "Get").
+	// Operations which are not idempotent, such as AppendSlice, are only
+	// retried with an entry here: a timeout does not tell whether they ran.
+	Operations map[string]RetryPolicy
+
+	// Retryable reports whether an error is worth retrying. Defaults to IsTransientError.
+	Retryable func(err error) bool
+}
+
+// IsTransientError reports whether the given error is a transient network failure
+// (timeout, connection reset or refused, broken pipe, unexpected EOF).
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	for _, target := range []error{
+		io.EOF,
+		io.ErrUnexpectedEOF,
+		syscall.ECONNRESET,
+		syscall.ECONNREFUSED,
+		syscall.ECONNABORTED,
+		syscall.EPIPE,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nonIdempotent are the operations applied twice when retried after they
+// ran, which are not retried by default.
+var nonIdempotent = map[string]bool{
+	"AppendSlice": true,
+}
+
+// RetryStore is a KVStore decorator retrying operations failing with transient errors.
+// Close is never retried, nor are the operations which are not idempotent
+// unless configured in RetryOptions.Operations.
+type RetryStore struct {
+	*interceptedStore
+
+	policy     RetryPolicy
+	operations map[string]RetryPolicy
+	retryable  func(err error) bool
+	sleep      func(time.Duration)
+}
+
+// NewRetryStore returns a RetryStore wrapping the given store.
+func NewRetryStore(store KVStore, options *RetryOptions) *RetryStore {
+	if options == nil {
+		options = &RetryOptions{}
+	}
+
+	r := &RetryStore{
+		policy:     DefaultRetryPolicy,
+		operations: options.Operations,
+		retryable:  options.Retryable,
+		sleep:      time.Sleep,
+	}
+
+	if options.Policy != nil {
+		r.policy = *options.Policy
+	}
+
+	if r.retryable == nil {
+		r.retryable = IsTransientError
+	}
+
+	r.interceptedStore = &interceptedStore{store: store, intercept: r.retry}
+
+	return r
+}
+
+// retry runs the operation until it succeeds, fails permanently or exhausts its attempts.
+func (r *RetryStore) retry(op *operation, next func() error) error {
+	if op.name == "Close" {
+		return next()
+	}
+
+	policy, ok := r.operations[op.name]
+	if !ok {
+		if nonIdempotent[op.name] {
+			return next()
+		}
+		policy = r.policy
+	}
+
+	err := next()
+	for attempt := 1; attempt < policy.MaxAttempts && r.retryable(err); attempt++ {
+		r.sleep(policy.backoff(attempt))
+		err = next()
+	}
+
+	return err
+}
@@ -0,0 +1,117 @@
+package gokvstores
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyStore is a KVStore whose Get fails with err the first failures times.
+type flakyStore struct {
+	KVStore
+	err      error
+	failures int
+	calls    int
+}
+
+func (s *flakyStore) Get(key string) (interface{}, error) {
+	s.calls++
+	if s.calls <= s.failures {
+		return nil, s.err
+	}
+	return s.KVStore.Get(key)
+}
+
+func (s *flakyStore) AppendSlice(key string, values ...interface{}) error {
+	s.calls++
+	if s.calls <= s.failures {
+		return s.err
+	}
+	return s.KVStore.AppendSlice(key, values...)
+}
+
+func TestRetryStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	testStore(t, NewRetryStore(memory, nil))
+
+	is.Nil(memory.Set("key", "value"))
+
+	flaky := &flakyStore{KVStore: memory, err: syscall.ECONNRESET, failures: 2}
+	store := NewRetryStore(flaky, nil)
+
+	delays := []time.Duration{}
+	store.sleep = func(d time.Duration) { delays = append(delays, d) }
+
+	v, err := store.Get("key")
+	is.Nil(err)
+	is.Equal("value", v)
+	is.Equal(3, flaky.calls)
+	is.Len(delays, 2)
+
+	// Exhausted attempts
+
+	flaky = &flakyStore{KVStore: memory, err: syscall.ECONNRESET, failures: 5}
+	store = NewRetryStore(flaky, &RetryOptions{
+		Operations: map[string]RetryPolicy{"Get": {MaxAttempts: 4}},
+	})
+	store.sleep = func(time.Duration) {}
+
+	_, err = store.Get("key")
+	is.Equal(syscall.ECONNRESET, err)
+	is.Equal(4, flaky.calls)
+
+	// Permanent errors
+
+	flaky = &flakyStore{KVStore: memory, err: errors.New("permanent"), failures: 5}
+	store = NewRetryStore(flaky, nil)
+	store.sleep = func(time.Duration) {}
+
+	_, err = store.Get("key")
+	is.NotNil(err)
+	is.Equal(1, flaky.calls)
+
+	// Operations which are not idempotent
+
+	flaky = &flakyStore{KVStore: memory, err: syscall.ETIMEDOUT, failures: 1}
+	store = NewRetryStore(flaky, nil)
+	store.sleep = func(time.Duration) {}
+
+	is.Equal(syscall.ETIMEDOUT, store.AppendSlice("slice", "a"))
+	is.Equal(1, flaky.calls)
+
+	flaky = &flakyStore{KVStore: memory, err: syscall.ETIMEDOUT, failures: 1}
+	store = NewRetryStore(flaky, &RetryOptions{
+		Operations: map[string]RetryPolicy{"AppendSlice": {MaxAttempts: 2}},
+	})
+	store.sleep = func(time.Duration) {}
+
+	is.Nil(store.AppendSlice("slice", "a"))
+	is.Equal(2, flaky.calls)
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	is := assert.New(t)
+
+	policy := RetryPolicy{
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     50 * time.Millisecond,
+	}
+
+	is.Equal(10*time.Millisecond, policy.backoff(1))
+	is.Equal(20*time.Millisecond, policy.backoff(2))
+	is.Equal(40*time.Millisecond, policy.backoff(3))
+	is.Equal(50*time.Millisecond, policy.backoff(4))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := policy.backoff(1)
+		is.True(d >= 5*time.Millisecond && d <= 15*time.Millisecond)
+	}
+}
@@ -0,0 +1,129 @@
+package gokvstores
+
+import (
+	"strings"
+	"time"
+)
+
+// Item is a store entry, as enumerated by a Scanner.
+type Item struct {
+	Key string
+
+	// Value is the value of the key, a map[string]interface{} for maps and
+	// a []interface{} for slices.
+	Value interface{}
+
+	// Expiration is the time the key expires at, zero if it never expires.
+	Expiration time.Time
+}
+
+// Scanner is implemented by stores able to enumerate their items.
+type Scanner interface {
+	// Scan calls fn with each item of the store, stopping at the first error
+	// it returns. Keys written or deleted during the scan may not be seen.
+	Scan(fn func(item Item) error) error
+}
+
+// scanUserItems calls fn with the items of the given store, skipping the
+// keys under ReservedPrefix.
+func scanUserItems(store KVStore, fn func(item Item) error) error {
+	scanner, ok := store.(Scanner)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	return scanner.Scan(func(item Item) error {
+		if strings.HasPrefix(item.Key, ReservedPrefix) {
+			return nil
+		}
+		return fn(item)
+	})
+}
+
+// Expirer is implemented by stores able to change the expiration of a key,
+// whatever its type.
+type Expirer interface {
+	// Expire sets the expiration of the given key. Zero or negative means the
+	// key never expires.
+	Expire(key string, expiration time.Duration) error
+}
+
+// BatchExpirer is implemented by stores able to change the expiration of many
+// keys at once.
+type BatchExpirer interface {
+	// ExpireMany sets the expiration of each key, as Expire does. Missing keys
+	// are ignored.
+	ExpireMany(expirations map[string]time.Duration) error
+}
+
+// Refresher is implemented by stores able to extend the expiration of a key
+// as it is read.
+type Refresher interface {
+	// GetAndRefresh returns the value of the given key, as Get does, and sets
+	// its expiration to ttl. Zero or negative means the key never expires.
+	GetAndRefresh(key string, ttl time.Duration) (interface{}, error)
+}
+
+// clockedStore is implemented by stores expiring their items by their own
+// Clock.
+type clockedStore interface {
+	now() time.Time
+}
+
+// itemReplacer is implemented by stores able to replace a key with a value
+// of any kind, and its time to live, atomically.
+type itemReplacer interface {
+	// replaceItem sets value for the given key, replacing any existing value,
+	// with the given time to live. Zero means the key never expires.
+	replaceItem(key string, value interface{}, ttl time.Duration) error
+}
+
+// setItem writes the item to the given store with its remaining time to live,
+// as told by the clock of the store, replacing any existing value. Expired
+// items are skipped.
+//
+// Stores implementing itemReplacer write the item atomically. Others have
+// maps and slices deleted, written then expired in separate calls, and only
+// keep their expiration if they implement Expirer.
+func setItem(store KVStore, item Item) error {
+	var ttl time.Duration
+	if !item.Expiration.IsZero() {
+		now := time.Now()
+		if clocked, ok := store.(clockedStore); ok {
+			now = clocked.now()
+		}
+
+		if ttl = item.Expiration.Sub(now); ttl <= 0 {
+			return nil
+		}
+	}
+
+	if replacer, ok := store.(itemReplacer); ok {
+		return replacer.replaceItem(item.Key, item.Value, ttl)
+	}
+
+	var set func() error
+	switch value := item.Value.(type) {
+	case map[string]interface{}:
+		set = func() error { return store.SetMap(item.Key, value) }
+	case []interface{}:
+		set = func() error { return store.SetSlice(item.Key, value) }
+	default:
+		return store.SetWithExpiration(item.Key, value, ttl)
+	}
+
+	// Maps and slices are merged with existing values by some backends.
+	if err := store.Delete(item.Key); err != nil {
+		return err
+	}
+
+	if err := set(); err != nil {
+		return err
+	}
+
+	if expirer, ok := store.(Expirer); ok {
+		return expirer.Expire(item.Key, ttl)
+	}
+
+	return nil
+}
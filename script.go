@@ -0,0 +1,42 @@
+package gokvstores
+
+import "github.com/redis/go-redis/v9"
+
+// Script is a Lua script run by RedisStore.RunScript.
+type Script struct {
+	script *redis.Script
+}
+
+// NewScript returns a Script with the given Lua source.
+func NewScript(src string) *Script {
+	return &Script{script: redis.NewScript(src)}
+}
+
+// LoadScript loads the script in the server cache, so later runs don't
+// send its source.
+func (r *RedisStore) LoadScript(script *Script) error {
+	return script.script.Load(r.ctx, r.client).Err()
+}
+
+// RunScript runs the script with EVALSHA, falling back to EVAL, which also
+// loads it, when the server doesn't know it yet. Replies are returned as sent
+// by Redis, without going through the store codec; a nil reply returns nil.
+func (r *RedisStore) RunScript(script *Script, keys []string, args ...interface{}) (interface{}, error) {
+	value, err := script.script.Run(r.ctx, r.client, keys, args...).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+
+	return value, err
+}
+
+// Eval runs the given Lua source with EVAL. Prefer RunScript for scripts run
+// more than once.
+func (r *RedisStore) Eval(src string, keys []string, args ...interface{}) (interface{}, error) {
+	value, err := r.client.Eval(r.ctx, src, keys, args...).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+
+	return value, err
+}
@@ -0,0 +1,278 @@
+package gokvstores
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	conv "github.com/cstockton/go-conv"
+	"github.com/redis/go-redis/v9"
+)
+
+// searchPageSize is the number of results fetched per FT.SEARCH.
+const searchPageSize = 1000
+
+// SearchIndex is a secondary index of map values.
+type SearchIndex struct {
+	// Name identifies the index.
+	Name string
+
+	// Prefix selects the keys indexed.
+	Prefix string
+
+	// Fields are the map fields which can be searched.
+	Fields []string
+}
+
+// Searcher is implemented by stores able to search map values by field.
+type Searcher interface {
+	// CreateIndex creates the index, if it does not exist yet.
+	CreateIndex(index SearchIndex) error
+
+	// Search returns the maps of the index whose fields equal all the values
+	// of query, ordered by key. An empty query returns all the maps.
+	Search(index string, query map[string]string) ([]Item, error)
+}
+
+// searchIndexes are the indexes created on a store.
+type searchIndexes struct {
+	mu      sync.RWMutex
+	indexes map[string]SearchIndex
+}
+
+// add records the index.
+func (s *searchIndexes) add(index SearchIndex) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.indexes == nil {
+		s.indexes = map[string]SearchIndex{}
+	}
+	s.indexes[index.Name] = index
+}
+
+// get returns the named index, after checking that query only uses its
+// fields.
+func (s *searchIndexes) get(name string, query map[string]string) (SearchIndex, error) {
+	s.mu.RLock()
+	index, ok := s.indexes[name]
+	s.mu.RUnlock()
+
+	if !ok {
+		return SearchIndex{}, ErrIndexNotFound
+	}
+
+	for field := range query {
+		if !index.indexes(field) {
+			return SearchIndex{}, fmt.Errorf("gokvstores: field %q is not indexed by %q", field, name)
+		}
+	}
+
+	return index, nil
+}
+
+// indexes reports whether the field is indexed.
+func (i SearchIndex) indexes(field string) bool {
+	for _, f := range i.Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSearch reports whether the fields of the map equal the query values.
+func matchSearch(values map[string]interface{}, query map[string]string) bool {
+	for field, expected := range query {
+		value, ok := values[field]
+		if !ok || conv.String(value) != expected {
+			return false
+		}
+	}
+	return true
+}
+
+// sortItems orders items by key.
+func sortItems(items []Item) {
+	sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+}
+
+// ----------------------------------------------------------------------------
+// Memory
+// ----------------------------------------------------------------------------
+
+// CreateIndex records the index. Searches scan the keys with its prefix.
+func (c *MemoryStore) CreateIndex(index SearchIndex) error {
+	c.indexes.add(index)
+	return nil
+}
+
+// Search returns the maps of the index whose fields equal all the values of
+// query, scanning every key of the store.
+func (c *MemoryStore) Search(name string, query map[string]string) ([]Item, error) {
+	index, err := c.indexes.get(name, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	err = c.Scan(func(item Item) error {
+		values, ok := item.Value.(map[string]interface{})
+		if ok && strings.HasPrefix(item.Key, index.Prefix) && matchSearch(values, query) {
+			items = append(items, item)
+		}
+		return nil
+	})
+
+	sortItems(items)
+	return items, err
+}
+
+// ----------------------------------------------------------------------------
+// Redis
+// ----------------------------------------------------------------------------
+
+// tagEscaper escapes the characters of RediSearch tag values.
+var tagEscaper = strings.NewReplacer(
+	`\`, `\\`, ",", `\,`, ".", `\.`, "<", `\<`, ">", `\>`, "{", `\{`, "}", `\}`,
+	"[", `\[`, "]", `\]`, `"`, `\"`, "'", `\'`, ":", `\:`, ";", `\;`, "!", `\!`,
+	"@", `\@`, "#", `\#`, "$", `\$`, "%", `\%`, "^", `\^`, "&", `\&`, "*", `\*`,
+	"(", `\(`, ")", `\)`, "-", `\-`, "+", `\+`, "=", `\=`, "~", `\~`, "|", `\|`,
+	"/", `\/`, " ", `\ `,
+)
+
+// CreateIndex creates the index with FT.CREATE, its fields being
+// case-sensitive tags, when the server has the RediSearch module. Otherwise
+// searches scan the keys with the index prefix.
+func (r *RedisStore) CreateIndex(index SearchIndex) error {
+	if moduleAvailable(&r.modules.search) {
+		args := []interface{}{"ft.create", index.Name, "on", "hash", "prefix", 1, index.Prefix, "schema"}
+		for _, field := range index.Fields {
+			args = append(args, field, "tag", "casesensitive")
+		}
+
+		ok, err := r.moduleCommand(&r.modules.search, redis.NewStatusCmd(r.ctx, args...))
+		if err != nil && strings.Contains(err.Error(), "Index already exists") {
+			err = nil
+		}
+
+		if ok && err != nil {
+			return err
+		}
+	}
+
+	r.indexes.add(index)
+	return nil
+}
+
+// Search returns the maps of the index whose fields equal all the values of
+// query, with FT.SEARCH when the server has the RediSearch module.
+func (r *RedisStore) Search(name string, query map[string]string) ([]Item, error) {
+	index, err := r.indexes.get(name, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	if moduleAvailable(&r.modules.search) {
+		items, err = r.ftSearch(index, query)
+	} else {
+		items, err = r.scanSearch(index, query)
+	}
+
+	sortItems(items)
+	return items, err
+}
+
+// ftSearch runs the query with FT.SEARCH.
+func (r *RedisStore) ftSearch(index SearchIndex, query map[string]string) ([]Item, error) {
+	var terms []string
+	for field, value := range query {
+		// Tags hold the serialized field values.
+		encoded, err := r.encode(value)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, "@"+field+":{"+tagEscaper.Replace(encoded)+"}")
+	}
+	sort.Strings(terms)
+
+	expr := strings.Join(terms, " ")
+	if expr == "" {
+		expr = "*"
+	}
+
+	var items []Item
+	for offset := 0; ; offset += searchPageSize {
+		cmd := redis.NewSliceCmd(r.ctx, "ft.search", index.Name, expr, "limit", offset, searchPageSize)
+		if err := r.client.Process(r.ctx, cmd); err != nil {
+			return nil, err
+		}
+
+		total, page, err := r.parseSearch(cmd.Val())
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, page...)
+		if len(page) == 0 || offset+searchPageSize >= int(total) {
+			return items, nil
+		}
+	}
+}
+
+// parseSearch returns the total number of results and the documents of a
+// FT.SEARCH reply: [total, key, [field, value, ...], key, [...], ...].
+func (r *RedisStore) parseSearch(reply []interface{}) (int64, []Item, error) {
+	if len(reply) == 0 || len(reply)%2 != 1 {
+		return 0, nil, fmt.Errorf("gokvstores: unexpected search reply %v", reply)
+	}
+
+	total, _ := reply[0].(int64)
+
+	var items []Item
+	for i := 1; i < len(reply); i += 2 {
+		key, _ := reply[i].(string)
+		fields, _ := reply[i+1].([]interface{})
+
+		values := make(map[string]interface{}, len(fields)/2)
+		for j := 0; j+1 < len(fields); j += 2 {
+			field, _ := fields[j].(string)
+			data, _ := fields[j+1].(string)
+
+			value, err := r.decode(data)
+			if err != nil {
+				return 0, nil, err
+			}
+			values[field] = value
+		}
+
+		items = append(items, Item{Key: key, Value: values})
+	}
+
+	return total, items, nil
+}
+
+// scanSearch runs the query by scanning the keys with the index prefix.
+func (r *RedisStore) scanSearch(index SearchIndex, query map[string]string) ([]Item, error) {
+	var items []Item
+	err := r.scanKeys(globEscaper.Replace(index.Prefix)+"*", func(key string) error {
+		kind, err := r.client.Type(r.ctx, key).Result()
+		if err != nil || kind != "hash" {
+			return err
+		}
+
+		values, err := r.GetMap(key)
+		if err != nil {
+			return err
+		}
+
+		if values != nil && matchSearch(values, query) {
+			items = append(items, Item{Key: key, Value: values})
+		}
+		return nil
+	})
+
+	return items, err
+}
@@ -0,0 +1,59 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testSearch(t *testing.T, store KVStore) {
+	is := assert.New(t)
+
+	searcher := store.(Searcher)
+
+	is.Nil(store.Flush())
+
+	_, err := searcher.Search("users", nil)
+	is.Equal(ErrIndexNotFound, err)
+
+	is.Nil(searcher.CreateIndex(SearchIndex{Name: "users", Prefix: "user:", Fields: []string{"city", "role"}}))
+	is.Nil(searcher.CreateIndex(SearchIndex{Name: "users", Prefix: "user:", Fields: []string{"city", "role"}}))
+
+	is.Nil(store.SetMap("user:1", map[string]interface{}{"name": "Ada", "city": "New York", "role": "admin"}))
+	is.Nil(store.SetMap("user:2", map[string]interface{}{"name": "Alan", "city": "London", "role": "admin"}))
+	is.Nil(store.SetMap("user:3", map[string]interface{}{"name": "Grace", "city": "New York", "role": "user"}))
+	is.Nil(store.SetMap("admin:1", map[string]interface{}{"name": "Root", "city": "New York", "role": "admin"}))
+
+	items, err := searcher.Search("users", map[string]string{"city": "New York"})
+	is.Nil(err)
+	if is.Len(items, 2) {
+		is.Equal("user:1", items[0].Key)
+		is.Equal("Ada", items[0].Value.(map[string]interface{})["name"])
+		is.Equal("user:3", items[1].Key)
+	}
+
+	items, err = searcher.Search("users", map[string]string{"city": "New York", "role": "admin"})
+	is.Nil(err)
+	if is.Len(items, 1) {
+		is.Equal("user:1", items[0].Key)
+	}
+
+	items, err = searcher.Search("users", map[string]string{"city": "Paris"})
+	is.Nil(err)
+	is.Empty(items)
+
+	items, err = searcher.Search("users", nil)
+	is.Nil(err)
+	is.Len(items, 3)
+
+	_, err = searcher.Search("users", map[string]string{"name": "Ada"})
+	is.NotNil(err)
+}
+
+func TestMemoryStoreSearch(t *testing.T) {
+	store, err := NewMemoryStore(time.Second*10, time.Second*10)
+	assert.Nil(t, err)
+
+	testSearch(t, store)
+}
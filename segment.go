@@ -0,0 +1,407 @@
+package gokvstores
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// defaultSweepBatch is the default MemoryStoreOptions.SweepBatch.
+const defaultSweepBatch = 1000
+
+// defaultExpiration sets an item with the expiration of its segment.
+const defaultExpiration time.Duration = 0
+
+// segmentItem is an item of a segment.
+type segmentItem struct {
+	object interface{}
+
+	// expiration is the time the item expires at, in nanoseconds, zero if
+	// it does not.
+	expiration int64
+
+	// written is the time the item was set at, in nanoseconds, and ttl its
+	// time to live, zero if it does not expire, to slide its expiration.
+	written int64
+	ttl     time.Duration
+}
+
+// expired reports whether the item expired at the given time, in nanoseconds.
+func (item segmentItem) expired(now int64) bool {
+	return item.expiration > 0 && now > item.expiration
+}
+
+// segment is a part of the items of a MemoryStore, with its own lock, its
+// expirations being told by a Clock.
+type segment struct {
+	mu    sync.RWMutex
+	items map[string]segmentItem
+
+	// size is the number of items the map is allocated for, and peak the
+	// most it held since built, to shrink it once below shrinkRatio of it.
+	size        int
+	peak        int
+	shrinkRatio float64
+
+	// expiries orders the expiring items by expiration, so that expired ones
+	// are found without scanning all the items. Overwritten items leave
+	// stale entries, skipped once popped.
+	expiries expiryHeap
+
+	// expiration is the expiration of the items set with defaultExpiration.
+	expiration time.Duration
+	clock      Clock
+
+	// sliding resets the expiration of the items as they are read, and
+	// maxLifetime, unless zero, limits the time items live once set.
+	sliding     bool
+	maxLifetime time.Duration
+
+	// lazy removes the expired items as they are read.
+	lazy bool
+
+	// strings is set to intern the strings of the values, slab to copy the
+	// small byte slices into shared buffers.
+	strings interner
+	slab    *slab
+
+	// onEvicted is called with the items deleted or expired, not with the
+	// overwritten ones.
+	onEvicted func(key string, value interface{})
+}
+
+// newSegment returns an empty segment allocated for size items.
+func newSegment(size int, expiration time.Duration, clock Clock, onEvicted func(key string, value interface{})) *segment {
+	return &segment{
+		items:       make(map[string]segmentItem, size),
+		size:        size,
+		shrinkRatio: defaultShrinkRatio,
+		expiration:  expiration,
+		clock:       clock,
+		onEvicted:   onEvicted,
+	}
+}
+
+// expires returns the expiration, in nanoseconds, of an item written at the
+// given time and living ttl from now, zero if it does not expire.
+func (s *segment) expires(now, written int64, ttl time.Duration) int64 {
+	var expiration int64
+	if ttl > 0 {
+		expiration = now + int64(ttl)
+	}
+
+	if s.maxLifetime > 0 {
+		if limit := written + int64(s.maxLifetime); expiration == 0 || expiration > limit {
+			expiration = limit
+		}
+	}
+
+	return expiration
+}
+
+// newItem returns the item of a value set now with the given expiration.
+func (s *segment) newItem(value interface{}, expiration time.Duration) segmentItem {
+	if expiration == defaultExpiration {
+		expiration = s.expiration
+	}
+	if expiration < 0 {
+		expiration = 0
+	}
+
+	now := s.clock.Now().UnixNano()
+	return segmentItem{
+		object:     value,
+		expiration: s.expires(now, now, expiration),
+		written:    now,
+		ttl:        expiration,
+	}
+}
+
+// expiry is an entry of an expiryHeap.
+type expiry struct {
+	expiration int64
+	key        string
+}
+
+// expiryHeap is a min-heap of expirations, implementing heap.Interface.
+type expiryHeap []expiry
+
+func (h expiryHeap) Len() int            { return len(h) }
+func (h expiryHeap) Less(i, j int) bool  { return h[i].expiration < h[j].expiration }
+func (h expiryHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *expiryHeap) Push(x interface{}) { *h = append(*h, x.(expiry)) }
+
+func (h *expiryHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
+
+// store sets the item of the given key, with the lock held.
+func (s *segment) store(key string, item segmentItem) {
+	s.items[key] = item
+	if len(s.items) > s.peak {
+		s.peak = len(s.items)
+	}
+
+	if item.expiration > 0 {
+		heap.Push(&s.expiries, expiry{expiration: item.expiration, key: key})
+
+		// Drop the stale entries once they outnumber the items.
+		if len(s.expiries) > 2*len(s.items)+64 {
+			s.rebuildExpiries()
+		}
+	}
+}
+
+// rebuildExpiries rebuilds expiries from the items, with the lock held.
+func (s *segment) rebuildExpiries() {
+	expiries := make(expiryHeap, 0, len(s.items))
+	for key, item := range s.items {
+		if item.expiration > 0 {
+			expiries = append(expiries, expiry{expiration: item.expiration, key: key})
+		}
+	}
+	heap.Init(&expiries)
+	s.expiries = expiries
+}
+
+// set sets the value of the given key.
+func (s *segment) set(key string, value interface{}, expiration time.Duration) {
+	item := s.newItem(value, expiration)
+
+	s.mu.Lock()
+	item.object = s.compact(item.object)
+	s.store(key, item)
+	s.mu.Unlock()
+}
+
+// add sets the value of the given key unless it exists, and reports whether
+// it did.
+func (s *segment) add(key string, value interface{}, expiration time.Duration) bool {
+	item := s.newItem(value, expiration)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current, found := s.items[key]; found && !current.expired(item.written) {
+		return false
+	}
+
+	s.store(key, item)
+	return true
+}
+
+// touch sets the expiration of the given key, still limited by maxLifetime,
+// and reports whether it exists.
+func (s *segment) touch(key string, expiration time.Duration) bool {
+	if expiration < 0 {
+		expiration = 0
+	}
+
+	now := s.clock.Now().UnixNano()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, found := s.items[key]
+	if !found || item.expired(now) {
+		return false
+	}
+
+	item.ttl = expiration
+	item.expiration = s.expires(now, item.written, expiration)
+	s.store(key, item)
+	return true
+}
+
+// peek returns the unexpired item of the given key, without sliding its
+// expiration nor removing it once expired.
+func (s *segment) peek(key string) (segmentItem, bool) {
+	now := s.clock.Now().UnixNano()
+
+	s.mu.RLock()
+	item, found := s.items[key]
+	s.mu.RUnlock()
+
+	return item, found && !item.expired(now)
+}
+
+// lookup returns the unexpired item of the given key, sliding its expiration
+// when the segment does, and removing it once expired when lazy.
+func (s *segment) lookup(key string) (segmentItem, bool) {
+	now := s.clock.Now().UnixNano()
+
+	if !s.sliding {
+		s.mu.RLock()
+		item, found := s.items[key]
+		s.mu.RUnlock()
+
+		if found && item.expired(now) {
+			if s.lazy {
+				s.removeExpired(key, now)
+			}
+			return item, false
+		}
+		return item, found
+	}
+
+	s.mu.Lock()
+	item, found := s.items[key]
+	if found && item.expired(now) {
+		s.mu.Unlock()
+		if s.lazy {
+			s.removeExpired(key, now)
+		}
+		return item, false
+	}
+
+	if found && item.ttl > 0 {
+		// The entry of expiries is not updated: sweep pushes it back once
+		// popped.
+		item.expiration = s.expires(now, item.written, item.ttl)
+		s.items[key] = item
+	}
+	s.mu.Unlock()
+
+	return item, found
+}
+
+// removeExpired deletes the given key if it expired at now, reporting it to
+// onEvicted. Its entry of expiries is skipped once popped.
+func (s *segment) removeExpired(key string, now int64) {
+	s.mu.Lock()
+	item, found := s.items[key]
+	found = found && item.expired(now)
+	if found {
+		delete(s.items, key)
+		s.shrink()
+	}
+	s.mu.Unlock()
+
+	if found && s.onEvicted != nil {
+		s.onEvicted(key, item.object)
+	}
+}
+
+// get returns the value of the given key.
+func (s *segment) get(key string) (interface{}, bool) {
+	item, found := s.lookup(key)
+	if !found {
+		return nil, false
+	}
+	return item.object, true
+}
+
+// getWithExpiration returns the value of the given key and its expiration,
+// zero if it does not expire.
+func (s *segment) getWithExpiration(key string) (interface{}, time.Time, bool) {
+	item, found := s.lookup(key)
+	if !found {
+		return nil, time.Time{}, false
+	}
+
+	if item.expiration > 0 {
+		return item.object, time.Unix(0, item.expiration), true
+	}
+	return item.object, time.Time{}, true
+}
+
+// delete deletes the given key, reporting it to onEvicted.
+func (s *segment) delete(key string) {
+	s.mu.Lock()
+	item, found := s.items[key]
+	if found {
+		delete(s.items, key)
+		s.shrink()
+	}
+	s.mu.Unlock()
+
+	if found && s.onEvicted != nil {
+		s.onEvicted(key, item.object)
+	}
+}
+
+// deleteExpired deletes all the expired items, reporting them to onEvicted.
+func (s *segment) deleteExpired() {
+	now := s.clock.Now().UnixNano()
+	for s.sweep(now, defaultSweepBatch) {
+	}
+}
+
+// sweep deletes the items expired at now, in order of expiration, looking
+// at most at batch entries of expiries, and reports them to onEvicted. It
+// returns whether expired items may remain.
+func (s *segment) sweep(now int64, batch int) bool {
+	var evicted []segmentItem
+	var keys []string
+
+	s.mu.Lock()
+	n := 0
+	for ; n < batch && len(s.expiries) > 0 && s.expiries[0].expiration < now; n++ {
+		e := heap.Pop(&s.expiries).(expiry)
+
+		item, found := s.items[e.key]
+		if !found || item.expiration != e.expiration {
+			// A slid expiration has no entry of its own yet.
+			if found && s.sliding && item.expiration > e.expiration {
+				heap.Push(&s.expiries, expiry{expiration: item.expiration, key: e.key})
+			}
+			continue
+		}
+
+		delete(s.items, e.key)
+		if s.onEvicted != nil {
+			keys = append(keys, e.key)
+			evicted = append(evicted, item)
+		}
+	}
+	s.shrink()
+	s.mu.Unlock()
+
+	for i, key := range keys {
+		s.onEvicted(key, evicted[i].object)
+	}
+
+	return n == batch
+}
+
+// unexpired returns a copy of the unexpired items.
+func (s *segment) unexpired() map[string]segmentItem {
+	now := s.clock.Now().UnixNano()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	items := make(map[string]segmentItem, len(s.items))
+	s.copyUnexpired(now, items)
+	return items
+}
+
+// copyUnexpired adds the items unexpired at now to items. The caller holds
+// s.mu.
+func (s *segment) copyUnexpired(now int64, items map[string]segmentItem) {
+	for key, item := range s.items {
+		if !item.expired(now) {
+			items[key] = item
+		}
+	}
+}
+
+// count returns the number of items, expired ones included.
+func (s *segment) count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.items)
+}
+
+// flush deletes all the items, without reporting them.
+func (s *segment) flush() {
+	s.mu.Lock()
+	s.items = make(map[string]segmentItem, s.size)
+	s.peak = 0
+	s.expiries = nil
+	s.mu.Unlock()
+}
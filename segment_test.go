@@ -0,0 +1,92 @@
+package gokvstores
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegmentSweep(t *testing.T) {
+	is := assert.New(t)
+
+	clock := NewManualClock(time.Now())
+
+	var evicted []string
+	s := newSegment(0, NoExpiration, clock, func(key string, value interface{}) {
+		evicted = append(evicted, key)
+	})
+
+	for i := 0; i < 10; i++ {
+		s.set(strconv.Itoa(i), i, time.Duration(10-i)*time.Second)
+	}
+	s.set("forever", "value", NoExpiration)
+
+	// Overwritten items are not removed by their former expiration.
+	s.set("0", 0, time.Hour)
+
+	clock.Advance(5 * time.Second)
+	now := clock.Now().UnixNano()
+
+	is.True(s.sweep(now, 2))
+	is.Equal([]string{"9", "8"}, evicted)
+
+	is.False(s.sweep(now, 10))
+	is.Equal([]string{"9", "8", "7", "6"}, evicted)
+	is.Equal(7, s.count())
+
+	s.deleteExpired()
+	is.Equal(7, s.count())
+
+	clock.Advance(time.Minute)
+	s.deleteExpired()
+	is.Equal(2, s.count())
+
+	for i := 0; i < 1000; i++ {
+		s.set("key", i, time.Hour)
+	}
+	is.True(len(s.expiries) < 200)
+}
+
+func TestSegmentShrink(t *testing.T) {
+	is := assert.New(t)
+
+	clock := NewManualClock(time.Now())
+
+	s := newSegment(0, NoExpiration, clock, nil)
+
+	for i := 0; i < 4000; i++ {
+		s.set(strconv.Itoa(i), i, time.Duration(i+1)*time.Second)
+	}
+	is.Equal(4000, s.peak)
+
+	for i := 0; i < 2000; i++ {
+		s.delete(strconv.Itoa(i))
+	}
+	is.Equal(4000, s.peak)
+
+	// Expired items shrink the map as well, along with the expirations.
+	clock.Advance(3002 * time.Second)
+	s.deleteExpired()
+	is.Equal(999, s.count())
+	is.Equal(999, s.peak)
+	is.Equal(999, len(s.expiries))
+
+	for i := 3001; i < 4000; i++ {
+		value, found := s.get(strconv.Itoa(i))
+		is.True(found)
+		is.Equal(i, value)
+	}
+
+	s = newSegment(0, NoExpiration, clock, nil)
+	s.shrinkRatio = -1
+
+	for i := 0; i < 2000; i++ {
+		s.set(strconv.Itoa(i), i, NoExpiration)
+	}
+	for i := 0; i < 2000; i++ {
+		s.delete(strconv.Itoa(i))
+	}
+	is.Equal(2000, s.peak)
+}
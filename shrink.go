@@ -0,0 +1,55 @@
+package gokvstores
+
+import "container/list"
+
+// defaultShrinkRatio is the default MemoryStoreOptions.ShrinkRatio.
+const defaultShrinkRatio = 0.25
+
+// minShrinkSize is the fewest entries a map must have held to be rebuilt, as
+// smaller maps are not worth it.
+const minShrinkSize = 1024
+
+// shrinkable reports whether a map holding count entries, after holding peak
+// since it was built, must be rebuilt to free the memory of the others: Go
+// maps never give back the memory of their deleted entries.
+func shrinkable(count, peak int, ratio float64) bool {
+	return ratio > 0 && peak >= minShrinkSize && float64(count) < ratio*float64(peak)
+}
+
+// shrink rebuilds the items once most of them were removed, with the lock
+// held. The map is not made smaller than allocated upfront.
+func (s *segment) shrink() {
+	if s.peak <= s.size || !shrinkable(len(s.items), s.peak, s.shrinkRatio) {
+		return
+	}
+
+	size := len(s.items)
+	if size < s.size {
+		size = s.size
+	}
+
+	items := make(map[string]segmentItem, size)
+	for key, item := range s.items {
+		items[key] = item
+	}
+
+	s.items = items
+	s.peak = len(items)
+	s.rebuildExpiries()
+}
+
+// shrink rebuilds the elements once most of them were removed, with the lock
+// held.
+func (l *lruIndex) shrink() {
+	if !shrinkable(len(l.elements), l.peak, l.shrinkRatio) {
+		return
+	}
+
+	elements := make(map[string]*list.Element, len(l.elements))
+	for key, e := range l.elements {
+		elements[key] = e
+	}
+
+	l.elements = elements
+	l.peak = len(elements)
+}
@@ -0,0 +1,49 @@
+package gokvstores
+
+import "golang.org/x/sync/singleflight"
+
+// SingleflightStore is a KVStore decorator collapsing concurrent lookups of
+// the same key into a single backend request.
+//
+// Concurrent callers receive the same map or slice instance and must not modify it.
+type SingleflightStore struct {
+	KVStore
+
+	group singleflight.Group
+}
+
+// NewSingleflightStore returns a SingleflightStore wrapping the given store.
+func NewSingleflightStore(store KVStore) *SingleflightStore {
+	return &SingleflightStore{KVStore: store}
+}
+
+// Get returns value for the given key.
+func (s *SingleflightStore) Get(key string) (interface{}, error) {
+	value, err, _ := s.group.Do("Get:"+key, func() (interface{}, error) {
+		return s.KVStore.Get(key)
+	})
+
+	return value, err
+}
+
+// GetMap returns map for the given key.
+func (s *SingleflightStore) GetMap(key string) (map[string]interface{}, error) {
+	value, err, _ := s.group.Do("GetMap:"+key, func() (interface{}, error) {
+		return s.KVStore.GetMap(key)
+	})
+
+	m, _ := value.(map[string]interface{})
+
+	return m, err
+}
+
+// GetSlice returns slice for the given key.
+func (s *SingleflightStore) GetSlice(key string) ([]interface{}, error) {
+	value, err, _ := s.group.Do("GetSlice:"+key, func() (interface{}, error) {
+		return s.KVStore.GetSlice(key)
+	})
+
+	slice, _ := value.([]interface{})
+
+	return slice, err
+}
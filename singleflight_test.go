@@ -0,0 +1,51 @@
+package gokvstores
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// slowStore is a KVStore whose Get takes delay and counts its calls.
+type slowStore struct {
+	KVStore
+	delay time.Duration
+	calls int32
+}
+
+func (s *slowStore) Get(key string) (interface{}, error) {
+	atomic.AddInt32(&s.calls, 1)
+	time.Sleep(s.delay)
+	return s.KVStore.Get(key)
+}
+
+func TestSingleflightStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	testStore(t, NewSingleflightStore(memory))
+
+	is.Nil(memory.Set("key", "value"))
+
+	slow := &slowStore{KVStore: memory, delay: 50 * time.Millisecond}
+	store := NewSingleflightStore(slow)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := store.Get("key")
+			is.Nil(err)
+			is.Equal("value", v)
+		}()
+	}
+	wg.Wait()
+
+	is.Equal(int32(1), atomic.LoadInt32(&slow.calls))
+}
@@ -0,0 +1,139 @@
+package gokvstores
+
+import (
+	"time"
+
+	conv "github.com/cstockton/go-conv"
+)
+
+// OversizeAction is what a SizeGuardStore does with oversized values.
+type OversizeAction int
+
+// Oversize actions.
+const (
+	// RejectOversize fails the write with a ValueTooLargeError.
+	RejectOversize OversizeAction = iota
+	// TruncateOversize truncates scalar values to the maximum size.
+	// Oversized maps and slices are rejected.
+	TruncateOversize
+	// AllowOversize writes the value anyway, OnOversize being the only notice.
+	AllowOversize
+)
+
+// SizeGuardOptions are SizeGuardStore options.
+type SizeGuardOptions struct {
+	// MaxSize is the maximum size in bytes of a written value, once
+	// stringified. Zero means no limit.
+	MaxSize int
+
+	// Action is applied to oversized values. Defaults to RejectOversize.
+	Action OversizeAction
+
+	// OnOversize is called for each oversized value, whatever the action.
+	OnOversize func(key string, size int)
+}
+
+// SizeGuardStore is a KVStore decorator protecting the wrapped store from
+// oversized values. Appended slice values are checked on their own.
+type SizeGuardStore struct {
+	KVStore
+
+	options SizeGuardOptions
+	stats   statsCounter
+}
+
+// NewSizeGuardStore returns a SizeGuardStore wrapping the given store.
+func NewSizeGuardStore(store KVStore, options *SizeGuardOptions) *SizeGuardStore {
+	if options == nil {
+		options = &SizeGuardOptions{}
+	}
+
+	return &SizeGuardStore{KVStore: store, options: *options}
+}
+
+// check returns the value to write, or an error if it must be rejected.
+func (s *SizeGuardStore) check(key string, value interface{}, truncatable bool) (interface{}, error) {
+	size := valueSize(value)
+	if s.options.MaxSize <= 0 || size <= s.options.MaxSize {
+		return value, nil
+	}
+
+	if s.options.OnOversize != nil {
+		s.options.OnOversize(key, size)
+	}
+
+	switch {
+	case s.options.Action == AllowOversize:
+		return value, nil
+	case s.options.Action == TruncateOversize && truncatable:
+		return conv.String(value)[:s.options.MaxSize], nil
+	}
+
+	s.stats.reject()
+
+	return nil, &ValueTooLargeError{Key: key, Size: size, MaxSize: s.options.MaxSize}
+}
+
+// Stats returns the counters of the wrapped store, if it maintains them, with
+// the writes rejected as oversized.
+func (s *SizeGuardStore) Stats() (Stats, error) {
+	var stats Stats
+	if provider, ok := s.KVStore.(StatsProvider); ok {
+		var err error
+		if stats, err = provider.Stats(); err != nil {
+			return Stats{}, err
+		}
+	}
+
+	stats.Rejected += s.stats.stats(0).Rejected
+
+	return stats, nil
+}
+
+// Set sets value for the given key.
+func (s *SizeGuardStore) Set(key string, value interface{}) error {
+	value, err := s.check(key, value, true)
+	if err != nil {
+		return err
+	}
+
+	return s.KVStore.Set(key, value)
+}
+
+// SetWithExpiration sets value for the given key with a specific expiration.
+func (s *SizeGuardStore) SetWithExpiration(key string, value interface{}, expiration time.Duration) error {
+	value, err := s.check(key, value, true)
+	if err != nil {
+		return err
+	}
+
+	return s.KVStore.SetWithExpiration(key, value, expiration)
+}
+
+// SetMap sets map for the given key.
+func (s *SizeGuardStore) SetMap(key string, value map[string]interface{}) error {
+	if _, err := s.check(key, value, false); err != nil {
+		return err
+	}
+
+	return s.KVStore.SetMap(key, value)
+}
+
+// SetSlice sets slice for the given key.
+func (s *SizeGuardStore) SetSlice(key string, value []interface{}) error {
+	if _, err := s.check(key, value, false); err != nil {
+		return err
+	}
+
+	return s.KVStore.SetSlice(key, value)
+}
+
+// AppendSlice appends values to an existing slice.
+// If key does not exist, creates slice.
+func (s *SizeGuardStore) AppendSlice(key string, values ...interface{}) error {
+	if _, err := s.check(key, values, false); err != nil {
+		return err
+	}
+
+	return s.KVStore.AppendSlice(key, values...)
+}
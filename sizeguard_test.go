@@ -0,0 +1,64 @@
+package gokvstores
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSizeGuardStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	oversized := []string{}
+
+	store := NewSizeGuardStore(memory, &SizeGuardOptions{
+		MaxSize: 32,
+		OnOversize: func(key string, size int) {
+			oversized = append(oversized, key)
+		},
+	})
+
+	testStore(t, store)
+
+	large := strings.Repeat("x", 64)
+
+	err = store.Set("key", large)
+	is.Equal(&ValueTooLargeError{Key: "key", Size: 64, MaxSize: 32}, err)
+
+	err = store.SetMap("map", map[string]interface{}{"field": large})
+	is.NotNil(err)
+
+	is.Equal([]string{"key", "map"}, oversized)
+
+	stats, err := store.Stats()
+	is.Nil(err)
+	is.Equal(uint64(2), stats.Rejected)
+
+	exists, err := memory.Exists("key")
+	is.Nil(err)
+	is.False(exists)
+
+	// Truncation
+
+	store = NewSizeGuardStore(memory, &SizeGuardOptions{
+		MaxSize: 32,
+		Action:  TruncateOversize,
+	})
+
+	is.Nil(store.Set("key", large))
+
+	v, err := store.Get("key")
+	is.Nil(err)
+	is.Equal(large[:32], v)
+
+	is.NotNil(store.SetSlice("slice", []interface{}{large}))
+
+	// Without options, values are not limited.
+	unlimited := NewSizeGuardStore(memory, nil)
+	is.Nil(unlimited.Set("key", large))
+}
@@ -0,0 +1,46 @@
+package gokvstores
+
+import (
+	"log"
+	"time"
+)
+
+// SlowOperationHook is called for each operation slower than the threshold
+// of a SlowLogStore. key is empty for store-wide operations.
+type SlowOperationHook func(operation, key string, duration time.Duration)
+
+// SlowLogStore is a KVStore decorator calling a hook for slow operations.
+type SlowLogStore struct {
+	*interceptedStore
+
+	threshold time.Duration
+	hook      SlowOperationHook
+}
+
+// NewSlowLogStore returns a SlowLogStore wrapping the given store, calling hook
+// for every operation taking threshold or longer. A nil hook logs the
+// operation with the standard logger.
+func NewSlowLogStore(store KVStore, threshold time.Duration, hook SlowOperationHook) *SlowLogStore {
+	if hook == nil {
+		hook = func(operation, key string, duration time.Duration) {
+			log.Printf("slow kvstore operation: %s %q took %s", operation, key, duration)
+		}
+	}
+
+	s := &SlowLogStore{threshold: threshold, hook: hook}
+	s.interceptedStore = &interceptedStore{store: store, intercept: s.measure}
+
+	return s
+}
+
+// measure times a single operation.
+func (s *SlowLogStore) measure(op *operation, next func() error) error {
+	start := time.Now()
+	err := next()
+
+	if duration := time.Since(start); duration >= s.threshold {
+		s.hook(op.name, op.key, duration)
+	}
+
+	return err
+}
@@ -0,0 +1,38 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlowLogStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	testStore(t, NewSlowLogStore(memory, time.Second, nil))
+
+	type call struct {
+		operation, key string
+		duration       time.Duration
+	}
+
+	calls := []call{}
+
+	slow := &slowStore{KVStore: memory, delay: 20 * time.Millisecond}
+	store := NewSlowLogStore(slow, 10*time.Millisecond, func(operation, key string, duration time.Duration) {
+		calls = append(calls, call{operation, key, duration})
+	})
+
+	is.Nil(store.Set("key", "value"))
+	_, err = store.Get("key")
+	is.Nil(err)
+
+	is.Len(calls, 1)
+	is.Equal("Get", calls[0].operation)
+	is.Equal("key", calls[0].key)
+	is.True(calls[0].duration >= 20*time.Millisecond)
+}
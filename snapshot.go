@@ -0,0 +1,134 @@
+package gokvstores
+
+import (
+	"sort"
+	"time"
+)
+
+// Snapshotter is implemented by stores able to take consistent snapshots.
+type Snapshotter interface {
+	// Snapshot returns a read-only view of the store at the current time.
+	Snapshot() (*Snapshot, error)
+}
+
+// snapshotItem is a value held by a Snapshot.
+type snapshotItem struct {
+	value      interface{}
+	expiration time.Time
+}
+
+// Snapshot is a read-only, point-in-time view of a store. It implements
+// KVStore: writes fail with ErrReadOnly and live writes to the store it was
+// taken from are not visible.
+type Snapshot struct {
+	time  time.Time
+	items map[string]snapshotItem
+}
+
+// Time returns the time the snapshot was taken at.
+func (s *Snapshot) Time() time.Time {
+	return s.time
+}
+
+// Len returns the number of keys in the snapshot.
+func (s *Snapshot) Len() int {
+	return len(s.items)
+}
+
+// Keys returns the sorted keys of the snapshot.
+func (s *Snapshot) Keys() []string {
+	keys := make([]string, 0, len(s.items))
+	for key := range s.items {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Expiration returns the expiration time of the given key, zero if it never expires.
+func (s *Snapshot) Expiration(key string) time.Time {
+	return s.items[key].expiration
+}
+
+// Scan calls fn with each item of the snapshot, in key order.
+func (s *Snapshot) Scan(fn func(item Item) error) error {
+	for _, key := range s.Keys() {
+		item := s.items[key]
+		if err := fn(Item{Key: key, Value: item.value, Expiration: item.expiration}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get returns value for the given key.
+func (s *Snapshot) Get(key string) (interface{}, error) {
+	return s.items[key].value, nil
+}
+
+// Set fails with ErrReadOnly.
+func (s *Snapshot) Set(key string, value interface{}) error {
+	return ErrReadOnly
+}
+
+// SetWithExpiration fails with ErrReadOnly.
+func (s *Snapshot) SetWithExpiration(key string, value interface{}, expiration time.Duration) error {
+	return ErrReadOnly
+}
+
+// GetMap returns map for the given key.
+func (s *Snapshot) GetMap(key string) (map[string]interface{}, error) {
+	value, _ := s.items[key].value.(map[string]interface{})
+	return value, nil
+}
+
+// SetMap fails with ErrReadOnly.
+func (s *Snapshot) SetMap(key string, value map[string]interface{}) error {
+	return ErrReadOnly
+}
+
+// GetSlice returns slice for the given key.
+func (s *Snapshot) GetSlice(key string) ([]interface{}, error) {
+	value, _ := s.items[key].value.([]interface{})
+	return value, nil
+}
+
+// SetSlice fails with ErrReadOnly.
+func (s *Snapshot) SetSlice(key string, value []interface{}) error {
+	return ErrReadOnly
+}
+
+// AppendSlice fails with ErrReadOnly.
+func (s *Snapshot) AppendSlice(key string, values ...interface{}) error {
+	return ErrReadOnly
+}
+
+// Exists checks if the given key exists.
+func (s *Snapshot) Exists(key string) (bool, error) {
+	_, exists := s.items[key]
+	return exists, nil
+}
+
+// Delete fails with ErrReadOnly.
+func (s *Snapshot) Delete(key string) error {
+	return ErrReadOnly
+}
+
+// Flush fails with ErrReadOnly.
+func (s *Snapshot) Flush() error {
+	return ErrReadOnly
+}
+
+// Close releases the snapshot.
+func (s *Snapshot) Close() error {
+	s.items = nil
+	return nil
+}
+
+// Ping does nothing for a snapshot.
+func (s *Snapshot) Ping() error {
+	return nil
+}
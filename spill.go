@@ -0,0 +1,266 @@
+package gokvstores
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// evictionSpilled is an item moved from memory to the spill directory, which
+// is not reported since it still exists.
+const evictionSpilled EvictionReason = -1
+
+// spillStore keeps the items of a MemoryStore moved out of memory, one gob
+// encoded Item per file.
+type spillStore struct {
+	dir   string
+	clock Clock
+
+	mu sync.Mutex
+	// keys maps the spilled keys to their expiration.
+	keys map[string]time.Time
+}
+
+// newSpillStore returns a spillStore writing to dir, removing the files left
+// by a previous store.
+func newSpillStore(dir string, clock Clock) (*spillStore, error) {
+	s := &spillStore{dir: dir, clock: clock, keys: map[string]time.Time{}}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	if err := s.clear(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// spillable reports whether the value can be moved to disk.
+func spillable(value interface{}) bool {
+	if _, ok := value.(*fieldMap); ok {
+		return false
+	}
+	return !isInternalValue(value)
+}
+
+// path returns the file holding the given key.
+func (s *spillStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:]))
+}
+
+// save writes the item to disk.
+func (s *spillStore) save(item Item) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.path(item.Key)
+
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return err
+	}
+
+	file := &dirBackupFile{File: f, path: path}
+	if err := gob.NewEncoder(f).Encode(item); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	s.keys[item.Key] = item.Expiration
+	return nil
+}
+
+// load reads the item of the given key, removing it once expired.
+func (s *spillStore) load(key string) (Item, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.read(key)
+}
+
+// read reads the item of the given key, with the lock held.
+func (s *spillStore) read(key string) (Item, bool) {
+	expiration, found := s.keys[key]
+	if !found {
+		return Item{}, false
+	}
+
+	if !expiration.IsZero() && s.clock.Now().After(expiration) {
+		s.delete(key)
+		return Item{}, false
+	}
+
+	f, err := os.Open(s.path(key))
+	if err != nil {
+		s.delete(key)
+		return Item{}, false
+	}
+	defer f.Close()
+
+	var item Item
+	if err := gob.NewDecoder(f).Decode(&item); err != nil {
+		s.delete(key)
+		return Item{}, false
+	}
+
+	return item, true
+}
+
+// remove deletes the item of the given key.
+func (s *spillStore) remove(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.keys[key]; found {
+		s.delete(key)
+	}
+}
+
+// delete deletes the item of the given key, with the lock held.
+func (s *spillStore) delete(key string) {
+	delete(s.keys, key)
+	os.Remove(s.path(key))
+}
+
+// count returns the number of spilled items, expired ones included.
+func (s *spillStore) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.keys)
+}
+
+// unexpired calls fn, unless nil, with the key of each unexpired spilled
+// item, and returns their number.
+func (s *spillStore) unexpired(fn func(key string)) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	count := 0
+	for key, expiration := range s.keys {
+		if !expiration.IsZero() && now.After(expiration) {
+			continue
+		}
+		if fn != nil {
+			fn(key)
+		}
+		count++
+	}
+
+	return count
+}
+
+// scan calls fn with each unexpired spilled item whose key skip does not
+// report.
+func (s *spillStore) scan(skip func(key string) bool, fn func(item Item) error) error {
+	s.mu.Lock()
+	keys := make([]string, 0, len(s.keys))
+	for key := range s.keys {
+		keys = append(keys, key)
+	}
+	s.mu.Unlock()
+
+	for _, key := range keys {
+		if skip(key) {
+			continue
+		}
+
+		item, found := s.load(key)
+		if !found {
+			continue
+		}
+
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// clear removes all the spilled items.
+func (s *spillStore) clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			os.Remove(filepath.Join(s.dir, entry.Name()))
+		}
+	}
+
+	s.keys = map[string]time.Time{}
+	return nil
+}
+
+// spills reports whether the value is large enough to be stored on disk.
+func (c *MemoryStore) spills(value interface{}) bool {
+	return c.spill != nil && c.spillThreshold > 0 && spillable(value) &&
+		valueSize(value) > c.spillThreshold
+}
+
+// spillOut moves the given key to disk, evicting it when it cannot be.
+func (c *MemoryStore) spillOut(key string) {
+	value, expiration, found := c.shard(key).getWithExpiration(key)
+	if found && spillable(value) {
+		if err := c.spill.save(Item{Key: key, Value: value, Expiration: expiration}); err == nil {
+			c.remove(key, evictionSpilled)
+			return
+		}
+	}
+
+	c.remove(key, EvictionEvicted)
+}
+
+// spillIn returns the spilled value of the given key and its expiration,
+// moving it back to memory unless it is large.
+func (c *MemoryStore) spillIn(key string) (interface{}, time.Time, bool) {
+	item, found := c.spill.load(key)
+	if !found {
+		return nil, time.Time{}, false
+	}
+
+	if c.spills(item.Value) {
+		return item.Value, item.Expiration, true
+	}
+
+	// An item read at its deadline has no time left, and put would keep it
+	// forever.
+	ttl := c.remainingTTL(item.Expiration)
+	c.spill.remove(key)
+	if !item.Expiration.IsZero() && ttl <= 0 {
+		return nil, time.Time{}, false
+	}
+
+	c.put(key, item.Value, ttl)
+	return item.Value, item.Expiration, true
+}
+
+// expirationTime returns the time an item set with the given segment
+// expiration expires at, zero if it does not.
+func (c *MemoryStore) expirationTime(expiration time.Duration) time.Time {
+	if expiration == defaultExpiration {
+		expiration = c.expiration
+	}
+	if expiration <= 0 {
+		return time.Time{}
+	}
+	return c.clock.Now().Add(expiration)
+}
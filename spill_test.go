@@ -0,0 +1,122 @@
+package gokvstores
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreSpillDir(t *testing.T) {
+	is := assert.New(t)
+
+	dir := t.TempDir()
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{
+		MaxEntries:     2,
+		SpillDir:       dir,
+		SpillThreshold: 100,
+	})
+	is.Nil(err)
+
+	memory := store.(*MemoryStore)
+
+	var evicted []string
+	memory.OnEvicted(func(key string, value interface{}, reason EvictionReason) {
+		evicted = append(evicted, key)
+	})
+
+	large := strings.Repeat("x", 200)
+	is.Nil(store.SetWithExpiration("large", large, time.Minute))
+
+	_, found := memory.shard("large").get("large")
+	is.False(found)
+
+	entries, err := os.ReadDir(dir)
+	is.Nil(err)
+	is.Len(entries, 1)
+
+	value, err := store.Get("large")
+	is.Nil(err)
+	is.Equal(large, value)
+
+	// Cold items are spilled, then moved back to memory once read.
+	for i := 0; i < 4; i++ {
+		is.Nil(store.Set("key"+strconv.Itoa(i), i))
+	}
+	is.Empty(evicted)
+	is.Equal(3, memory.spill.count())
+
+	for i := 0; i < 4; i++ {
+		value, err := store.Get("key" + strconv.Itoa(i))
+		is.Nil(err)
+		is.Equal(i, value)
+	}
+
+	stats, err := store.(StatsProvider).Stats()
+	is.Nil(err)
+	is.Equal(int64(5), stats.Keys)
+
+	keys := map[string]time.Time{}
+	is.Nil(memory.Scan(func(item Item) error {
+		keys[item.Key] = item.Expiration
+		return nil
+	}))
+	is.Len(keys, 5)
+	is.WithinDuration(time.Now().Add(time.Minute), keys["large"], time.Second)
+
+	is.Nil(memory.Expire("large", time.Hour))
+	is.Nil(memory.Scan(func(item Item) error {
+		if item.Key == "large" {
+			is.WithinDuration(time.Now().Add(time.Hour), item.Expiration, time.Second)
+		}
+		return nil
+	}))
+
+	is.Nil(store.Delete("large"))
+	exists, err := store.Exists("large")
+	is.Nil(err)
+	is.False(exists)
+
+	is.Nil(store.Flush())
+	entries, err = os.ReadDir(dir)
+	is.Nil(err)
+	is.Empty(entries)
+}
+
+func TestMemoryStoreSpillDeadline(t *testing.T) {
+	is := assert.New(t)
+
+	clock := NewManualClock(time.Now())
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{
+		MaxEntries:     1,
+		SpillDir:       t.TempDir(),
+		SpillThreshold: 100,
+		Clock:          clock,
+	})
+	is.Nil(err)
+
+	memory := store.(*MemoryStore)
+
+	is.Nil(store.SetWithExpiration("cold", "value", time.Minute))
+	is.Nil(store.Set("hot", "value"))
+	is.Equal(1, memory.spill.count())
+
+	// Read exactly at the deadline, the item must not come back permanent.
+	clock.Advance(time.Minute)
+
+	value, err := store.Get("cold")
+	is.Nil(err)
+	is.Nil(value)
+	is.Equal(0, memory.spill.count())
+
+	clock.Advance(time.Hour)
+
+	exists, err := store.Exists("cold")
+	is.Nil(err)
+	is.False(exists)
+}
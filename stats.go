@@ -0,0 +1,110 @@
+package gokvstores
+
+import "sync/atomic"
+
+// Stats are the counters maintained by a store since it was created.
+type Stats struct {
+	// Operations is the number of operations, Ping and Close excluded.
+	Operations uint64 `json:"operations"`
+
+	// Hits and Misses are the number of lookups which found, or did not find, the key.
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+
+	// Errors is the number of operations which returned an error.
+	Errors uint64 `json:"errors"`
+
+	// Keys is the number of keys currently held by the store.
+	Keys int64 `json:"keys"`
+
+	// Evictions is the number of keys removed to free space, and Expirations
+	// the number of keys removed once expired.
+	Evictions   uint64 `json:"evictions"`
+	Expirations uint64 `json:"expirations"`
+
+	// Rejected is the number of writes refused because of a size limit.
+	Rejected uint64 `json:"rejected"`
+}
+
+// StatsProvider is implemented by stores maintaining Stats.
+type StatsProvider interface {
+	// Stats returns the current counters of the store.
+	Stats() (Stats, error)
+}
+
+// MemoryReporter is implemented by stores able to estimate their memory usage.
+type MemoryReporter interface {
+	// MemoryUsage returns the approximate number of bytes used by the store data.
+	MemoryUsage() (int64, error)
+}
+
+// KeyMemoryReporter is implemented by stores able to estimate the memory used
+// by a single key.
+type KeyMemoryReporter interface {
+	// KeyMemoryUsage returns the approximate number of bytes used by the key
+	// and its value, 0 if the key does not exist.
+	KeyMemoryUsage(key string) (int64, error)
+}
+
+// statsCounter maintains the counters of a store. It is safe for concurrent use.
+type statsCounter struct {
+	operations uint64
+	hits       uint64
+	misses     uint64
+	errors     uint64
+
+	evictions   uint64
+	expirations uint64
+	rejected    uint64
+}
+
+// write records a write operation.
+func (c *statsCounter) write(err error) {
+	atomic.AddUint64(&c.operations, 1)
+	if err != nil {
+		atomic.AddUint64(&c.errors, 1)
+	}
+}
+
+// read records a lookup.
+func (c *statsCounter) read(hit bool, err error) {
+	c.write(err)
+
+	switch {
+	case err != nil:
+	case hit:
+		atomic.AddUint64(&c.hits, 1)
+	default:
+		atomic.AddUint64(&c.misses, 1)
+	}
+}
+
+// removed records a key removed by the store itself.
+func (c *statsCounter) removed(reason EvictionReason) {
+	switch reason {
+	case EvictionEvicted:
+		atomic.AddUint64(&c.evictions, 1)
+	case EvictionExpired:
+		atomic.AddUint64(&c.expirations, 1)
+	}
+}
+
+// reject records a write refused because of a size limit.
+func (c *statsCounter) reject() {
+	atomic.AddUint64(&c.rejected, 1)
+}
+
+// stats returns a copy of the counters, with the given number of keys.
+func (c *statsCounter) stats(keys int64) Stats {
+	return Stats{
+		Operations: atomic.LoadUint64(&c.operations),
+		Hits:       atomic.LoadUint64(&c.hits),
+		Misses:     atomic.LoadUint64(&c.misses),
+		Errors:     atomic.LoadUint64(&c.errors),
+		Keys:       keys,
+
+		Evictions:   atomic.LoadUint64(&c.evictions),
+		Expirations: atomic.LoadUint64(&c.expirations),
+		Rejected:    atomic.LoadUint64(&c.rejected),
+	}
+}
@@ -0,0 +1,179 @@
+package gokvstores
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// StreamMessage is an entry of a stream.
+type StreamMessage struct {
+	Stream string
+	ID     string
+	Values map[string]interface{}
+}
+
+// StreamReadArgs are the arguments of Streams reads.
+type StreamReadArgs struct {
+	// Streams maps the streams to read to the ID entries are read after.
+	// With XReadGroup, ">" reads the entries never delivered to the group.
+	Streams map[string]string
+
+	// Count is the maximum number of entries read per stream, all when zero.
+	Count int64
+
+	// Block is how long to wait for entries when there are none. Reads don't
+	// block when zero.
+	Block time.Duration
+}
+
+// Streams is implemented by stores supporting append-only streams.
+type Streams interface {
+	// XAdd appends an entry to the stream and returns its ID.
+	XAdd(stream string, values map[string]interface{}) (string, error)
+
+	// XRead returns the entries of the streams following the given IDs.
+	XRead(args StreamReadArgs) ([]StreamMessage, error)
+
+	// XGroupCreate creates a consumer group delivering the entries following
+	// the given ID, "$" for new entries only. The stream is created if needed.
+	XGroupCreate(stream, group, start string) error
+
+	// XReadGroup returns entries of the streams as the consumer of the group.
+	XReadGroup(group, consumer string, args StreamReadArgs) ([]StreamMessage, error)
+
+	// XAck acknowledges entries delivered to the group.
+	XAck(stream, group string, ids ...string) error
+}
+
+// XAdd appends an entry to the stream and returns its ID. Values are
+// serialized with the store codec.
+func (r *RedisStore) XAdd(stream string, values map[string]interface{}) (string, error) {
+	fields, err := r.encodeMap(values)
+	if err != nil {
+		return "", err
+	}
+
+	args := []interface{}{"xadd", stream, "*"}
+	for field, value := range fields {
+		args = append(args, field, value)
+	}
+
+	cmd := redis.NewStringCmd(r.ctx, args...)
+	if err := r.client.Process(r.ctx, cmd); err != nil {
+		return "", err
+	}
+
+	return cmd.Val(), nil
+}
+
+// XRead returns the entries of the streams following the given IDs, or nil
+// when there are none.
+func (r *RedisStore) XRead(args StreamReadArgs) ([]StreamMessage, error) {
+	return r.readStreams([]interface{}{"xread"}, args)
+}
+
+// XGroupCreate creates a consumer group delivering the entries following the
+// given ID. The stream is created if needed.
+func (r *RedisStore) XGroupCreate(stream, group, start string) error {
+	return r.client.Process(r.ctx, redis.NewStatusCmd(r.ctx, "xgroup", "create", stream, group, start, "mkstream"))
+}
+
+// XReadGroup returns entries of the streams as the consumer of the group, or
+// nil when there are none.
+func (r *RedisStore) XReadGroup(group, consumer string, args StreamReadArgs) ([]StreamMessage, error) {
+	return r.readStreams([]interface{}{"xreadgroup", "group", group, consumer}, args)
+}
+
+// XAck acknowledges entries delivered to the group.
+func (r *RedisStore) XAck(stream, group string, ids ...string) error {
+	args := []interface{}{"xack", stream, group}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	return r.client.Process(r.ctx, redis.NewIntCmd(r.ctx, args...))
+}
+
+// readStreams sends the XREAD or XREADGROUP command and parses its reply.
+func (r *RedisStore) readStreams(args []interface{}, opts StreamReadArgs) ([]StreamMessage, error) {
+	if opts.Count > 0 {
+		args = append(args, "count", opts.Count)
+	}
+	if opts.Block > 0 {
+		args = append(args, "block", int64(opts.Block/time.Millisecond))
+	}
+
+	streams := make([]string, 0, len(opts.Streams))
+	for stream := range opts.Streams {
+		streams = append(streams, stream)
+	}
+	sort.Strings(streams)
+
+	args = append(args, "streams")
+	for _, stream := range streams {
+		args = append(args, stream)
+	}
+	for _, stream := range streams {
+		args = append(args, opts.Streams[stream])
+	}
+
+	cmd := redis.NewCmd(r.ctx, args...)
+	if err := r.client.Process(r.ctx, cmd); err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return r.parseStreams(cmd.Val())
+}
+
+// parseStreams returns the messages of an XREAD reply, an array of
+// [stream, [[id, [field, value, ...]], ...]] elements.
+func (r *RedisStore) parseStreams(reply interface{}) ([]StreamMessage, error) {
+	streams, ok := reply.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("gokvstores: unexpected stream reply %T", reply)
+	}
+
+	var messages []StreamMessage
+	for _, s := range streams {
+		stream, ok := s.([]interface{})
+		if !ok || len(stream) != 2 {
+			return nil, fmt.Errorf("gokvstores: unexpected stream reply %v", s)
+		}
+
+		name, _ := stream[0].(string)
+		entries, _ := stream[1].([]interface{})
+
+		for _, e := range entries {
+			entry, ok := e.([]interface{})
+			if !ok || len(entry) != 2 {
+				return nil, fmt.Errorf("gokvstores: unexpected stream entry %v", e)
+			}
+
+			message := StreamMessage{Stream: name, Values: map[string]interface{}{}}
+			message.ID, _ = entry[0].(string)
+
+			// Entries deleted since their delivery have no fields.
+			fields, _ := entry[1].([]interface{})
+			for i := 0; i+1 < len(fields); i += 2 {
+				field, _ := fields[i].(string)
+				data, _ := fields[i+1].(string)
+
+				value, err := r.decode(data)
+				if err != nil {
+					return nil, err
+				}
+				message.Values[field] = value
+			}
+
+			messages = append(messages, message)
+		}
+	}
+
+	return messages, nil
+}
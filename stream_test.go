@@ -0,0 +1,32 @@
+package gokvstores
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStreams(t *testing.T) {
+	is := assert.New(t)
+
+	store := newRedisStore(nil, 0, nil)
+
+	messages, err := store.parseStreams([]interface{}{
+		[]interface{}{"events", []interface{}{
+			[]interface{}{"1-0", []interface{}{"type", "created", "id", "42"}},
+			[]interface{}{"2-0", nil},
+		}},
+		[]interface{}{"other", []interface{}{}},
+	})
+	is.Nil(err)
+	is.Equal([]StreamMessage{
+		{Stream: "events", ID: "1-0", Values: map[string]interface{}{"type": "created", "id": "42"}},
+		{Stream: "events", ID: "2-0", Values: map[string]interface{}{}},
+	}, messages)
+
+	_, err = store.parseStreams("OK")
+	is.NotNil(err)
+
+	_, err = store.parseStreams([]interface{}{[]interface{}{"events"}})
+	is.NotNil(err)
+}
@@ -0,0 +1,142 @@
+package gokvstores
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RangeStore is implemented by stores able to read and write part of string
+// values, without transferring the whole value. Offsets are in bytes, of
+// the value as stored by the codec.
+type RangeStore interface {
+	// GetRange returns the bytes of the value between start and end, both
+	// included. Negative offsets count from the end of the value, -1 being
+	// the last byte. It returns an empty string if the key does not exist.
+	GetRange(key string, start, end int64) (string, error)
+
+	// SetRange overwrites the value from offset with the given bytes,
+	// padding it with zero bytes if it is shorter than offset, and returns
+	// its new length. The expiration of the key is kept.
+	SetRange(key string, offset int64, value string) (int64, error)
+}
+
+// stringRange returns the bytes of s between start and end, both included,
+// as GETRANGE does.
+func stringRange(s string, start, end int64) string {
+	length := int64(len(s))
+
+	if start < 0 {
+		start += length
+	}
+	if end < 0 {
+		end += length
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end >= length {
+		end = length - 1
+	}
+
+	if start > end || length == 0 {
+		return ""
+	}
+
+	return s[start : end+1]
+}
+
+// ----------------------------------------------------------------------------
+// Memory
+// ----------------------------------------------------------------------------
+
+// stringValue returns the value of the given key as a string, and its
+// expiration. It fails if the value is a map or a slice.
+func (c *MemoryStore) stringValue(key string) (string, time.Time, error) {
+	v, expiration, found := c.lookupWithExpiration(key)
+	c.stats.read(found, nil)
+
+	switch value := v.(type) {
+	case nil:
+		return "", expiration, nil
+	case string:
+		return value, expiration, nil
+	case []byte:
+		return string(value), expiration, nil
+	case map[string]interface{}, []interface{}, *fieldMap:
+		return "", expiration, fmt.Errorf("gokvstores: %q is not a string", key)
+	default:
+		return fmt.Sprint(value), expiration, nil
+	}
+}
+
+// GetRange returns the bytes of the value between start and end, both
+// included. Values which are not strings are converted first.
+func (c *MemoryStore) GetRange(key string, start, end int64) (string, error) {
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	value, _, err := c.stringValue(key)
+	if err != nil {
+		return "", err
+	}
+
+	return stringRange(value, start, end), nil
+}
+
+// SetRange overwrites the value from offset with the given bytes, and returns
+// its new length. The value is stored as a string.
+func (c *MemoryStore) SetRange(key string, offset int64, value string) (int64, error) {
+	if offset < 0 {
+		return 0, fmt.Errorf("gokvstores: offset %d is out of range", offset)
+	}
+
+	c.txn.Lock()
+	defer c.txn.Unlock()
+
+	current, expiration, err := c.stringValue(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if int64(len(current)) < offset {
+		current += strings.Repeat("\x00", int(offset)-len(current))
+	}
+
+	updated := current[:offset] + value
+	if end := offset + int64(len(value)); end < int64(len(current)) {
+		updated += current[end:]
+	}
+
+	c.store(key, updated, c.remainingTTL(expiration))
+	c.stats.write(nil)
+	c.watches.notify(ChangeSet, key)
+	return int64(len(updated)), nil
+}
+
+// ----------------------------------------------------------------------------
+// Redis
+// ----------------------------------------------------------------------------
+
+// GetRange returns the bytes of the value between start and end, both
+// included, with GETRANGE.
+func (r *RedisStore) GetRange(key string, start, end int64) (string, error) {
+	value, err := r.reader().GetRange(r.ctx, key, start, end).Result()
+	r.stats.read(value != "", err)
+	return value, err
+}
+
+// SetRange overwrites the value from offset with the given bytes with
+// SETRANGE, and returns its new length.
+func (r *RedisStore) SetRange(key string, offset int64, value string) (int64, error) {
+	var cmd *redis.IntCmd
+	err := r.write(func(c redis.Cmdable) error {
+		cmd = c.SetRange(r.ctx, key, offset, value)
+		return cmd.Err()
+	})
+	r.uncache(key)
+	r.stats.write(err)
+	return cmd.Val(), err
+}
@@ -0,0 +1,77 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringRange(t *testing.T) {
+	is := assert.New(t)
+
+	is.Equal("This", stringRange("This is a string", 0, 3))
+	is.Equal("ing", stringRange("This is a string", -3, -1))
+	is.Equal("This is a string", stringRange("This is a string", 0, -1))
+	is.Equal("string", stringRange("This is a string", 10, 100))
+	is.Equal("", stringRange("This is a string", 5, 2))
+	is.Equal("", stringRange("", 0, -1))
+}
+
+func testRangeStore(t *testing.T, store KVStore) {
+	is := assert.New(t)
+
+	rs := store.(RangeStore)
+
+	is.Nil(store.Delete("blob"))
+
+	value, err := rs.GetRange("blob", 0, -1)
+	is.Nil(err)
+	is.Equal("", value)
+
+	is.Nil(store.Set("blob", "Hello World"))
+
+	length, err := rs.SetRange("blob", 6, "Redis")
+	is.Nil(err)
+	is.Equal(int64(11), length)
+
+	value, err = rs.GetRange("blob", -5, -1)
+	is.Nil(err)
+	is.Equal("Redis", value)
+
+	length, err = rs.SetRange("padded", 3, "ab")
+	is.Nil(err)
+	is.Equal(int64(5), length)
+
+	value, err = rs.GetRange("padded", 0, -1)
+	is.Nil(err)
+	is.Equal("\x00\x00\x00ab", value)
+
+	is.Nil(store.Delete("padded"))
+}
+
+func TestMemoryStoreRange(t *testing.T) {
+	store, err := NewMemoryStore(0, time.Second*10)
+	assert.Nil(t, err)
+
+	testRangeStore(t, store)
+
+	is := assert.New(t)
+	rs := store.(RangeStore)
+
+	is.Nil(store.SetWithExpiration("expiring", "abc", time.Minute))
+	_, err = rs.SetRange("expiring", 1, "x")
+	is.Nil(err)
+
+	store.(*MemoryStore).Scan(func(item Item) error {
+		if item.Key == "expiring" {
+			is.Equal("axc", item.Value)
+			is.False(item.Expiration.IsZero())
+		}
+		return nil
+	})
+
+	is.Nil(store.SetMap("map", map[string]interface{}{"a": 1}))
+	_, err = rs.GetRange("map", 0, -1)
+	is.NotNil(err)
+}
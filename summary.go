@@ -0,0 +1,83 @@
+package gokvstores
+
+// KeyspaceSummary describes the items held by a MemoryStore.
+type KeyspaceSummary struct {
+	// Items is the number of unexpired items, spilled ones included.
+	Items int64 `json:"items"`
+
+	// Bytes is the approximate size of the items held in memory, computed
+	// as by MemoryUsage.
+	Bytes int64 `json:"bytes"`
+
+	// Spilled is the number of items moved to SpillDir.
+	Spilled int64 `json:"spilled"`
+
+	// Prefixes summarizes the items of each key prefix.
+	Prefixes map[string]*PrefixSummary `json:"prefixes"`
+}
+
+// PrefixSummary describes the items of a key prefix.
+type PrefixSummary struct {
+	// Items is the number of unexpired items, spilled ones included.
+	Items int64 `json:"items"`
+
+	// Bytes is the approximate size of the items held in memory.
+	Bytes int64 `json:"bytes"`
+}
+
+// add records an item of the given size, zero if spilled.
+func (s *KeyspaceSummary) add(prefix string, size int64) {
+	s.Items++
+	s.Bytes += size
+
+	p := s.Prefixes[prefix]
+	if p == nil {
+		p = &PrefixSummary{}
+		s.Prefixes[prefix] = p
+	}
+	p.Items++
+	p.Bytes += size
+}
+
+// ItemCount returns the number of unexpired items, spilled ones included.
+func (c *MemoryStore) ItemCount() int {
+	count := 0
+	for _, item := range c.items() {
+		if _, found := plainValue(item.object, true); found {
+			count++
+		}
+	}
+
+	if c.spill != nil {
+		count += c.spill.unexpired(nil)
+	}
+
+	return count
+}
+
+// Summary returns the number and size of the unexpired items, in total and
+// per key prefix. prefix extracts the prefix of a key, defaulting to
+// KeyPrefix.
+func (c *MemoryStore) Summary(prefix func(key string) string) *KeyspaceSummary {
+	if prefix == nil {
+		prefix = KeyPrefix
+	}
+
+	summary := &KeyspaceSummary{Prefixes: map[string]*PrefixSummary{}}
+
+	for key, item := range c.items() {
+		value, found := plainValue(item.object, true)
+		if !found {
+			continue
+		}
+		summary.add(prefix(key), int64(itemSize(key, value)))
+	}
+
+	if c.spill != nil {
+		summary.Spilled = int64(c.spill.unexpired(func(key string) {
+			summary.add(prefix(key), 0)
+		}))
+	}
+
+	return summary
+}
@@ -0,0 +1,49 @@
+package gokvstores
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreSummary(t *testing.T) {
+	is := assert.New(t)
+
+	clock := NewManualClock(time.Now())
+
+	store, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{
+		SpillDir:       t.TempDir(),
+		SpillThreshold: 100,
+		Clock:          clock,
+	})
+	is.Nil(err)
+
+	memory := store.(*MemoryStore)
+
+	is.Nil(store.Set("user:1", "value"))
+	is.Nil(store.Set("user:2", "value"))
+	is.Nil(store.SetMap("session:1", map[string]interface{}{"id": "1"}))
+	is.Nil(store.Set("session:2", strings.Repeat("x", 200)))
+	is.Nil(store.SetWithExpiration("short", "value", time.Millisecond))
+
+	clock.Advance(5 * time.Millisecond)
+
+	is.Equal(4, memory.ItemCount())
+
+	summary := memory.Summary(nil)
+	is.Equal(int64(4), summary.Items)
+	is.Equal(int64(1), summary.Spilled)
+
+	usage, err := memory.MemoryUsage()
+	is.Nil(err)
+	is.Equal(usage, summary.Bytes)
+
+	is.Equal(&PrefixSummary{Items: 2, Bytes: 2 * int64(len("user:1")+len("value"))}, summary.Prefixes["user"])
+	is.Equal(&PrefixSummary{Items: 2, Bytes: int64(len("session:1") + len("id") + len("1"))}, summary.Prefixes["session"])
+	is.NotContains(summary.Prefixes, "short")
+
+	summary = memory.Summary(func(key string) string { return key[:1] })
+	is.Equal(int64(2), summary.Prefixes["u"].Items)
+}
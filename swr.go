@@ -0,0 +1,148 @@
+package gokvstores
+
+import (
+	"encoding/gob"
+	"sync"
+	"time"
+)
+
+func init() {
+	gob.Register(swrEntry{})
+}
+
+// swrEntry is the value stored by a StaleWhileRevalidateStore.
+type swrEntry struct {
+	Value      interface{}
+	FreshUntil time.Time
+}
+
+// StaleWhileRevalidateOptions are StaleWhileRevalidateStore options.
+type StaleWhileRevalidateOptions struct {
+	// TTL is how long a value is fresh.
+	TTL time.Duration
+
+	// StaleWindow is how long a value is still served after its TTL,
+	// while being refreshed in the background.
+	StaleWindow time.Duration
+
+	// OnError is called when a background refresh fails.
+	OnError func(key string, err error)
+
+	// Clock tells the time values become stale by. Defaults to the system
+	// time.
+	Clock Clock
+}
+
+// StaleWhileRevalidateStore is a KVStore decorator serving values up to
+// StaleWindow after their expiration while refreshing them in the background
+// with a loader. Missing values are loaded synchronously.
+//
+// Values are stored in an envelope recording their freshness, so the wrapped
+// store must preserve Go types (MemoryStore, or RedisStore with GobCodec).
+// Only Get, Set and SetWithExpiration are affected, other calls go straight
+// to the wrapped store.
+type StaleWhileRevalidateStore struct {
+	KVStore
+
+	loader  LoaderFunc
+	options StaleWhileRevalidateOptions
+
+	mu         sync.Mutex
+	refreshing map[string]bool
+}
+
+// NewStaleWhileRevalidateStore returns a StaleWhileRevalidateStore wrapping the given store.
+func NewStaleWhileRevalidateStore(store KVStore, loader LoaderFunc, options *StaleWhileRevalidateOptions) *StaleWhileRevalidateStore {
+	if options == nil {
+		options = &StaleWhileRevalidateOptions{}
+	}
+
+	s := &StaleWhileRevalidateStore{
+		KVStore:    store,
+		loader:     loader,
+		options:    *options,
+		refreshing: make(map[string]bool),
+	}
+
+	if s.options.Clock == nil {
+		s.options.Clock = systemClock{}
+	}
+
+	return s
+}
+
+// Get returns value for the given key, refreshing it in the background when stale.
+func (s *StaleWhileRevalidateStore) Get(key string) (interface{}, error) {
+	value, err := s.KVStore.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, ok := value.(swrEntry)
+	if !ok {
+		return s.load(key)
+	}
+
+	// Values set without expiration have no FreshUntil and never go stale.
+	if !entry.FreshUntil.IsZero() && s.options.Clock.Now().After(entry.FreshUntil) {
+		s.refresh(key)
+	}
+
+	return entry.Value, nil
+}
+
+// Set sets value for the given key, fresh for TTL.
+func (s *StaleWhileRevalidateStore) Set(key string, value interface{}) error {
+	return s.SetWithExpiration(key, value, s.options.TTL)
+}
+
+// SetWithExpiration sets value for the given key, fresh for the given expiration.
+func (s *StaleWhileRevalidateStore) SetWithExpiration(key string, value interface{}, expiration time.Duration) error {
+	if expiration <= 0 {
+		return s.KVStore.SetWithExpiration(key, swrEntry{Value: value}, 0)
+	}
+
+	entry := swrEntry{
+		Value:      value,
+		FreshUntil: s.options.Clock.Now().Add(expiration),
+	}
+
+	return s.KVStore.SetWithExpiration(key, entry, expiration+s.options.StaleWindow)
+}
+
+// load loads and stores the value of the given key.
+func (s *StaleWhileRevalidateStore) load(key string) (interface{}, error) {
+	value, err := s.loader(key)
+	if err != nil || value == nil {
+		return nil, err
+	}
+
+	if err := s.Set(key, value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// refresh reloads the given key in the background, unless already in progress.
+func (s *StaleWhileRevalidateStore) refresh(key string) {
+	s.mu.Lock()
+	if s.refreshing[key] {
+		s.mu.Unlock()
+		return
+	}
+	s.refreshing[key] = true
+	s.mu.Unlock()
+
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.refreshing, key)
+			s.mu.Unlock()
+		}()
+
+		if _, err := s.load(key); err != nil && s.options.OnError != nil {
+			s.options.OnError(key, err)
+		}
+	}()
+}
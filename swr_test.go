@@ -0,0 +1,78 @@
+package gokvstores
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaleWhileRevalidateStore(t *testing.T) {
+	is := assert.New(t)
+
+	clock := NewManualClock(time.Now())
+
+	memory, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{
+		Expiration:      time.Second * 10,
+		CleanupInterval: time.Hour,
+		Clock:           clock,
+	})
+	is.Nil(err)
+
+	var loads int32
+
+	store := NewStaleWhileRevalidateStore(memory, func(key string) (interface{}, error) {
+		return fmt.Sprintf("%s:%d", key, atomic.AddInt32(&loads, 1)), nil
+	}, &StaleWhileRevalidateOptions{
+		TTL:         50 * time.Millisecond,
+		StaleWindow: time.Second,
+		Clock:       clock,
+	})
+
+	v, err := store.Get("key")
+	is.Nil(err)
+	is.Equal("key:1", v)
+
+	v, err = store.Get("key")
+	is.Nil(err)
+	is.Equal("key:1", v)
+
+	clock.Advance(60 * time.Millisecond)
+
+	// Stale value is served while refreshed
+
+	v, err = store.Get("key")
+	is.Nil(err)
+	is.Equal("key:1", v)
+
+	is.Eventually(func() bool {
+		v, err := store.Get("key")
+		return err == nil && v == "key:2"
+	}, time.Second, time.Millisecond)
+	is.Equal(int32(2), atomic.LoadInt32(&loads))
+
+	// Set values are wrapped too
+
+	is.Nil(store.Set("other", "value"))
+
+	v, err = store.Get("other")
+	is.Nil(err)
+	is.Equal("value", v)
+
+	// Values set without expiration are never refreshed
+
+	is.Nil(store.SetWithExpiration("permanent", "value", 0))
+
+	clock.Advance(time.Hour)
+
+	v, err = store.Get("permanent")
+	is.Nil(err)
+	is.Equal("value", v)
+
+	store.mu.Lock()
+	is.False(store.refreshing["permanent"])
+	store.mu.Unlock()
+	is.Equal(int32(2), atomic.LoadInt32(&loads))
+}
@@ -0,0 +1,216 @@
+package gokvstores
+
+import (
+	"context"
+	"sync"
+)
+
+// TenantQuota limits the data a tenant can store. Zero values mean no limit.
+type TenantQuota struct {
+	MaxKeys  int
+	MaxBytes int
+}
+
+// TenantOptions are TenantStore options.
+type TenantOptions struct {
+	// Tenant returns the tenant of a context, empty if none.
+	Tenant func(ctx context.Context) string
+
+	// Quota applies to every tenant.
+	Quota TenantQuota
+
+	// Quotas overrides the quota per tenant.
+	Quotas map[string]TenantQuota
+}
+
+// tenantUsage tracks the keys written by a tenant and their size.
+type tenantUsage struct {
+	keys  map[string]int
+	bytes int
+}
+
+// TenantStore shares a store between tenants, each tenant having its own key
+// namespace and quota. Tenants access the store through WithContext.
+//
+// Usage is tracked from the writes made through this TenantStore instance,
+// so quotas are approximate: expired keys are still accounted until deleted.
+type TenantStore struct {
+	store   KVStore
+	options TenantOptions
+
+	mu    sync.Mutex
+	usage map[string]*tenantUsage
+}
+
+// NewTenantStore returns a TenantStore wrapping the given store. Without a
+// Tenant function, no context carries a tenant.
+func NewTenantStore(store KVStore, options *TenantOptions) *TenantStore {
+	if options == nil {
+		options = &TenantOptions{}
+	}
+
+	s := &TenantStore{
+		store:   store,
+		options: *options,
+		usage:   make(map[string]*tenantUsage),
+	}
+
+	if s.options.Tenant == nil {
+		s.options.Tenant = func(ctx context.Context) string { return "" }
+	}
+
+	return s
+}
+
+// WithContext returns the view of the store of the tenant carried by the context.
+// Flushing the view only deletes the keys of the tenant, closing it does nothing.
+func (s *TenantStore) WithContext(ctx context.Context) KVStore {
+	tenant := s.options.Tenant(ctx)
+
+	return &interceptedStore{
+		store: s.store,
+		intercept: func(op *operation, next func() error) error {
+			return s.intercept(tenant, op, next)
+		},
+	}
+}
+
+// Usage returns the number of keys and bytes stored by the given tenant.
+func (s *TenantStore) Usage(tenant string) (keys int, bytes int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if usage, ok := s.usage[tenant]; ok {
+		return len(usage.keys), usage.bytes
+	}
+
+	return 0, 0
+}
+
+// quota returns the quota of the given tenant.
+func (s *TenantStore) quota(tenant string) TenantQuota {
+	if quota, ok := s.options.Quotas[tenant]; ok {
+		return quota
+	}
+
+	return s.options.Quota
+}
+
+// intercept runs an operation in the namespace of the given tenant.
+func (s *TenantStore) intercept(tenant string, op *operation, next func() error) error {
+	if tenant == "" {
+		return ErrNoTenant
+	}
+
+	switch op.name {
+	case "Close":
+		return nil
+	case "Ping":
+		return next()
+	case "Flush":
+		return s.flush(tenant)
+	}
+
+	key := op.key
+	op.key = "tenant:" + tenant + ":" + key
+
+	if op.read() {
+		return next()
+	}
+
+	if op.name == "Delete" {
+		if err := next(); err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		usage := s.tenantUsage(tenant)
+		usage.bytes -= usage.keys[key]
+		delete(usage.keys, key)
+		s.mu.Unlock()
+
+		return nil
+	}
+
+	// The write is accounted before being sent, so that concurrent writes
+	// can't exceed the quota, and the lock is not held while it is.
+	s.mu.Lock()
+
+	usage := s.tenantUsage(tenant)
+	previous, exists := usage.keys[key]
+
+	size := valueSize(op.value)
+	if op.name == "AppendSlice" {
+		size += previous
+	}
+
+	quota := s.quota(tenant)
+
+	if quota.MaxKeys > 0 && !exists && len(usage.keys) >= quota.MaxKeys {
+		s.mu.Unlock()
+		return &QuotaExceededError{Tenant: tenant, Quota: "keys", Limit: quota.MaxKeys}
+	}
+
+	if quota.MaxBytes > 0 && usage.bytes-previous+size > quota.MaxBytes {
+		s.mu.Unlock()
+		return &QuotaExceededError{Tenant: tenant, Quota: "bytes", Limit: quota.MaxBytes}
+	}
+
+	usage.keys[key] = size
+	usage.bytes += size - previous
+
+	s.mu.Unlock()
+
+	err := next()
+	if err != nil {
+		s.mu.Lock()
+		if current, ok := usage.keys[key]; ok && current == size {
+			usage.bytes -= size - previous
+			if exists {
+				usage.keys[key] = previous
+			} else {
+				delete(usage.keys, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	return err
+}
+
+// tenantUsage returns the usage of the given tenant. The caller holds s.mu.
+func (s *TenantStore) tenantUsage(tenant string) *tenantUsage {
+	usage, ok := s.usage[tenant]
+	if !ok {
+		usage = &tenantUsage{keys: make(map[string]int)}
+		s.usage[tenant] = usage
+	}
+
+	return usage
+}
+
+// flush deletes the keys written by the given tenant.
+func (s *TenantStore) flush(tenant string) error {
+	s.mu.Lock()
+	var keys []string
+	if usage, ok := s.usage[tenant]; ok {
+		for key := range usage.keys {
+			keys = append(keys, key)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, key := range keys {
+		if err := s.store.Delete("tenant:" + tenant + ":" + key); err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		usage := s.usage[tenant]
+		usage.bytes -= usage.keys[key]
+		delete(usage.keys, key)
+		s.mu.Unlock()
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package gokvstores
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tenantKey struct{}
+
+func TestTenantStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	tenants := NewTenantStore(memory, &TenantOptions{
+		Tenant: func(ctx context.Context) string {
+			tenant, _ := ctx.Value(tenantKey{}).(string)
+			return tenant
+		},
+		Quota:  TenantQuota{MaxKeys: 2},
+		Quotas: map[string]TenantQuota{"big": {MaxBytes: 10}},
+	})
+
+	acme := tenants.WithContext(context.WithValue(context.Background(), tenantKey{}, "acme"))
+	big := tenants.WithContext(context.WithValue(context.Background(), tenantKey{}, "big"))
+
+	testStore(t, acme)
+
+	is.Nil(acme.Set("key", "acme"))
+	is.Nil(big.Set("key", "big"))
+
+	v, err := acme.Get("key")
+	is.Nil(err)
+	is.Equal("acme", v)
+
+	v, err = memory.Get("tenant:big:key")
+	is.Nil(err)
+	is.Equal("big", v)
+
+	// Quotas
+
+	is.Nil(acme.Set("key2", "value"))
+	is.Nil(acme.Set("key2", "value"))
+	is.Equal(&QuotaExceededError{Tenant: "acme", Quota: "keys", Limit: 2}, acme.Set("key3", "value"))
+
+	is.Nil(big.Set("key2", "1234567"))
+	is.Equal(&QuotaExceededError{Tenant: "big", Quota: "bytes", Limit: 10}, big.Set("key3", "value"))
+
+	keys, bytes := tenants.Usage("big")
+	is.Equal(2, keys)
+	is.Equal(10, bytes)
+
+	// Independent flushes
+
+	is.Nil(acme.Flush())
+
+	keys, _ = tenants.Usage("acme")
+	is.Equal(0, keys)
+
+	v, err = big.Get("key")
+	is.Nil(err)
+	is.Equal("big", v)
+
+	// Missing tenant
+
+	is.Equal(ErrNoTenant, tenants.WithContext(context.Background()).Set("key", "value"))
+	is.Equal(ErrNoTenant, NewTenantStore(memory, nil).WithContext(context.Background()).Set("key", "value"))
+}
+
+// blockingSetStore is a store whose Set of the given key waits for release.
+type blockingSetStore struct {
+	KVStore
+	key     string
+	release chan struct{}
+}
+
+func (s *blockingSetStore) Set(key string, value interface{}) error {
+	if key == s.key {
+		<-s.release
+	}
+	return s.KVStore.Set(key, value)
+}
+
+func TestTenantStoreConcurrentWrites(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	backend := &blockingSetStore{KVStore: memory, key: "tenant:slow:key", release: make(chan struct{})}
+
+	tenants := NewTenantStore(backend, &TenantOptions{
+		Tenant: func(ctx context.Context) string {
+			tenant, _ := ctx.Value(tenantKey{}).(string)
+			return tenant
+		},
+		Quota: TenantQuota{MaxKeys: 1},
+	})
+
+	slow := tenants.WithContext(context.WithValue(context.Background(), tenantKey{}, "slow"))
+	fast := tenants.WithContext(context.WithValue(context.Background(), tenantKey{}, "fast"))
+
+	done := make(chan error)
+	go func() {
+		done <- slow.Set("key", "value")
+	}()
+
+	// The pending write counts against the quota, without holding others.
+	is.Eventually(func() bool {
+		keys, _ := tenants.Usage("slow")
+		return keys == 1
+	}, time.Second, time.Millisecond)
+
+	is.Equal(&QuotaExceededError{Tenant: "slow", Quota: "keys", Limit: 1}, slow.Set("other", "value"))
+	is.Nil(fast.Set("key", "value"))
+
+	close(backend.release)
+	is.Nil(<-done)
+}
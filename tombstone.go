@@ -0,0 +1,131 @@
+package gokvstores
+
+import "time"
+
+// tombstoneValue replaces the values of deleted keys until their tombstone
+// expires. It is a string, so that every codec preserves it.
+const tombstoneValue = ReservedPrefix + "tombstone"
+
+// defaultTombstoneTTL is the lifetime of tombstones when none is given.
+const defaultTombstoneTTL = time.Minute
+
+// TombstoneStore is a KVStore decorator leaving a short-lived tombstone in
+// place of deleted keys, so multi-tier setups can tell a deleted key from a
+// missing one and avoid resurrecting it from a slower tier.
+//
+// Deleted keys read as missing until written again, and are removed from the
+// wrapped store once their tombstone expires.
+type TombstoneStore struct {
+	KVStore
+
+	ttl time.Duration
+}
+
+// NewTombstoneStore returns a TombstoneStore wrapping the given store.
+// Tombstones expire after the given ttl, one minute if zero or negative.
+func NewTombstoneStore(store KVStore, ttl time.Duration) *TombstoneStore {
+	if ttl <= 0 {
+		ttl = defaultTombstoneTTL
+	}
+
+	return &TombstoneStore{KVStore: store, ttl: ttl}
+}
+
+// Get returns value for the given key, nil if it was deleted.
+func (s *TombstoneStore) Get(key string) (interface{}, error) {
+	value, err := s.KVStore.Get(key)
+	if err != nil || value == tombstoneValue {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// GetMap returns map for the given key, nil if it was deleted.
+func (s *TombstoneStore) GetMap(key string) (map[string]interface{}, error) {
+	value, err := s.KVStore.GetMap(key)
+	if err != nil {
+		if s.tombstoned(key) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// GetSlice returns slice for the given key, nil if it was deleted.
+func (s *TombstoneStore) GetSlice(key string) ([]interface{}, error) {
+	value, err := s.KVStore.GetSlice(key)
+	if err != nil {
+		if s.tombstoned(key) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// AppendSlice appends values to the slice of the given key, creating it if
+// the key was deleted.
+func (s *TombstoneStore) AppendSlice(key string, values ...interface{}) error {
+	err := s.KVStore.AppendSlice(key, values...)
+	if err == nil {
+		return nil
+	}
+
+	if !s.tombstoned(key) {
+		return err
+	}
+
+	if err := s.KVStore.Delete(key); err != nil {
+		return err
+	}
+
+	return s.KVStore.AppendSlice(key, values...)
+}
+
+// Exists checks if the given key exists and was not deleted.
+func (s *TombstoneStore) Exists(key string) (bool, error) {
+	exists, err := s.KVStore.Exists(key)
+	if err != nil || !exists {
+		return false, err
+	}
+
+	return !s.tombstoned(key), nil
+}
+
+// Delete replaces the value of the given key with a tombstone, removed from
+// the wrapped store once expired.
+func (s *TombstoneStore) Delete(key string) error {
+	return s.KVStore.SetWithExpiration(key, tombstoneValue, s.ttl)
+}
+
+// Deleted reports whether the given key was recently deleted and not written since.
+func (s *TombstoneStore) Deleted(key string) (bool, error) {
+	value, err := s.KVStore.Get(key)
+	if err != nil {
+		return false, err
+	}
+
+	return value == tombstoneValue, nil
+}
+
+// tombstoned reports whether the given key holds a tombstone. Keys which
+// can't be read as strings, such as Redis maps, do not.
+func (s *TombstoneStore) tombstoned(key string) bool {
+	deleted, err := s.Deleted(key)
+	return err == nil && deleted
+}
+
+// Scan calls fn with each item of the wrapped store, skipping the tombstones.
+// It returns ErrNotSupported if the wrapped store is not a Scanner.
+func (s *TombstoneStore) Scan(fn func(item Item) error) error {
+	return scanUserItems(s.KVStore, func(item Item) error {
+		if item.Value == tombstoneValue {
+			return nil
+		}
+		return fn(item)
+	})
+}
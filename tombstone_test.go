@@ -0,0 +1,97 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTombstoneStore(t *testing.T) {
+	is := assert.New(t)
+
+	clock := NewManualClock(time.Now())
+
+	memory, err := NewMemoryStoreWithOptions(&MemoryStoreOptions{
+		Expiration:      time.Second * 10,
+		CleanupInterval: time.Second * 10,
+		Clock:           clock,
+	})
+	is.Nil(err)
+
+	store := NewTombstoneStore(memory, 50*time.Millisecond)
+
+	testStore(t, store)
+
+	is.Nil(store.Set("key", "value"))
+
+	deleted, err := store.Deleted("key")
+	is.Nil(err)
+	is.False(deleted)
+
+	is.Nil(store.Delete("key"))
+
+	v, err := store.Get("key")
+	is.Nil(err)
+	is.Nil(v)
+
+	deleted, err = store.Deleted("key")
+	is.Nil(err)
+	is.True(deleted)
+
+	exists, err := store.Exists("key")
+	is.Nil(err)
+	is.False(exists)
+
+	// The key is only removed from the wrapped store once the tombstone
+	// expires, and tombstones are not scanned.
+	exists, err = memory.Exists("key")
+	is.Nil(err)
+	is.True(exists)
+
+	is.Nil(store.Scan(func(item Item) error {
+		is.NotEqual("key", item.Key)
+		return nil
+	}))
+
+	m, err := store.GetMap("key")
+	is.Nil(err)
+	is.Nil(m)
+
+	is.Nil(store.AppendSlice("key", "a"))
+
+	slice, err := store.GetSlice("key")
+	is.Nil(err)
+	is.Equal([]interface{}{"a"}, slice)
+
+	is.Nil(store.Delete("key"))
+
+	deleted, err = store.Deleted("unknown")
+	is.Nil(err)
+	is.False(deleted)
+
+	// Writing the key again clears the tombstone
+
+	is.Nil(store.Set("key", "value"))
+
+	deleted, err = store.Deleted("key")
+	is.Nil(err)
+	is.False(deleted)
+
+	// Tombstones expire
+
+	is.Nil(store.Delete("key"))
+
+	clock.Advance(60 * time.Millisecond)
+
+	deleted, err = store.Deleted("key")
+	is.Nil(err)
+	is.False(deleted)
+
+	exists, err = memory.Exists("key")
+	is.Nil(err)
+	is.False(exists)
+
+	// Tombstones live one minute by default.
+	is.Equal(time.Minute, NewTombstoneStore(memory, 0).ttl)
+}
@@ -0,0 +1,112 @@
+package gokvstores
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation name used when no tracer is provided.
+const tracerName = "github.com/louiseGrandjonc/gokvstores"
+
+// TracingOptions are TracingStore options.
+type TracingOptions struct {
+	// Tracer creates the spans. Defaults to the global tracer provider.
+	Tracer trace.Tracer
+
+	// Backend is reported as the db.system attribute (e.g. "redis").
+	Backend string
+
+	// HashKeys replaces keys by their SHA-256 in span attributes.
+	HashKeys bool
+}
+
+// TracingStore is a KVStore decorator creating an OpenTelemetry span for each operation.
+type TracingStore struct {
+	*interceptedStore
+
+	ctx     context.Context
+	tracer  trace.Tracer
+	options TracingOptions
+}
+
+// NewTracingStore returns a TracingStore wrapping the given store.
+func NewTracingStore(store KVStore, options *TracingOptions) *TracingStore {
+	if options == nil {
+		options = &TracingOptions{}
+	}
+
+	tracer := options.Tracer
+	if tracer == nil {
+		tracer = otel.Tracer(tracerName)
+	}
+
+	t := &TracingStore{
+		ctx:     context.Background(),
+		tracer:  tracer,
+		options: *options,
+	}
+
+	t.interceptedStore = &interceptedStore{store: store, intercept: t.trace}
+
+	return t
+}
+
+// WithContext returns a copy of the store whose spans are children of the span
+// carried by the given context.
+func (t *TracingStore) WithContext(ctx context.Context) *TracingStore {
+	c := *t
+	c.ctx = ctx
+	c.interceptedStore = &interceptedStore{store: t.store, intercept: c.trace}
+
+	return &c
+}
+
+// trace wraps a single operation in a span.
+func (t *TracingStore) trace(op *operation, next func() error) error {
+	attributes := []attribute.KeyValue{
+		attribute.String("db.operation", op.name),
+	}
+
+	if t.options.Backend != "" {
+		attributes = append(attributes, attribute.String("db.system", t.options.Backend))
+	}
+
+	if op.key != "" {
+		attributes = append(attributes, attribute.String("db.kvstore.key", t.key(op.key)))
+	}
+
+	_, span := t.tracer.Start(t.ctx, "kvstore."+op.name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...))
+	defer span.End()
+
+	err := next()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	if op.read() {
+		span.SetAttributes(attribute.Bool("db.kvstore.hit", op.hit))
+	}
+
+	return nil
+}
+
+// key returns the key as reported in span attributes.
+func (t *TracingStore) key(key string) string {
+	if !t.options.HashKeys {
+		return key
+	}
+
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
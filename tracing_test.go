@@ -0,0 +1,128 @@
+package gokvstores
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanAttributes returns the attributes of a span by key.
+func spanAttributes(span sdktrace.ReadOnlySpan) map[string]interface{} {
+	attributes := map[string]interface{}{}
+	for _, kv := range span.Attributes() {
+		attributes[string(kv.Key)] = kv.Value.AsInterface()
+	}
+
+	return attributes
+}
+
+func TestTracingStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	store := NewTracingStore(memory, &TracingOptions{
+		Backend:  "memory",
+		HashKeys: true,
+	})
+
+	testStore(t, store)
+	testStore(t, store.WithContext(context.Background()))
+
+	is.Equal("2c70e12b7a0646f92279f427c7b38e7334d8e5389cff167a1dc30e73f826b683", store.key("key"))
+}
+
+func TestTracingStoreSpans(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	store := NewTracingStore(memory, &TracingOptions{
+		Tracer:  provider.Tracer("test"),
+		Backend: "memory",
+	})
+
+	is.Nil(store.Set("key", "value"))
+
+	_, err = store.Get("key")
+	is.Nil(err)
+
+	_, err = store.Get("missing")
+	is.Nil(err)
+
+	_, err = store.GetMap("key")
+	is.Equal(ErrWrongType, err)
+
+	is.Nil(store.Flush())
+
+	spans := recorder.Ended()
+	is.Len(spans, 5)
+
+	for _, span := range spans {
+		is.Equal(trace.SpanKindClient, span.SpanKind())
+	}
+
+	is.Equal("kvstore.Set", spans[0].Name())
+	is.Equal(map[string]interface{}{
+		"db.operation":   "Set",
+		"db.system":      "memory",
+		"db.kvstore.key": "key",
+	}, spanAttributes(spans[0]))
+	is.Equal(codes.Unset, spans[0].Status().Code)
+
+	is.Equal("kvstore.Get", spans[1].Name())
+	is.Equal(map[string]interface{}{
+		"db.operation":   "Get",
+		"db.system":      "memory",
+		"db.kvstore.key": "key",
+		"db.kvstore.hit": true,
+	}, spanAttributes(spans[1]))
+
+	is.Equal("kvstore.Get", spans[2].Name())
+	is.Equal(false, spanAttributes(spans[2])["db.kvstore.hit"])
+
+	is.Equal("kvstore.GetMap", spans[3].Name())
+	is.Equal(sdktrace.Status{Code: codes.Error, Description: ErrWrongType.Error()}, spans[3].Status())
+	is.NotContains(spanAttributes(spans[3]), "db.kvstore.hit")
+
+	is.Equal("kvstore.Flush", spans[4].Name())
+	is.Equal(map[string]interface{}{
+		"db.operation": "Flush",
+		"db.system":    "memory",
+	}, spanAttributes(spans[4]))
+}
+
+func TestTracingStoreHashKeys(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	store := NewTracingStore(memory, &TracingOptions{
+		Tracer:   provider.Tracer("test"),
+		HashKeys: true,
+	})
+
+	is.Nil(store.Set("key", "value"))
+
+	spans := recorder.Ended()
+	is.Len(spans, 1)
+	is.Equal(map[string]interface{}{
+		"db.operation":   "Set",
+		"db.kvstore.key": "2c70e12b7a0646f92279f427c7b38e7334d8e5389cff167a1dc30e73f826b683",
+	}, spanAttributes(spans[0]))
+}
@@ -0,0 +1,255 @@
+package gokvstores
+
+import (
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Txn is a transaction. Reads are executed immediately, writes are queued
+// and applied atomically once the transaction function returns: reads do
+// not see the writes of their own transaction.
+type Txn interface {
+	Get(key string) (interface{}, error)
+	GetMap(key string) (map[string]interface{}, error)
+	GetSlice(key string) ([]interface{}, error)
+	Exists(key string) (bool, error)
+
+	Set(key string, value interface{})
+	SetWithExpiration(key string, value interface{}, expiration time.Duration)
+	SetMap(key string, value map[string]interface{})
+	SetSlice(key string, values []interface{})
+	AppendSlice(key string, values ...interface{})
+	Delete(key string)
+}
+
+// Transactioner is implemented by stores able to apply several writes atomically.
+type Transactioner interface {
+	// Txn runs fn and applies the writes it queued atomically, unless it
+	// returns an error. With Redis, Txn fails with ErrTxnConflict if one of
+	// the watched keys is modified by another client before the writes are applied.
+	// fn must only access the store through tx.
+	Txn(fn func(tx Txn) error, watch ...string) error
+}
+
+// txnWriter is the write half of KVStore, transactions apply their writes to.
+type txnWriter interface {
+	Set(key string, value interface{}) error
+	SetWithExpiration(key string, value interface{}, expiration time.Duration) error
+	SetMap(key string, value map[string]interface{}) error
+	SetSlice(key string, values []interface{}) error
+	AppendSlice(key string, values ...interface{}) error
+	Delete(key string) error
+}
+
+// txnWrites queues the writes of a transaction.
+type txnWrites []func(w txnWriter) error
+
+func (t *txnWrites) Set(key string, value interface{}) {
+	*t = append(*t, func(w txnWriter) error { return w.Set(key, value) })
+}
+
+func (t *txnWrites) SetWithExpiration(key string, value interface{}, expiration time.Duration) {
+	*t = append(*t, func(w txnWriter) error { return w.SetWithExpiration(key, value, expiration) })
+}
+
+func (t *txnWrites) SetMap(key string, value map[string]interface{}) {
+	*t = append(*t, func(w txnWriter) error { return w.SetMap(key, value) })
+}
+
+func (t *txnWrites) SetSlice(key string, values []interface{}) {
+	*t = append(*t, func(w txnWriter) error { return w.SetSlice(key, values) })
+}
+
+func (t *txnWrites) AppendSlice(key string, values ...interface{}) {
+	*t = append(*t, func(w txnWriter) error { return w.AppendSlice(key, values...) })
+}
+
+func (t *txnWrites) Delete(key string) {
+	*t = append(*t, func(w txnWriter) error { return w.Delete(key) })
+}
+
+// apply applies the queued writes to w, stopping at the first error.
+func (t txnWrites) apply(w txnWriter) error {
+	for _, write := range t {
+		if err := write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// Memory
+// ----------------------------------------------------------------------------
+
+// memoryTxn is a MemoryStore transaction, run while holding the store
+// exclusively. It is also the txnWriter writes are applied to.
+type memoryTxn struct {
+	txnWrites
+	store *MemoryStore
+}
+
+func (t *memoryTxn) Get(key string) (interface{}, error) {
+	return t.store.get(key)
+}
+
+func (t *memoryTxn) GetMap(key string) (map[string]interface{}, error) {
+	return t.store.getMap(key)
+}
+
+func (t *memoryTxn) GetSlice(key string) ([]interface{}, error) {
+	return t.store.getSlice(key)
+}
+
+func (t *memoryTxn) Exists(key string) (bool, error) {
+	return t.store.exists(key)
+}
+
+// memoryTxnWriter applies writes to a MemoryStore held by a transaction.
+type memoryTxnWriter struct {
+	store *MemoryStore
+}
+
+func (w memoryTxnWriter) Set(key string, value interface{}) error {
+	return w.store.set(key, value)
+}
+
+func (w memoryTxnWriter) SetWithExpiration(key string, value interface{}, expiration time.Duration) error {
+	return w.store.setWithExpiration(key, value, expiration)
+}
+
+func (w memoryTxnWriter) SetMap(key string, value map[string]interface{}) error {
+	return w.store.setMap(key, value)
+}
+
+func (w memoryTxnWriter) SetSlice(key string, values []interface{}) error {
+	return w.store.setSlice(key, values)
+}
+
+func (w memoryTxnWriter) AppendSlice(key string, values ...interface{}) error {
+	return w.store.appendSlice(key, values...)
+}
+
+func (w memoryTxnWriter) Delete(key string) error {
+	return w.store.delete(key)
+}
+
+// Txn runs fn while holding the store exclusively, then applies its writes.
+// Watched keys are ignored: no other operation can run concurrently.
+//
+// Writes are applied in order, and the first failing one, such as an
+// AppendSlice to a string, ends the transaction: the writes before it stay
+// applied. OnEvicted functions are called while the store is held, so they
+// must not use it.
+func (c *MemoryStore) Txn(fn func(tx Txn) error, watch ...string) error {
+	c.txn.Lock()
+	defer c.txn.Unlock()
+
+	tx := &memoryTxn{store: c}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.apply(memoryTxnWriter{store: c})
+}
+
+// ----------------------------------------------------------------------------
+// Redis
+// ----------------------------------------------------------------------------
+
+// redisTxn is a RedisStore transaction, reading through the connection
+// watching the keys.
+type redisTxn struct {
+	txnWrites
+	store *RedisStore
+	tx    *redis.Tx
+}
+
+func (t *redisTxn) Get(key string) (interface{}, error) {
+	return t.store.decodeString(t.tx.Get(t.store.ctx, key))
+}
+
+func (t *redisTxn) GetMap(key string) (map[string]interface{}, error) {
+	return t.store.decodeMap(t.tx.HGetAll(t.store.ctx, key))
+}
+
+func (t *redisTxn) GetSlice(key string) ([]interface{}, error) {
+	return t.store.decodeSlice(t.tx.SMembers(t.store.ctx, key))
+}
+
+func (t *redisTxn) Exists(key string) (bool, error) {
+	n, err := t.tx.Exists(t.store.ctx, key).Result()
+	return n > 0, err
+}
+
+// pipelineTxnWriter queues writes in a pipeline.
+type pipelineTxnWriter struct {
+	Pipeline
+}
+
+func (w pipelineTxnWriter) Set(key string, value interface{}) error {
+	w.Pipeline.Set(key, value)
+	return nil
+}
+
+func (w pipelineTxnWriter) SetWithExpiration(key string, value interface{}, expiration time.Duration) error {
+	w.Pipeline.SetWithExpiration(key, value, expiration)
+	return nil
+}
+
+func (w pipelineTxnWriter) SetMap(key string, value map[string]interface{}) error {
+	w.Pipeline.SetMap(key, value)
+	return nil
+}
+
+func (w pipelineTxnWriter) SetSlice(key string, values []interface{}) error {
+	w.Pipeline.SetSlice(key, values)
+	return nil
+}
+
+func (w pipelineTxnWriter) AppendSlice(key string, values ...interface{}) error {
+	w.Pipeline.AppendSlice(key, values...)
+	return nil
+}
+
+func (w pipelineTxnWriter) Delete(key string) error {
+	w.Pipeline.Delete(key)
+	return nil
+}
+
+// Txn runs fn after watching the given keys, then applies its writes in a
+// MULTI/EXEC block.
+func (r *RedisStore) Txn(fn func(tx Txn) error, watch ...string) error {
+	var pipe *redisPipeline
+
+	err := r.client.Watch(r.ctx, func(tx *redis.Tx) error {
+		t := &redisTxn{store: r, tx: tx}
+		if err := fn(t); err != nil {
+			return err
+		}
+
+		if len(t.txnWrites) == 0 {
+			return nil
+		}
+
+		_, err := tx.TxPipelined(r.ctx, func(p redis.Pipeliner) error {
+			pipe = &redisPipeline{store: r, pipe: p}
+			return t.apply(pipelineTxnWriter{pipe})
+		})
+
+		return err
+	}, watch...)
+
+	if err == redis.TxFailedErr {
+		return ErrTxnConflict
+	}
+
+	if pipe != nil {
+		if perr := pipe.complete(); err == nil {
+			err = perr
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,127 @@
+package gokvstores
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	conv "github.com/cstockton/go-conv"
+	"github.com/stretchr/testify/assert"
+)
+
+// increment increments the integer stored at key in a transaction.
+func increment(store Transactioner, key string) error {
+	return store.Txn(func(tx Txn) error {
+		v, err := tx.Get(key)
+		if err != nil {
+			return err
+		}
+
+		n, _ := conv.Int64(v)
+		tx.Set(key, n+1)
+
+		return nil
+	}, key)
+}
+
+func testTxn(t *testing.T, store KVStore) {
+	is := assert.New(t)
+
+	is.Nil(store.Flush())
+
+	txn := store.(Transactioner)
+
+	// Writes are applied together
+
+	err := txn.Txn(func(tx Txn) error {
+		tx.Set("key", "value")
+		tx.SetMap("map", map[string]interface{}{"language": "go"})
+		tx.AppendSlice("slice", "a")
+
+		v, err := tx.Get("key")
+		is.Nil(err)
+		is.Nil(v)
+
+		return nil
+	})
+	is.Nil(err)
+
+	v, err := store.Get("key")
+	is.Nil(err)
+	is.Equal("value", conv.String(v))
+
+	m, err := store.GetMap("map")
+	is.Nil(err)
+	is.Equal(map[string]interface{}{"language": "go"}, m)
+
+	// Failing transactions are discarded
+
+	failure := errors.New("failure")
+	err = txn.Txn(func(tx Txn) error {
+		tx.Delete("key")
+		return failure
+	})
+	is.Equal(failure, err)
+
+	exists, err := store.Exists("key")
+	is.Nil(err)
+	is.True(exists)
+}
+
+func TestMemoryStoreTxn(t *testing.T) {
+	is := assert.New(t)
+
+	store, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	testTxn(t, store)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			is.Nil(increment(store.(Transactioner), "counter"))
+		}()
+	}
+	wg.Wait()
+
+	v, err := store.Get("counter")
+	is.Nil(err)
+	is.Equal(int64(50), v)
+
+	// Snapshots wait for the transaction to be fully applied.
+	memory := store.(*MemoryStore)
+	snapshots := make(chan *Snapshot)
+
+	is.Nil(memory.Txn(func(tx Txn) error {
+		started := make(chan struct{})
+		go func() {
+			close(started)
+			snapshot, _ := memory.Snapshot()
+			snapshots <- snapshot
+		}()
+		<-started
+
+		tx.Set("first", "value")
+		tx.Set("second", "value")
+		return nil
+	}))
+
+	snapshot := <-snapshots
+	is.Contains(snapshot.Keys(), "first")
+	is.Contains(snapshot.Keys(), "second")
+
+	// Scan functions may use the store.
+	is.Nil(memory.Scan(func(item Item) error {
+		if item.Key == "first" {
+			return memory.Delete("second")
+		}
+		return nil
+	}))
+
+	exists, err := store.Exists("second")
+	is.Nil(err)
+	is.False(exists)
+}
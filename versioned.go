@@ -0,0 +1,184 @@
+package gokvstores
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	conv "github.com/cstockton/go-conv"
+)
+
+// VersionedStore is a KVStore decorator keeping the last versions of the
+// values written with Set, so they can be inspected and rolled back.
+//
+// Versions are numbered from 1 and stored in the wrapped store under
+// ReservedPrefix, without expiration. Versioning is not atomic across
+// processes.
+type VersionedStore struct {
+	KVStore
+
+	versions int
+	mu       sync.Mutex
+}
+
+// NewVersionedStore returns a VersionedStore wrapping the given store and
+// keeping the given number of versions per key.
+func NewVersionedStore(store KVStore, versions int) *VersionedStore {
+	if versions < 1 {
+		versions = 1
+	}
+
+	return &VersionedStore{KVStore: store, versions: versions}
+}
+
+// latestKey returns the key holding the latest version number of key.
+func (s *VersionedStore) latestKey(key string) string {
+	return ReservedPrefix + "versions:" + key
+}
+
+// versionKey returns the key holding the given version of key.
+func (s *VersionedStore) versionKey(key string, version int) string {
+	return fmt.Sprintf("%sversion:%d:%s", ReservedPrefix, version, key)
+}
+
+// latest returns the latest version number of the given key, 0 if none.
+func (s *VersionedStore) latest(key string) (int, error) {
+	value, err := s.KVStore.Get(s.latestKey(key))
+	if err != nil || value == nil {
+		return 0, err
+	}
+
+	latest, err := conv.Int64(value)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(latest), nil
+}
+
+// Set sets value for the given key, recording it as a new version.
+func (s *VersionedStore) Set(key string, value interface{}) error {
+	return s.set(key, value, func() error {
+		return s.KVStore.Set(key, value)
+	})
+}
+
+// SetWithExpiration sets value for the given key with a specific expiration,
+// recording it as a new version. Versions themselves don't expire.
+func (s *VersionedStore) SetWithExpiration(key string, value interface{}, expiration time.Duration) error {
+	return s.set(key, value, func() error {
+		return s.KVStore.SetWithExpiration(key, value, expiration)
+	})
+}
+
+// set records value as a new version of the given key, then writes it.
+func (s *VersionedStore) set(key string, value interface{}, write func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	latest, err := s.latest(key)
+	if err != nil {
+		return err
+	}
+
+	version := latest + 1
+
+	if err := s.KVStore.SetWithExpiration(s.versionKey(key, version), value, 0); err != nil {
+		return err
+	}
+
+	if err := s.KVStore.SetWithExpiration(s.latestKey(key), version, 0); err != nil {
+		return err
+	}
+
+	if expired := version - s.versions; expired > 0 {
+		if err := s.KVStore.Delete(s.versionKey(key, expired)); err != nil {
+			return err
+		}
+	}
+
+	return write()
+}
+
+// Scan calls fn with each item of the wrapped store, skipping the versions.
+// It returns ErrNotSupported if the wrapped store is not a Scanner.
+func (s *VersionedStore) Scan(fn func(item Item) error) error {
+	return scanUserItems(s.KVStore, fn)
+}
+
+// ListVersions returns the available versions of the given key, oldest first.
+func (s *VersionedStore) ListVersions(key string) ([]int, error) {
+	latest, err := s.latest(key)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []int{}
+
+	first := latest - s.versions + 1
+	if first < 1 {
+		first = 1
+	}
+
+	for version := first; version <= latest; version++ {
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+// GetVersion returns the given version of the key.
+func (s *VersionedStore) GetVersion(key string, version int) (interface{}, error) {
+	latest, err := s.latest(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if version < 1 || version > latest || version <= latest-s.versions {
+		return nil, ErrVersionNotFound
+	}
+
+	value, err := s.KVStore.Get(s.versionKey(key, version))
+	if err != nil {
+		return nil, err
+	}
+
+	if value == nil {
+		return nil, ErrVersionNotFound
+	}
+
+	return value, nil
+}
+
+// Rollback restores the given version of the key, recording it as a new version.
+func (s *VersionedStore) Rollback(key string, version int) error {
+	value, err := s.GetVersion(key, version)
+	if err != nil {
+		return err
+	}
+
+	return s.Set(key, value)
+}
+
+// Delete deletes the given key and its versions.
+func (s *VersionedStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	latest, err := s.latest(key)
+	if err != nil {
+		return err
+	}
+
+	for version := latest; version > 0 && version > latest-s.versions; version-- {
+		if err := s.KVStore.Delete(s.versionKey(key, version)); err != nil {
+			return err
+		}
+	}
+
+	if err := s.KVStore.Delete(s.latestKey(key)); err != nil {
+		return err
+	}
+
+	return s.KVStore.Delete(key)
+}
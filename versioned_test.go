@@ -0,0 +1,92 @@
+package gokvstores
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionedStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	store := NewVersionedStore(memory, 2)
+
+	testStore(t, store)
+
+	versions, err := store.ListVersions("config")
+	is.Nil(err)
+	is.Empty(versions)
+
+	is.Nil(store.Set("config", "v1"))
+	is.Nil(store.Set("config", "v2"))
+	is.Nil(store.Set("config", "bad"))
+
+	versions, err = store.ListVersions("config")
+	is.Nil(err)
+	is.Equal([]int{2, 3}, versions)
+
+	_, err = store.GetVersion("config", 1)
+	is.Equal(ErrVersionNotFound, err)
+
+	v, err := store.GetVersion("config", 2)
+	is.Nil(err)
+	is.Equal("v2", v)
+
+	is.Nil(store.Rollback("config", 2))
+
+	v, err = store.Get("config")
+	is.Nil(err)
+	is.Equal("v2", v)
+
+	versions, err = store.ListVersions("config")
+	is.Nil(err)
+	is.Equal([]int{3, 4}, versions)
+
+	is.Nil(store.Delete("config"))
+
+	versions, err = store.ListVersions("config")
+	is.Nil(err)
+	is.Empty(versions)
+
+	exists, err := memory.Exists(store.versionKey("config", 4))
+	is.Nil(err)
+	is.False(exists)
+
+	// Versions are kept apart from the user keys.
+	is.Nil(store.Set("config", "v1"))
+
+	var keys []string
+	is.Nil(store.Scan(func(item Item) error {
+		keys = append(keys, item.Key)
+		return nil
+	}))
+	is.Contains(keys, "config")
+	for _, key := range keys {
+		is.False(strings.HasPrefix(key, ReservedPrefix), key)
+	}
+
+	// Values written without expiration never expire, whatever the
+	// wrapped store default.
+	clock := NewManualClock(time.Now())
+	memory, err = NewMemoryStoreWithOptions(&MemoryStoreOptions{Expiration: time.Minute, Clock: clock})
+	is.Nil(err)
+
+	store = NewVersionedStore(memory, 2)
+	is.Nil(store.SetWithExpiration("permanent", "value", NoExpiration))
+	is.Nil(store.Set("default", "value"))
+
+	clock.Advance(time.Hour)
+
+	v, err = store.Get("permanent")
+	is.Nil(err)
+	is.Equal("value", v)
+
+	v, err = store.Get("default")
+	is.Nil(err)
+	is.Nil(v)
+}
@@ -0,0 +1,62 @@
+package gokvstores
+
+import (
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// replicaWait are the replication requirements of RedisStore writes.
+type replicaWait struct {
+	replicas int
+	timeout  time.Duration
+}
+
+// WithWait returns a copy of the store whose writes (Set, SetWithExpiration,
+// SetMap, SetSlice, AppendSlice, Delete and Flush) wait, with WAIT, until
+// replicas replicas acknowledged them, for at most timeout, or forever if
+// zero. Writes acknowledged by fewer replicas return a NotReplicatedError,
+// but are not rolled back. Zero replicas disables waiting.
+//
+// The copy shares the client and the counters of the store. Waiting is not
+// supported with a cluster.
+func (r *RedisStore) WithWait(replicas int, timeout time.Duration) *RedisStore {
+	store := *r
+	store.wait = nil
+
+	if replicas > 0 {
+		store.wait = &replicaWait{replicas: replicas, timeout: timeout}
+	}
+
+	return &store
+}
+
+// write sends the write commands of fn, followed by a WAIT on the same
+// connection when the store requires acknowledgements from replicas.
+func (r *RedisStore) write(fn func(c redis.Cmdable) error) error {
+	if r.wait == nil {
+		return fn(r.client)
+	}
+
+	if _, ok := r.client.(*redis.ClusterClient); ok {
+		return ErrNotSupported
+	}
+
+	var wait *redis.IntCmd
+
+	_, err := r.client.Pipelined(r.ctx, func(pipe redis.Pipeliner) error {
+		// Queued commands only fail once executed.
+		fn(pipe)
+		wait = pipe.Wait(r.ctx, r.wait.replicas, r.wait.timeout)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if acknowledged := int(wait.Val()); acknowledged < r.wait.replicas {
+		return &NotReplicatedError{Replicas: r.wait.replicas, Acknowledged: acknowledged}
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package gokvstores
+
+import "path"
+
+// WarmOptions are MemoryStore.WarmFrom options.
+type WarmOptions struct {
+	// Keys are the keys to load. Missing ones are skipped.
+	Keys []string
+
+	// Pattern loads the keys matching the glob-style pattern instead, all of
+	// them if empty. They are listed with Keys from a RedisStore, or matched
+	// with path.Match against the items of the other stores implementing
+	// Scanner.
+	Pattern string
+
+	// Concurrency is the number of keys read in parallel. Defaults to 8.
+	Concurrency int
+
+	// OnProgress is called after each key is loaded, with the number of keys
+	// loaded so far. Calls are serialized.
+	OnProgress func(loaded int)
+}
+
+// itemReader is implemented by stores able to read a key whatever its type,
+// with its expiration.
+type itemReader interface {
+	item(key string) (Item, bool, error)
+}
+
+// keyLister is implemented by stores able to list their keys matching a
+// glob-style pattern.
+type keyLister interface {
+	Keys(pattern string) ([]string, error)
+}
+
+// item returns the item stored at key, unless it is a HyperLogLog, Geo or
+// filter key.
+func (c *MemoryStore) item(key string) (Item, bool, error) {
+	c.txn.RLock()
+	defer c.txn.RUnlock()
+
+	value, expiration, found := c.lookupWithExpiration(key)
+	if value, found = plainValue(value, found); !found || isInternalValue(value) {
+		return Item{}, false, nil
+	}
+
+	value, err := c.readCopy(value)
+	if err != nil {
+		return Item{}, false, err
+	}
+
+	return Item{Key: key, Value: value, Expiration: expiration}, true, nil
+}
+
+// warmTask returns the task loading the given key of src into the store.
+func (c *MemoryStore) warmTask(src KVStore, key string) task {
+	return func() (bool, error) {
+		var (
+			item  Item
+			found bool
+			err   error
+		)
+
+		if reader, ok := src.(itemReader); ok {
+			item, found, err = reader.item(key)
+		} else {
+			var value interface{}
+			value, err = src.Get(key)
+			item, found = Item{Key: key, Value: value}, value != nil
+		}
+
+		if !found || err != nil {
+			return false, err
+		}
+
+		return true, setItem(c, item)
+	}
+}
+
+// WarmFrom loads keys of src into the store, with their type and remaining
+// time to live, so that a new instance does not start with only misses. The
+// keys are read concurrently, which matters with a remote src. It stops at
+// the first failure and returns the number of keys loaded.
+func (c *MemoryStore) WarmFrom(src KVStore, options *WarmOptions) (int, error) {
+	if options == nil {
+		options = &WarmOptions{}
+	}
+
+	lister, canList := src.(keyLister)
+	scanner, canScan := src.(Scanner)
+
+	var produce func(send func(task task) error) error
+
+	switch {
+	case len(options.Keys) > 0:
+		produce = func(send func(task task) error) error {
+			for _, key := range options.Keys {
+				if err := send(c.warmTask(src, key)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case canList:
+		produce = func(send func(task task) error) error {
+			pattern := options.Pattern
+			if pattern == "" {
+				pattern = "*"
+			}
+
+			keys, err := lister.Keys(pattern)
+			if err != nil {
+				return err
+			}
+
+			for _, key := range keys {
+				if err := send(c.warmTask(src, key)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case canScan:
+		produce = func(send func(task task) error) error {
+			return scanner.Scan(func(item Item) error {
+				if options.Pattern != "" {
+					if matched, err := path.Match(options.Pattern, item.Key); err != nil || !matched {
+						return err
+					}
+				}
+
+				return send(func() (bool, error) {
+					return true, setItem(c, item)
+				})
+			})
+		}
+	default:
+		return 0, ErrNotSupported
+	}
+
+	return parallel(options.Concurrency, options.OnProgress, produce)
+}
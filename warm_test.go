@@ -0,0 +1,78 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreWarmFrom(t *testing.T) {
+	is := assert.New(t)
+
+	src, err := NewMemoryStore(NoExpiration, 0)
+	is.Nil(err)
+
+	is.Nil(src.Set("user:1", "alice"))
+	is.Nil(src.SetWithExpiration("user:2", "bob", time.Hour))
+	is.Nil(src.SetMap("user:3", map[string]interface{}{"name": "carol"}))
+	is.Nil(src.SetSlice("other", []interface{}{"a"}))
+
+	store, err := NewMemoryStore(NoExpiration, 0)
+	is.Nil(err)
+
+	memory := store.(*MemoryStore)
+
+	loaded, err := memory.WarmFrom(src, &WarmOptions{Keys: []string{"user:1", "user:3", "missing"}})
+	is.Nil(err)
+	is.Equal(2, loaded)
+
+	m, err := store.GetMap("user:3")
+	is.Nil(err)
+	is.Equal(map[string]interface{}{"name": "carol"}, m)
+
+	store, err = NewMemoryStore(NoExpiration, 0)
+	is.Nil(err)
+
+	memory = store.(*MemoryStore)
+
+	var progress []int
+	loaded, err = memory.WarmFrom(src, &WarmOptions{
+		Pattern:     "user:*",
+		Concurrency: 1,
+		OnProgress: func(loaded int) {
+			progress = append(progress, loaded)
+		},
+	})
+	is.Nil(err)
+	is.Equal(3, loaded)
+	is.Equal([]int{1, 2, 3}, progress)
+
+	exists, err := store.Exists("other")
+	is.Nil(err)
+	is.False(exists)
+
+	is.Nil(memory.Scan(func(item Item) error {
+		if item.Key == "user:2" {
+			is.WithinDuration(time.Now().Add(time.Hour), item.Expiration, time.Minute)
+		}
+		return nil
+	}))
+
+	// Stores which can only be read key by key need the keys.
+	plain := &interceptedStore{store: src, intercept: func(op *operation, next func() error) error {
+		return next()
+	}}
+
+	loaded, err = memory.WarmFrom(plain, nil)
+	is.Equal(ErrNotSupported, err)
+	is.Equal(0, loaded)
+
+	loaded, err = memory.WarmFrom(plain, &WarmOptions{Keys: []string{"other"}})
+	is.Nil(err)
+	is.Equal(1, loaded)
+
+	slice, err := store.GetSlice("other")
+	is.Nil(err)
+	is.Equal([]interface{}{"a"}, slice)
+}
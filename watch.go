@@ -0,0 +1,130 @@
+package gokvstores
+
+import (
+	"strings"
+	"sync"
+)
+
+// watchBufferSize is the capacity of KeyWatch channels.
+const watchBufferSize = 64
+
+// ChangeType is the kind of a key change.
+type ChangeType int
+
+// Change types.
+const (
+	// ChangeSet is a key being written.
+	ChangeSet ChangeType = iota
+
+	// ChangeDelete is a key being deleted.
+	ChangeDelete
+
+	// ChangeExpire is a key reaching its expiration.
+	ChangeExpire
+
+	// ChangeEvict is a key being evicted to free space.
+	ChangeEvict
+)
+
+// String returns the change type name.
+func (t ChangeType) String() string {
+	switch t {
+	case ChangeSet:
+		return "set"
+	case ChangeDelete:
+		return "delete"
+	case ChangeExpire:
+		return "expire"
+	case ChangeEvict:
+		return "evict"
+	}
+	return "unknown"
+}
+
+// ChangeEvent describes a key change.
+type ChangeEvent struct {
+	Type ChangeType
+	Key  string
+}
+
+// Watcher is implemented by stores able to report key changes.
+type Watcher interface {
+	// Watch returns a KeyWatch receiving the changes of the keys starting with
+	// keyOrPrefix, or of all keys if it is empty.
+	Watch(keyOrPrefix string) (*KeyWatch, error)
+}
+
+// KeyWatch delivers the changes of watched keys.
+type KeyWatch struct {
+	events chan ChangeEvent
+	stop   func() error
+}
+
+// Events returns the channel changes are delivered on. It is closed once the
+// watch is closed.
+func (w *KeyWatch) Events() <-chan ChangeEvent {
+	return w.events
+}
+
+// Close stops watching.
+func (w *KeyWatch) Close() error {
+	return w.stop()
+}
+
+// watchHub dispatches changes to the watches of a store. Changes are dropped
+// for watches whose channel is full, so a slow reader never blocks writers.
+type watchHub struct {
+	mu      sync.RWMutex
+	watches map[*KeyWatch]string
+}
+
+// watch registers a new watch for the given prefix.
+func (h *watchHub) watch(prefix string) *KeyWatch {
+	w := &KeyWatch{events: make(chan ChangeEvent, watchBufferSize)}
+
+	var once sync.Once
+	w.stop = func() error {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.watches, w)
+			h.mu.Unlock()
+
+			close(w.events)
+		})
+		return nil
+	}
+
+	h.mu.Lock()
+	if h.watches == nil {
+		h.watches = map[*KeyWatch]string{}
+	}
+	h.watches[w] = prefix
+	h.mu.Unlock()
+
+	return w
+}
+
+// active reports whether any watch is registered.
+func (h *watchHub) active() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.watches) > 0
+}
+
+// notify dispatches a change to the matching watches.
+func (h *watchHub) notify(t ChangeType, key string) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for w, prefix := range h.watches {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		select {
+		case w.events <- ChangeEvent{Type: t, Key: key}:
+		default:
+		}
+	}
+}
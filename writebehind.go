@@ -0,0 +1,258 @@
+package gokvstores
+
+import (
+	"sync"
+	"time"
+)
+
+// WriteBehindOptions are WriteBehindStore options.
+type WriteBehindOptions struct {
+	// QueueSize is the maximum number of pending writes. Defaults to 1024.
+	QueueSize int
+
+	// BatchSize is the number of pending writes triggering a flush. Defaults to 100.
+	BatchSize int
+
+	// FlushInterval is the maximum delay before pending writes are flushed.
+	// Defaults to 100ms.
+	FlushInterval time.Duration
+
+	// OnError is called for each write failing in the background.
+	OnError func(key string, err error)
+}
+
+// pendingWrite is a write waiting to be applied to the wrapped store.
+type pendingWrite struct {
+	key   string
+	apply func(store KVStore) error
+}
+
+// WriteBehindStore is a KVStore decorator acknowledging writes immediately and
+// applying them to the wrapped store in background batches.
+//
+// Reads are served by the wrapped store and do not see pending writes.
+// Close applies the pending writes before closing the wrapped store.
+type WriteBehindStore struct {
+	store   KVStore
+	options WriteBehindOptions
+
+	mu     sync.Mutex
+	queue  []pendingWrite
+	closed bool
+
+	// applying serializes batches with Flush and Sync.
+	applying sync.Mutex
+
+	notify chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// NewWriteBehindStore returns a WriteBehindStore wrapping the given store.
+func NewWriteBehindStore(store KVStore, options *WriteBehindOptions) *WriteBehindStore {
+	if options == nil {
+		options = &WriteBehindOptions{}
+	}
+
+	w := &WriteBehindStore{
+		store:   store,
+		options: *options,
+		notify:  make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	if w.options.QueueSize <= 0 {
+		w.options.QueueSize = 1024
+	}
+
+	if w.options.BatchSize <= 0 {
+		w.options.BatchSize = 100
+	}
+
+	if w.options.FlushInterval <= 0 {
+		w.options.FlushInterval = 100 * time.Millisecond
+	}
+
+	go w.run()
+
+	return w
+}
+
+// run applies pending writes until the store is closed.
+func (w *WriteBehindStore) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.options.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.notify:
+		case <-w.stop:
+			w.Sync()
+			return
+		}
+
+		w.applyBatch()
+	}
+}
+
+// enqueue adds a write to the queue.
+func (w *WriteBehindStore) enqueue(key string, apply func(store KVStore) error) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return ErrStoreClosed
+	}
+
+	if len(w.queue) >= w.options.QueueSize {
+		return ErrWriteQueueFull
+	}
+
+	w.queue = append(w.queue, pendingWrite{key: key, apply: apply})
+
+	if len(w.queue) >= w.options.BatchSize {
+		select {
+		case w.notify <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// applyBatch applies at most BatchSize pending writes and returns their count.
+func (w *WriteBehindStore) applyBatch() int {
+	w.applying.Lock()
+	defer w.applying.Unlock()
+
+	w.mu.Lock()
+	n := len(w.queue)
+	if n > w.options.BatchSize {
+		n = w.options.BatchSize
+	}
+	batch := w.queue[:n:n]
+	w.queue = w.queue[n:]
+	w.mu.Unlock()
+
+	for _, write := range batch {
+		if err := write.apply(w.store); err != nil && w.options.OnError != nil {
+			w.options.OnError(write.key, err)
+		}
+	}
+
+	return n
+}
+
+// Pending returns the number of writes waiting to be applied.
+func (w *WriteBehindStore) Pending() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return len(w.queue)
+}
+
+// Sync applies all pending writes before returning.
+func (w *WriteBehindStore) Sync() {
+	for w.applyBatch() > 0 {
+	}
+}
+
+// Get returns value for the given key.
+func (w *WriteBehindStore) Get(key string) (interface{}, error) {
+	return w.store.Get(key)
+}
+
+// Set queues the value for the given key.
+func (w *WriteBehindStore) Set(key string, value interface{}) error {
+	return w.enqueue(key, func(store KVStore) error {
+		return store.Set(key, value)
+	})
+}
+
+// SetWithExpiration queues the value for the given key with a specific expiration.
+func (w *WriteBehindStore) SetWithExpiration(key string, value interface{}, expiration time.Duration) error {
+	return w.enqueue(key, func(store KVStore) error {
+		return store.SetWithExpiration(key, value, expiration)
+	})
+}
+
+// GetMap returns map for the given key.
+func (w *WriteBehindStore) GetMap(key string) (map[string]interface{}, error) {
+	return w.store.GetMap(key)
+}
+
+// SetMap queues the map for the given key.
+func (w *WriteBehindStore) SetMap(key string, value map[string]interface{}) error {
+	return w.enqueue(key, func(store KVStore) error {
+		return store.SetMap(key, value)
+	})
+}
+
+// GetSlice returns slice for the given key.
+func (w *WriteBehindStore) GetSlice(key string) ([]interface{}, error) {
+	return w.store.GetSlice(key)
+}
+
+// SetSlice queues the slice for the given key.
+func (w *WriteBehindStore) SetSlice(key string, value []interface{}) error {
+	return w.enqueue(key, func(store KVStore) error {
+		return store.SetSlice(key, value)
+	})
+}
+
+// AppendSlice queues values to append to an existing slice.
+// If key does not exist, creates slice.
+func (w *WriteBehindStore) AppendSlice(key string, values ...interface{}) error {
+	return w.enqueue(key, func(store KVStore) error {
+		return store.AppendSlice(key, values...)
+	})
+}
+
+// Exists checks if the given key exists.
+func (w *WriteBehindStore) Exists(key string) (bool, error) {
+	return w.store.Exists(key)
+}
+
+// Delete queues the deletion of the given key.
+func (w *WriteBehindStore) Delete(key string) error {
+	return w.enqueue(key, func(store KVStore) error {
+		return store.Delete(key)
+	})
+}
+
+// Flush discards pending writes and flushes the wrapped store.
+func (w *WriteBehindStore) Flush() error {
+	w.applying.Lock()
+	defer w.applying.Unlock()
+
+	w.mu.Lock()
+	w.queue = nil
+	w.mu.Unlock()
+
+	return w.store.Flush()
+}
+
+// Close applies pending writes and closes the wrapped store.
+func (w *WriteBehindStore) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.stop)
+	<-w.done
+
+	return w.store.Close()
+}
+
+// Ping checks the wrapped store is reachable.
+func (w *WriteBehindStore) Ping() error {
+	return w.store.Ping()
+}
@@ -0,0 +1,68 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteBehindStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	store := NewWriteBehindStore(memory, &WriteBehindOptions{
+		QueueSize:     2,
+		FlushInterval: time.Hour,
+	})
+
+	is.Nil(store.Set("key1", "value1"))
+	is.Nil(store.SetMap("key2", map[string]interface{}{"language": "go"}))
+	is.Equal(ErrWriteQueueFull, store.Set("key3", "value3"))
+	is.Equal(2, store.Pending())
+
+	v, err := memory.Get("key1")
+	is.Nil(err)
+	is.Nil(v)
+
+	store.Sync()
+	is.Equal(0, store.Pending())
+
+	v, err = store.Get("key1")
+	is.Nil(err)
+	is.Equal("value1", v)
+
+	m, err := store.GetMap("key2")
+	is.Nil(err)
+	is.Equal(map[string]interface{}{"language": "go"}, m)
+
+	// Background flush
+
+	store = NewWriteBehindStore(memory, &WriteBehindOptions{
+		FlushInterval: 10 * time.Millisecond,
+	})
+
+	is.Nil(store.Delete("key1"))
+
+	time.Sleep(50 * time.Millisecond)
+
+	exists, err := memory.Exists("key1")
+	is.Nil(err)
+	is.False(exists)
+
+	// Close applies pending writes
+
+	store = NewWriteBehindStore(memory, &WriteBehindOptions{
+		FlushInterval: time.Hour,
+	})
+
+	is.Nil(store.Set("key3", "value3"))
+	is.Nil(store.Close())
+	is.Equal(ErrStoreClosed, store.Set("key4", "value4"))
+
+	v, err = memory.Get("key3")
+	is.Nil(err)
+	is.Equal("value3", v)
+}
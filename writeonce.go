@@ -0,0 +1,74 @@
+package gokvstores
+
+import (
+	"sync"
+	"time"
+)
+
+// WriteOnceStore is a KVStore decorator enforcing write-once semantics:
+// writing an existing key fails with a KeyExistsError. Deleted or expired
+// keys can be written again.
+//
+// Existence is checked before writing, which is not atomic across processes.
+type WriteOnceStore struct {
+	KVStore
+
+	mu sync.Mutex
+}
+
+// NewWriteOnceStore returns a WriteOnceStore wrapping the given store.
+func NewWriteOnceStore(store KVStore) *WriteOnceStore {
+	return &WriteOnceStore{KVStore: store}
+}
+
+// create runs write if the given key does not exist.
+func (s *WriteOnceStore) create(key string, write func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	exists, err := s.KVStore.Exists(key)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return &KeyExistsError{Key: key}
+	}
+
+	return write()
+}
+
+// Set sets value for the given key if it does not exist.
+func (s *WriteOnceStore) Set(key string, value interface{}) error {
+	return s.create(key, func() error {
+		return s.KVStore.Set(key, value)
+	})
+}
+
+// SetWithExpiration sets value for the given key with a specific expiration if it does not exist.
+func (s *WriteOnceStore) SetWithExpiration(key string, value interface{}, expiration time.Duration) error {
+	return s.create(key, func() error {
+		return s.KVStore.SetWithExpiration(key, value, expiration)
+	})
+}
+
+// SetMap sets map for the given key if it does not exist.
+func (s *WriteOnceStore) SetMap(key string, value map[string]interface{}) error {
+	return s.create(key, func() error {
+		return s.KVStore.SetMap(key, value)
+	})
+}
+
+// SetSlice sets slice for the given key if it does not exist.
+func (s *WriteOnceStore) SetSlice(key string, value []interface{}) error {
+	return s.create(key, func() error {
+		return s.KVStore.SetSlice(key, value)
+	})
+}
+
+// AppendSlice creates a slice with the given values if the key does not exist.
+func (s *WriteOnceStore) AppendSlice(key string, values ...interface{}) error {
+	return s.create(key, func() error {
+		return s.KVStore.SetSlice(key, values)
+	})
+}
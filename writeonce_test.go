@@ -0,0 +1,35 @@
+package gokvstores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteOnceStore(t *testing.T) {
+	is := assert.New(t)
+
+	memory, err := NewMemoryStore(time.Second*10, time.Second*10)
+	is.Nil(err)
+
+	store := NewWriteOnceStore(memory)
+
+	is.Nil(store.Set("key", "value"))
+	is.Equal(&KeyExistsError{Key: "key"}, store.Set("key", "other"))
+	is.Equal(&KeyExistsError{Key: "key"}, store.SetMap("key", map[string]interface{}{"language": "go"}))
+
+	v, err := store.Get("key")
+	is.Nil(err)
+	is.Equal("value", v)
+
+	is.Nil(store.Delete("key"))
+	is.Nil(store.Set("key", "other"))
+
+	is.Nil(store.AppendSlice("slice", "one"))
+	is.NotNil(store.AppendSlice("slice", "two"))
+
+	s, err := store.GetSlice("slice")
+	is.Nil(err)
+	is.Equal([]interface{}{"one"}, s)
+}